@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"ferrodb/internal/persistence"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "truncate the file at the first invalid record")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: ferrodb-check-aof [--fix] <file.aof>")
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	res, err := persistence.Check(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	if res.Legacy {
+		fmt.Printf("%s: legacy text format (v1), it will be migrated when the server starts\n", path)
+		return
+	}
+
+	if res.Err == nil {
+		fmt.Printf("%s: OK, %d commands, %d bytes\n", path, res.Commands, res.Size)
+		return
+	}
+
+	var ferr *persistence.FormatError
+	kind := "corrupt"
+	if errors.As(res.Err, &ferr) && ferr.Truncated {
+		kind = "truncated"
+	}
+
+	fmt.Printf("%s: %s: %v\n", path, kind, res.Err)
+	fmt.Printf("%d commands valid, %d of %d bytes valid, %d bytes would be discarded\n",
+		res.Commands, res.Valid, res.Size, res.Size-res.Valid)
+
+	if !*fix {
+		fmt.Println("run with --fix to truncate the file at the last valid record")
+		os.Exit(1)
+	}
+
+	if err := persistence.Fix(path, res); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	fmt.Printf("%s: truncated to %d bytes\n", path, res.Valid)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain menjalankan main bila binary test dipanggil ulang oleh run,
+// sehingga exit code dan output bisa diperiksa tanpa build terpisah.
+func TestMain(m *testing.M) {
+	if os.Getenv("FERRODB_CHECK_AOF_MAIN") == "1" {
+		os.Args = append([]string{"ferrodb-check-aof"}, strings.Fields(os.Getenv("FERRODB_CHECK_AOF_ARGS"))...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(),
+		"FERRODB_CHECK_AOF_MAIN=1",
+		"FERRODB_CHECK_AOF_ARGS="+strings.Join(args, " "),
+	)
+	out, err := cmd.CombinedOutput()
+
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return string(out), exit.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+const (
+	validAOF = "FERRODB-AOF 2\r\n*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n"
+	partial  = "*2\r\n$3\r\nDEL\r\n$1"
+	garbage  = "+OK\r\n"
+)
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckAOF(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		fix  bool
+		code int
+		want string
+	}{
+		{"ok", validAOF, false, 0, ": OK, 1 commands, 42 bytes"},
+		{"legacy", "SET 0 k v\n", false, 0, "legacy text format"},
+		{"truncated", validAOF + partial, false, 1, ": truncated: aof: truncated record at offset 42"},
+		{"corrupt", validAOF + garbage, false, 1, `: corrupt: aof: invalid array header "+OK" at offset 42`},
+		{"fix truncated", validAOF + partial, true, 0, ": truncated to 42 bytes"},
+		{"fix corrupt", validAOF + garbage, true, 0, ": truncated to 42 bytes"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeFile(t, tc.data)
+			args := []string{path}
+			if tc.fix {
+				args = []string{"--fix", path}
+			}
+
+			out, code := run(t, args...)
+			if code != tc.code || !strings.Contains(out, tc.want) {
+				t.Fatalf("exit %d, output:\n%s\nwant exit %d containing %q", code, out, tc.code, tc.want)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tc.data
+			if tc.fix {
+				want = validAOF
+			}
+			if string(data) != want {
+				t.Fatalf("file is %q, want %q", data, want)
+			}
+		})
+	}
+}
+
+func TestCheckAOFUsage(t *testing.T) {
+	if _, code := run(t); code != 2 {
+		t.Fatalf("exit %d without arguments, want 2", code)
+	}
+	if _, code := run(t, filepath.Join(t.TempDir(), "missing.aof")); code != 2 {
+		t.Fatalf("exit %d for a missing file, want 2", code)
+	}
+}
@@ -0,0 +1,70 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ferrodb/internal/pubsub"
+)
+
+// sseHeartbeat menjaga koneksi SSE tetap hidup melewati proxy.
+const sseHeartbeat = 15 * time.Second
+
+// streamEvents mengirim keyevent notification milik satu DB sebagai
+// Server-Sent Events untuk live-update Web UI. Event hanya mengalir bila
+// notify_keyspace_events mengaktifkan E dan kelas event-nya (mis. "EA").
+func streamEvents(w http.ResponseWriter, r *http.Request, db int) {
+	if db < 0 || db >= eng.DBCount() {
+		writeJSONError(w, "invalid db", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	dropped := make(chan struct{})
+	hub := eng.PubSub()
+	sub := pubsub.NewSubscriber(256, func() { close(dropped) })
+	prefix := "__keyevent@" + strconv.Itoa(db) + "__:"
+	hub.PSubscribe(sub, prefix+"*")
+	defer hub.Remove(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg := <-sub.C:
+			event := strings.TrimPrefix(msg.Channel, prefix)
+			data, _ := json.Marshal(map[string]any{
+				"db":    db,
+				"event": event,
+				"key":   msg.Payload,
+			})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+
+		case <-dropped:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -2,7 +2,9 @@ package adminapi
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -57,7 +59,19 @@ func dbRouter(w http.ResponseWriter, r *http.Request) {
 
 	// /api/db/{id}/keys
 	if len(parts) == 4 && parts[3] == "keys" {
-		listKeys(w, db)
+		listKeys(w, r, db)
+		return
+	}
+
+	// /api/db/{id}/events (Server-Sent Events)
+	if len(parts) == 4 && parts[3] == "events" {
+		streamEvents(w, r, db)
+		return
+	}
+
+	// /api/db/{id}/memory
+	if len(parts) == 4 && parts[3] == "memory" {
+		memoryReport(w, r, db)
 		return
 	}
 
@@ -72,23 +86,86 @@ func dbRouter(w http.ResponseWriter, r *http.Request) {
 
 // --- keys ---
 
-func listKeys(w http.ResponseWriter, db int) {
+// listKeys mengembalikan satu halaman key via SCAN.
+// Query: ?cursor=0&count=100&match=pattern
+func listKeys(w http.ResponseWriter, r *http.Request, db int) {
+	q := r.URL.Query()
+
+	cursor := q.Get("cursor")
+	if cursor == "" {
+		cursor = "0"
+	}
+	count := q.Get("count")
+	if count == "" {
+		count = "100"
+	}
+
+	args := []string{"SCAN", cursor, "COUNT", count}
+	if match := q.Get("match"); match != "" {
+		args = append(args, "MATCH", match)
+	}
+
+	res := eng.Execute(db, args)
+	if res.IsError() {
+		writeJSONError(w, res.Str, http.StatusBadRequest)
+		return
+	}
+
 	jsonOK(w)
+	json.NewEncoder(w).Encode(map[string]any{
+		"db":     db,
+		"cursor": res.Elems[0].Str,
+		"keys":   replyStrings(res.Elems[1]),
+	})
+}
 
-	res := eng.Execute(db, "KEYS *")
-	keys := []string{}
+// --- memory ---
 
-	if res != "(nil)" && res != "" {
-		for _, k := range strings.Split(res, "\n") {
-			if k != "" {
-				keys = append(keys, k)
-			}
+// memoryReport mengembalikan distribusi tipe dan key terbesar di db untuk
+// analisis big key. Query: ?top=20
+func memoryReport(w http.ResponseWriter, r *http.Request, db int) {
+	if db < 0 || db >= eng.DBCount() {
+		writeJSONError(w, "invalid db", http.StatusBadRequest)
+		return
+	}
+
+	top := 20
+	if raw := r.URL.Query().Get("top"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > 1000 {
+			writeJSONError(w, "invalid top", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	stats := eng.KeyStats(db, top)
+
+	types := map[string]any{}
+	for t, s := range stats.Types {
+		types[t.String()] = map[string]any{
+			"keys":  s.Keys,
+			"bytes": s.Bytes,
+		}
+	}
+
+	largest := make([]map[string]any, len(stats.Largest))
+	for i, k := range stats.Largest {
+		largest[i] = map[string]any{
+			"key":   k.Key,
+			"type":  k.Type.String(),
+			"bytes": k.Bytes,
 		}
 	}
 
+	jsonOK(w)
 	json.NewEncoder(w).Encode(map[string]any{
-		"db":   db,
-		"keys": keys,
+		"db":      db,
+		"keys":    stats.Keys,
+		"expires": stats.Expires,
+		"bytes":   stats.Bytes,
+		"types":   types,
+		"largest": largest,
 	})
 }
 
@@ -100,7 +177,8 @@ func handleKey(w http.ResponseWriter, r *http.Request, db int, rest []string) {
 		return
 	}
 
-	key := rest[0]
+	// key boleh berisi "/" (path sudah di-decode oleh net/http)
+	key := strings.Join(rest, "/")
 
 	switch r.Method {
 	case http.MethodGet:
@@ -117,21 +195,116 @@ func handleKey(w http.ResponseWriter, r *http.Request, db int, rest []string) {
 func getKey(w http.ResponseWriter, db int, key string) {
 	jsonOK(w)
 
-	val := eng.Execute(db, "GET "+key)
-	if val == "(nil)" {
+	typ := eng.KeyType(db, key)
+	if typ == "none" {
 		writeJSONError(w, "key not found", http.StatusNotFound)
 		return
 	}
 
-	ttl := eng.Execute(db, "TTL "+key)
+	val, err := keyValue(db, key, typ)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ttl dalam detik (kompatibel), ttl_ms presisi milidetik
+	pttl := eng.Execute(db, []string{"PTTL", key}).Int
+	ttl := pttl
+	if pttl >= 0 {
+		ttl = (pttl + 500) / 1000
+	}
 
 	json.NewEncoder(w).Encode(map[string]any{
-		"key":   key,
-		"value": val,
-		"ttl":   ttl,
+		"key":    key,
+		"type":   typ,
+		"value":  val,
+		"ttl":    ttl,
+		"ttl_ms": pttl,
 	})
 }
 
+// keyValue membaca isi key sesuai tipenya dalam bentuk yang ramah JSON.
+func keyValue(db int, key, typ string) (any, error) {
+	switch typ {
+	case "hash":
+		res := eng.Execute(db, []string{"HGETALL", key})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+
+		fields := make(map[string]string, len(res.Elems)/2)
+		for i := 0; i+1 < len(res.Elems); i += 2 {
+			fields[res.Elems[i].Str] = res.Elems[i+1].Str
+		}
+		return fields, nil
+
+	case "list":
+		res := eng.Execute(db, []string{"LRANGE", key, "0", "-1"})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+		return replyStrings(res), nil
+
+	case "set":
+		res := eng.Execute(db, []string{"SMEMBERS", key})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+		members := replyStrings(res)
+		sort.Strings(members)
+		return members, nil
+
+	case "stream":
+		res := eng.Execute(db, []string{"XRANGE", key, "-", "+"})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+
+		entries := make([]map[string]any, 0, len(res.Elems))
+		for _, e := range res.Elems {
+			fields := map[string]string{}
+			for i := 0; i+1 < len(e.Elems[1].Elems); i += 2 {
+				fields[e.Elems[1].Elems[i].Str] = e.Elems[1].Elems[i+1].Str
+			}
+			entries = append(entries, map[string]any{
+				"id":     e.Elems[0].Str,
+				"fields": fields,
+			})
+		}
+		return entries, nil
+
+	case "zset":
+		res := eng.Execute(db, []string{"ZRANGE", key, "0", "-1", "WITHSCORES"})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+
+		members := make([]map[string]string, 0, len(res.Elems)/2)
+		for i := 0; i+1 < len(res.Elems); i += 2 {
+			members = append(members, map[string]string{
+				"member": res.Elems[i].Str,
+				"score":  res.Elems[i+1].Str,
+			})
+		}
+		return members, nil
+
+	default:
+		res := eng.Execute(db, []string{"GET", key})
+		if res.IsError() {
+			return nil, errors.New(res.Str)
+		}
+		return res.Str, nil
+	}
+}
+
+func replyStrings(res engine.Reply) []string {
+	out := make([]string, 0, len(res.Elems))
+	for _, el := range res.Elems {
+		out = append(out, el.Str)
+	}
+	return out
+}
+
 func setKey(w http.ResponseWriter, r *http.Request, db int, key string) {
 	jsonOK(w)
 
@@ -144,9 +317,12 @@ func setKey(w http.ResponseWriter, r *http.Request, db int, key string) {
 		return
 	}
 
-	// allow empty string value
-	cmd := "SET " + key + " " + body.Value
-	eng.Execute(db, cmd)
+	// value diteruskan apa adanya (boleh kosong, spasi, newline, JSON)
+	res := eng.Execute(db, []string{"SET", key, body.Value})
+	if res.IsError() {
+		writeJSONError(w, res.Str, http.StatusBadRequest)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -158,14 +334,14 @@ func setKey(w http.ResponseWriter, r *http.Request, db int, key string) {
 func delKey(w http.ResponseWriter, db int, key string) {
 	jsonOK(w)
 
-	res := eng.Execute(db, "DEL "+key)
-	deleted := 0
-	if res == "OK" {
-		deleted = 1
+	res := eng.Execute(db, []string{"DEL", key})
+	if res.IsError() {
+		writeJSONError(w, res.Str, http.StatusBadRequest)
+		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]int{
-		"deleted": deleted,
+	json.NewEncoder(w).Encode(map[string]int64{
+		"deleted": res.Int,
 	})
 }
 
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -23,12 +27,28 @@ type Config struct {
 	Data struct {
 		Dir     string `yaml:"dir"`
 		AOFFile string `yaml:"aof_file"`
+
+		// kapan AOF di-fsync: always, everysec atau no
+		AppendFsync string `yaml:"appendfsync"`
+
+		// ekor AOF yang terpotong dibuang saat load (false = server menolak start)
+		AOFLoadTruncated bool `yaml:"aof_load_truncated"`
 	} `yaml:"data"`
 
 	Engine struct {
 		DBCount            int `yaml:"db_count"`
 		CleanupIntervalSec int `yaml:"cleanup_interval_sec"`
+
+		// kelas keyspace notification, mis. "KEA" (kosong = nonaktif)
+		NotifyKeyspaceEvents string `yaml:"notify_keyspace_events"`
 	} `yaml:"engine"`
+
+	Memory struct {
+		// batas memori dataset, mis. "512mb" atau "2gb" (kosong/0 = tanpa batas)
+		MaxMemory        string `yaml:"maxmemory"`
+		MaxMemoryPolicy  string `yaml:"maxmemory_policy"`
+		MaxMemorySamples int    `yaml:"maxmemory_samples"`
+	} `yaml:"memory"`
 }
 
 func Load(path string) (*Config, error) {
@@ -54,10 +74,15 @@ func defaultConfig() *Config {
 
 	cfg.Data.Dir = "data"
 	cfg.Data.AOFFile = "ferrodb.aof"
+	cfg.Data.AppendFsync = "everysec"
+	cfg.Data.AOFLoadTruncated = true
 
 	cfg.Engine.DBCount = 16
 	cfg.Engine.CleanupIntervalSec = 1
 
+	cfg.Memory.MaxMemoryPolicy = "noeviction"
+	cfg.Memory.MaxMemorySamples = 5
+
 	return cfg
 }
 
@@ -74,6 +99,10 @@ func (c *Config) applyDefaults() {
 		c.Data.AOFFile = "ferrodb.aof"
 	}
 
+	if c.Data.AppendFsync == "" {
+		c.Data.AppendFsync = "everysec"
+	}
+
 	if c.Engine.DBCount <= 0 {
 		c.Engine.DBCount = 16
 	}
@@ -81,8 +110,53 @@ func (c *Config) applyDefaults() {
 	if c.Engine.CleanupIntervalSec <= 0 {
 		c.Engine.CleanupIntervalSec = 1
 	}
+
+	if c.Memory.MaxMemoryPolicy == "" {
+		c.Memory.MaxMemoryPolicy = "noeviction"
+	}
+
+	if c.Memory.MaxMemorySamples <= 0 {
+		c.Memory.MaxMemorySamples = 5
+	}
 }
 
 func (c *Config) AOFPath() string {
 	return filepath.Join(c.Data.Dir, c.Data.AOFFile)
 }
+
+// MaxMemoryBytes mengembalikan maxmemory dalam byte (0 = tanpa batas).
+func (c *Config) MaxMemoryBytes() (int64, error) {
+	return ParseBytes(c.Memory.MaxMemory)
+}
+
+// ParseBytes membaca ukuran gaya redis.conf: angka polos (byte) atau
+// dengan satuan k/kb, m/mb, g/gb. Satuan tanpa "b" memakai kelipatan 1000,
+// dengan "b" kelipatan 1024.
+func ParseBytes(raw string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		mul    int64
+	}{
+		{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+		{"g", 1e9}, {"m", 1e6}, {"k", 1e3}, {"b", 1},
+	}
+
+	mul := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mul = strings.TrimSuffix(s, u.suffix), u.mul
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/mul {
+		return 0, fmt.Errorf("invalid memory size %q", raw)
+	}
+	return n * mul, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"", 0, true},
+		{"0", 0, true},
+		{"1024", 1024, true},
+		{"100b", 100, true},
+		{"1k", 1000, true},
+		{"1kb", 1 << 10, true},
+		{"1m", 1_000_000, true},
+		{"1mb", 1 << 20, true},
+		{"1g", 1_000_000_000, true},
+		{"1gb", 1 << 30, true},
+		{" 2GB ", 2 << 30, true},
+		{"8589934591gb", 8589934591 << 30, true},
+
+		{"8589934592gb", 0, false}, // overflow int64
+		{"9223372036854775808", 0, false},
+		{"-1mb", 0, false},
+		{"1tb", 0, false},
+		{"1.5gb", 0, false},
+		{"gb", 0, false},
+		{"abc", 0, false},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseBytes(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("ParseBytes(%q) = %d, %v; want %d, ok=%v", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "memory:\n  maxmemory: 64mb\n  maxmemory_policy: allkeys-lru\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if n, err := cfg.MaxMemoryBytes(); err != nil || n != 64<<20 {
+		t.Fatalf("MaxMemoryBytes = %d, %v", n, err)
+	}
+	if cfg.Memory.MaxMemoryPolicy != "allkeys-lru" || cfg.Memory.MaxMemorySamples != 5 {
+		t.Fatalf("memory config = %+v", cfg.Memory)
+	}
+}
@@ -0,0 +1,288 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ferrodb/internal/config"
+)
+
+func testConfig(dir, fsync string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Data.Dir = dir
+	cfg.Data.AOFFile = "test.aof"
+	cfg.Data.AppendFsync = fsync
+	cfg.Data.AOFLoadTruncated = true
+	cfg.Engine.DBCount = 16
+	cfg.Engine.CleanupIntervalSec = 1
+	cfg.Memory.MaxMemoryPolicy = "noeviction"
+	cfg.Memory.MaxMemorySamples = 5
+	return cfg
+}
+
+// restart mematikan e lalu membangun engine baru dari AOF yang sama.
+func restart(t *testing.T, e *Engine, cfg *config.Config) *Engine {
+	t.Helper()
+
+	e.Shutdown()
+	e = New(cfg)
+	t.Cleanup(e.Shutdown)
+	return e
+}
+
+func mustExec(t *testing.T, e *Engine, args ...string) Reply {
+	t.Helper()
+
+	r := e.Execute(0, args)
+	if r.IsError() {
+		t.Fatalf("%v: %s", args, r.Str)
+	}
+	return r
+}
+
+// execCase adalah satu command beserta reply yang diharapkan.
+type execCase struct {
+	args []string
+	want Reply
+}
+
+// execCases menjalankan cases berurutan di DB 0.
+func execCases(t *testing.T, e *Engine, cases []execCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		if got := e.Execute(0, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+// TestAOFConcurrentWriteOrder memastikan write bersamaan pada key yang
+// sama masuk AOF dengan urutan yang sama seperti diterapkan di memori,
+// sehingga restart membangun dataset yang identik.
+func TestAOFConcurrentWriteOrder(t *testing.T) {
+	const (
+		workers = 8
+		ops     = 3000
+		keys    = 2
+	)
+
+	for _, fsync := range []string{"everysec", "always"} {
+		t.Run(fsync, func(t *testing.T) {
+			cfg := testConfig(t.TempDir(), fsync)
+			e := New(cfg)
+
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < ops; i++ {
+						k := strconv.Itoa(i % keys)
+						v := fmt.Sprintf("%d:%d", w, i)
+						e.Execute(0, []string{"RPUSH", "list:" + k, v})
+						e.Execute(0, []string{"SET", "str:" + k, v})
+						e.Execute(0, []string{"APPEND", "log:" + k, v + ","})
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			dump := func(e *Engine) []string {
+				var out []string
+				for k := 0; k < keys; k++ {
+					id := strconv.Itoa(k)
+					out = append(out,
+						mustExec(t, e, "LRANGE", "list:"+id, "0", "-1").String(),
+						mustExec(t, e, "GET", "str:"+id).String(),
+						mustExec(t, e, "GET", "log:"+id).String(),
+					)
+				}
+				return out
+			}
+
+			before := dump(e)
+			after := dump(restart(t, e, cfg))
+			if !reflect.DeepEqual(before, after) {
+				t.Fatal("dataset after restart differs from dataset before restart")
+			}
+		})
+	}
+}
+
+// TestAOFExpireReplay memastikan TTL yang lewat saat (atau sebelum)
+// restart tidak mengubah hasil replay: expiry dimatikan selama AOF dimuat
+// dan key yang expired saat server berjalan tercatat sebagai DEL.
+func TestAOFExpireReplay(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "everysec")
+	e := New(cfg)
+
+	// write sebelum TTL lewat: seluruh value ikut expired setelah restart
+	mustExec(t, e, "SET", "k", "v", "PX", "100")
+	mustExec(t, e, "APPEND", "k", "x")
+	mustExec(t, e, "RPUSH", "l", "a")
+	mustExec(t, e, "PEXPIRE", "l", "100")
+	mustExec(t, e, "RPUSH", "l", "b")
+	mustExec(t, e, "SET", "keep", "v", "PX", "60000")
+
+	// write setelah TTL lewat membuat key baru tanpa TTL
+	mustExec(t, e, "SET", "late", "v", "PX", "100")
+	mustExec(t, e, "RPUSH", "late-list", "a")
+	mustExec(t, e, "PEXPIRE", "late-list", "100")
+	time.Sleep(150 * time.Millisecond)
+	mustExec(t, e, "APPEND", "late", "x")
+	mustExec(t, e, "RPUSH", "late-list", "b")
+
+	e = restart(t, e, cfg)
+
+	cases := []struct {
+		args []string
+		want Reply
+	}{
+		{[]string{"GET", "k"}, Null()},
+		{[]string{"EXISTS", "l"}, Integer(0)},
+		{[]string{"GET", "keep"}, Bulk("v")},
+		{[]string{"GET", "late"}, Bulk("x")},
+		{[]string{"TTL", "late"}, Integer(-1)},
+		{[]string{"LRANGE", "late-list", "0", "-1"}, BulkArray([]string{"b"})},
+		{[]string{"TTL", "late-list"}, Integer(-1)},
+	}
+	for _, tc := range cases {
+		if got := e.Execute(0, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v = %#v, want %#v", tc.args, got, tc.want)
+		}
+	}
+	if ttl := mustExec(t, e, "PTTL", "keep"); ttl.Int <= 0 {
+		t.Errorf("PTTL keep = %d, want > 0", ttl.Int)
+	}
+}
+
+// TestAOFExpireRace menjalankan write ber-TTL pendek bersamaan dengan
+// lazy expire (GET dari goroutine lain) dan active expire. DEL dari
+// expiry harus masuk AOF dengan urutan yang sama seperti diterapkan,
+// sehingga replay menghasilkan dataset yang identik.
+func TestAOFExpireRace(t *testing.T) {
+	const (
+		workers = 4
+		keys    = 4
+	)
+
+	cfg := testConfig(t.TempDir(), "everysec")
+	cfg.Engine.NotifyKeyspaceEvents = "KEA"
+	e := New(cfg)
+
+	// cukup lama agar active expire (tiap detik) ikut berjalan
+	deadline := time.Now().Add(1200 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; time.Now().Before(deadline); i++ {
+				k := "k:" + strconv.Itoa(i%keys)
+				l := "l:" + strconv.Itoa(i%keys)
+				v := fmt.Sprintf("%d:%d", w, i)
+
+				switch i % 4 {
+				case 0:
+					e.Execute(0, []string{"SET", k, v, "PX", "1"})
+				case 1:
+					e.Execute(0, []string{"APPEND", k, "," + v})
+				case 2:
+					e.Execute(0, []string{"PEXPIRE", k, "1"})
+				case 3:
+					e.Execute(0, []string{"SET", k, v})
+				}
+				e.Execute(0, []string{"RPUSH", l, v})
+				if i%7 == 0 {
+					e.Execute(0, []string{"PEXPIRE", l, "1"})
+				}
+				// d tanpa TTL menyimpan jejak isi l sehingga urutan DEL
+				// yang salah tetap terlihat setelah l expired lagi
+				if i%3 == 0 {
+					e.Execute(0, []string{"LMOVE", l, "d:" + strconv.Itoa(i%keys), "LEFT", "RIGHT"})
+				}
+
+				// jeda acak agar TTL lewat di titik yang berbeda-beda
+				if i%16 == 0 {
+					time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+				}
+			}
+		}(w)
+	}
+
+	// reader memicu lazy expire di luar command write
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; time.Now().Before(deadline); i++ {
+			e.Execute(0, []string{"GET", "k:" + strconv.Itoa(i%keys)})
+			e.Execute(0, []string{"EXISTS", "l:" + strconv.Itoa(i%keys)})
+		}
+	}()
+	wg.Wait()
+
+	// semua TTL sudah lewat, jadi dataset tidak lagi bergantung pada jam
+	time.Sleep(10 * time.Millisecond)
+
+	dump := func(e *Engine) []string {
+		var out []string
+		for k := 0; k < keys; k++ {
+			id := strconv.Itoa(k)
+			out = append(out,
+				mustExec(t, e, "GET", "k:"+id).String(),
+				mustExec(t, e, "LRANGE", "l:"+id, "0", "-1").String(),
+				mustExec(t, e, "LRANGE", "d:"+id, "0", "-1").String(),
+			)
+		}
+		return out
+	}
+
+	before := dump(e)
+	after := dump(restart(t, e, cfg))
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("dataset after restart differs:\nbefore %q\nafter  %q", before, after)
+	}
+}
+
+// TestAOFLoadTruncated mensimulasikan crash di tengah write: dengan
+// aof_load_truncated ekor yang terpotong dibuang saat start dan write
+// berikutnya tetap bisa di-replay. Kasus aof_load_truncated false
+// menghentikan proses, lihat TestReplayTruncated di persistence.
+func TestAOFLoadTruncated(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "always")
+	e := New(cfg)
+
+	mustExec(t, e, "SET", "a", "1")
+	mustExec(t, e, "RPUSH", "l", "x", "y")
+	e.Shutdown()
+
+	file, err := os.OpenFile(cfg.AOFPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteString("*1\r\n$5\r\nMULTI\r\n*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n2")
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e = New(cfg)
+	mustExec(t, e, "SET", "b", "2")
+	e = restart(t, e, cfg)
+
+	execCases(t, e, []execCase{
+		{[]string{"GET", "a"}, Bulk("1")},
+		{[]string{"GET", "b"}, Bulk("2")},
+		{[]string{"LRANGE", "l", "0", "-1"}, BulkArray([]string{"x", "y"})},
+	})
+}
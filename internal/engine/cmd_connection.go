@@ -0,0 +1,57 @@
+package engine
+
+// Command di file ini hanya berisi metadata. Eksekusinya butuh state
+// koneksi (user, DB aktif) sehingga ditangani oleh server.
+func init() {
+	registerCommands(
+		&Command{
+			Name: "AUTH", Arity: 3, Flags: FlagNoAuth | FlagConnection | FlagFast,
+			Category: "connection", Syntax: "username password",
+		},
+		&Command{
+			Name: "LOGOUT", Arity: 1, Flags: FlagConnection | FlagFast,
+			Category: "connection",
+		},
+		&Command{
+			Name: "SELECT", Arity: 2, Flags: FlagConnection | FlagFast,
+			Category: "connection", Syntax: "db",
+		},
+		&Command{
+			Name: "ACL", Arity: -2, Flags: FlagConnection,
+			Category: "connection", Syntax: "WHOAMI|LIST|CAT [category]",
+		},
+		&Command{
+			Name: "HELLO", Arity: -1, Flags: FlagNoAuth | FlagConnection | FlagFast,
+			Category: "connection", Syntax: "[protover]",
+		},
+		&Command{
+			Name: "QUIT", Arity: 1, Flags: FlagNoAuth | FlagConnection | FlagFast,
+			Category: "connection",
+		},
+		&Command{
+			Name: "EXIT", Arity: 1, Flags: FlagNoAuth | FlagConnection | FlagFast,
+			Category: "connection",
+		},
+		&Command{
+			Name: "MULTI", Arity: 1, Flags: FlagConnection | FlagFast,
+			Category: "transaction",
+		},
+		&Command{
+			Name: "EXEC", Arity: 1, Flags: FlagConnection,
+			Category: "transaction",
+		},
+		&Command{
+			Name: "DISCARD", Arity: 1, Flags: FlagConnection | FlagFast,
+			Category: "transaction",
+		},
+		&Command{
+			Name: "WATCH", Arity: -2, Flags: FlagConnection | FlagFast,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "transaction", Syntax: "key [key ...]",
+		},
+		&Command{
+			Name: "UNWATCH", Arity: 1, Flags: FlagConnection | FlagFast,
+			Category: "transaction",
+		},
+	)
+}
@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "HSET", Arity: -4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field value [field value ...]",
+			Handler: hsetCommand,
+		},
+		&Command{
+			Name: "HMSET", Arity: -4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field value [field value ...]",
+			Handler: hsetCommand,
+		},
+		&Command{
+			Name: "HSETNX", Arity: 4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field value",
+			Handler: hsetNXCommand,
+		},
+		&Command{
+			Name: "HGET", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field",
+			Handler: hgetCommand,
+		},
+		&Command{
+			Name: "HMGET", Arity: -3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field [field ...]",
+			Handler: hmgetCommand,
+		},
+		&Command{
+			Name: "HGETALL", Arity: 2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key",
+			Handler: hgetAllCommand,
+		},
+		&Command{
+			Name: "HKEYS", Arity: 2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key",
+			Handler: hgetAllCommand,
+		},
+		&Command{
+			Name: "HVALS", Arity: 2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key",
+			Handler: hgetAllCommand,
+		},
+		&Command{
+			Name: "HDEL", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field [field ...]",
+			Handler: hdelCommand,
+		},
+		&Command{
+			Name: "HEXISTS", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field",
+			Handler: hexistsCommand,
+		},
+		&Command{
+			Name: "HLEN", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key",
+			Handler: hlenCommand,
+		},
+		&Command{
+			Name: "HSTRLEN", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field",
+			Handler: hstrlenCommand,
+		},
+		&Command{
+			Name: "HINCRBY", Arity: 4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field increment",
+			Handler: hincrByCommand,
+		},
+		&Command{
+			Name: "HINCRBYFLOAT", Arity: 4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key field increment",
+			Handler: hincrByFloatCommand,
+		},
+		&Command{
+			Name: "HSCAN", Arity: -3, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "hash", Syntax: "key cursor [MATCH pattern] [COUNT count]",
+			Handler: hscanCommand,
+		},
+	)
+}
+
+func hsetCommand(ctx *Context, args []string) Reply {
+	if len(args)%2 != 0 {
+		return wrongArgs(args[0])
+	}
+
+	added, err := ctx.store.HSet(ctx.DB, args[1], args[2:], false)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if strings.EqualFold(args[0], "HMSET") {
+		return OK()
+	}
+	return Integer(int64(added))
+}
+
+func hsetNXCommand(ctx *Context, args []string) Reply {
+	added, err := ctx.store.HSet(ctx.DB, args[1], args[2:], true)
+	if err != nil {
+		return errorReply(err)
+	}
+	if added == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(added))
+}
+
+func hgetCommand(ctx *Context, args []string) Reply {
+	val, ok, err := ctx.store.HGet(ctx.DB, args[1], args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return Null()
+	}
+	return Bulk(val)
+}
+
+func hmgetCommand(ctx *Context, args []string) Reply {
+	values, found, err := ctx.store.HMGet(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	out := make([]Reply, len(values))
+	for i := range values {
+		if found[i] {
+			out[i] = Bulk(values[i])
+		} else {
+			out[i] = Null()
+		}
+	}
+	return Array(out...)
+}
+
+// hgetAllCommand melayani HGETALL, HKEYS dan HVALS.
+func hgetAllCommand(ctx *Context, args []string) Reply {
+	h, err := ctx.store.HGetAll(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	name := strings.ToUpper(args[0])
+	out := make([]Reply, 0, len(h)*2)
+	for f, v := range h {
+		switch name {
+		case "HKEYS":
+			out = append(out, Bulk(f))
+		case "HVALS":
+			out = append(out, Bulk(v))
+		default:
+			out = append(out, Bulk(f), Bulk(v))
+		}
+	}
+
+	if name == "HGETALL" {
+		return Map(out...)
+	}
+	return Array(out...)
+}
+
+func hdelCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.HDel(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func hexistsCommand(ctx *Context, args []string) Reply {
+	_, ok, err := ctx.store.HGet(ctx.DB, args[1], args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+	if ok {
+		return Integer(1)
+	}
+	return Integer(0)
+}
+
+func hlenCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.HLen(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func hstrlenCommand(ctx *Context, args []string) Reply {
+	val, _, err := ctx.store.HGet(ctx.DB, args[1], args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(len(val)))
+}
+
+func hincrByCommand(ctx *Context, args []string) Reply {
+	delta, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	n, err := ctx.store.HIncrBy(ctx.DB, args[1], args[2], delta)
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(n)
+}
+
+func hincrByFloatCommand(ctx *Context, args []string) Reply {
+	delta, err := strconv.ParseFloat(args[3], 64)
+	if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return errorReply(storage.ErrNotFloat)
+	}
+
+	val, err := ctx.store.HIncrByFloat(ctx.DB, args[1], args[2], delta)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	ctx.Propagate("HSET", args[1], args[2], val)
+	return Bulk(val)
+}
+
+func hscanCommand(ctx *Context, args []string) Reply {
+	opts, err := parseScanArgs(args, 2)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	next, pairs, err := ctx.store.HScan(ctx.DB, args[1], opts.cursor, opts.count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var items []Reply
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if opts.matches(pairs[i]) {
+			items = append(items, Bulk(pairs[i]), Bulk(pairs[i+1]))
+		}
+	}
+	return scanReply(next, items)
+}
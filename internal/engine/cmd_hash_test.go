@@ -0,0 +1,71 @@
+package engine
+
+import "testing"
+
+func TestHashCommands(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	wrongType := Error("WRONGTYPE Operation against a key holding the wrong kind of value")
+	execCases(t, e, []execCase{
+		{[]string{"HSET", "h", "a", "1", "b", "2"}, Integer(2)},
+		{[]string{"HSET", "h", "a", "3"}, Integer(0)},
+		{[]string{"HSET", "h", "a"}, Error("ERR wrong number of arguments for 'hset' command")},
+		{[]string{"HGET", "h", "a"}, Bulk("3")},
+		{[]string{"HGET", "h", "x"}, Null()},
+		{[]string{"HMGET", "h", "a", "x"}, Array(Bulk("3"), Null())},
+		{[]string{"HSETNX", "h", "a", "z"}, Integer(0)},
+		{[]string{"HSETNX", "h", "c", "z"}, Integer(1)},
+		{[]string{"HLEN", "h"}, Integer(3)},
+		{[]string{"HEXISTS", "h", "c"}, Integer(1)},
+		{[]string{"HSTRLEN", "h", "c"}, Integer(1)},
+
+		{[]string{"HINCRBY", "h", "a", "-5"}, Integer(-2)},
+		{[]string{"HINCRBY", "h", "n", "9223372036854775807"}, Integer(9223372036854775807)},
+		{[]string{"HINCRBY", "h", "n", "1"}, Error("ERR increment or decrement would overflow")},
+		{[]string{"HINCRBY", "h", "c", "1"}, Error("ERR hash value is not an integer")},
+		{[]string{"HINCRBYFLOAT", "h", "f", "0.5"}, Bulk("0.5")},
+		{[]string{"HINCRBYFLOAT", "h", "f", "1e1"}, Bulk("10.5")},
+		{[]string{"HINCRBYFLOAT", "h", "c", "1"}, Error("ERR hash value is not a float")},
+
+		{[]string{"HDEL", "h", "a", "b", "c", "n", "f", "x"}, Integer(5)},
+		{[]string{"EXISTS", "h"}, Integer(0)},
+		{[]string{"HGETALL", "h"}, Map()},
+
+		{[]string{"SET", "s", "v"}, OK()},
+		{[]string{"HGET", "s", "a"}, wrongType},
+		{[]string{"HSET", "s", "a", "1"}, wrongType},
+	})
+}
+
+func TestHashScan(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "HSET", "h", "a1", "1", "a2", "2", "b1", "3")
+	execCases(t, e, []execCase{
+		{[]string{"HSCAN", "h", "0", "MATCH", "b*"}, Array(Bulk("0"), BulkArray([]string{"b1", "3"}))},
+		{[]string{"HSCAN", "missing", "0"}, Array(Bulk("0"), Array())},
+		{[]string{"HSCAN", "h", "0", "COUNT", "0"}, Error("ERR syntax error")},
+		{[]string{"HSCAN", "h", "x"}, Error("ERR invalid cursor")},
+	})
+
+	// COUNT 1 tetap mengembalikan setiap field tepat sekali
+	seen := map[string]string{}
+	for cursor := "0"; ; {
+		r := mustExec(t, e, "HSCAN", "h", cursor, "COUNT", "1")
+		pairs := r.Elems[1].Elems
+		for i := 0; i < len(pairs); i += 2 {
+			if _, dup := seen[pairs[i].Str]; dup {
+				t.Fatalf("field %q returned twice", pairs[i].Str)
+			}
+			seen[pairs[i].Str] = pairs[i+1].Str
+		}
+		if cursor = r.Elems[0].Str; cursor == "0" {
+			break
+		}
+	}
+	if len(seen) != 3 || seen["a1"] != "1" || seen["a2"] != "2" || seen["b1"] != "3" {
+		t.Fatalf("HSCAN returned %v", seen)
+	}
+}
@@ -0,0 +1,519 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ferrodb/internal/glob"
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "DEL", Arity: -2, Flags: FlagWrite,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "keyspace", Syntax: "key [key ...]",
+			Handler: delCommand,
+		},
+		&Command{
+			Name: "UNLINK", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "keyspace", Syntax: "key [key ...]",
+			Handler: delCommand,
+		},
+		&Command{
+			Name: "EXISTS", Arity: -2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "keyspace", Syntax: "key [key ...]",
+			Handler: existsCommand,
+		},
+		&Command{
+			Name: "TYPE", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: typeCommand,
+		},
+		&Command{
+			Name: "RENAME", Arity: 3, Flags: FlagWrite,
+			FirstKey: 1, LastKey: 2, Step: 1,
+			Category: "keyspace", Syntax: "key newkey",
+			Handler: renameCommand,
+		},
+		&Command{
+			Name: "RENAMENX", Arity: 3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 2, Step: 1,
+			Category: "keyspace", Syntax: "key newkey",
+			Handler: renameCommand,
+		},
+		&Command{
+			Name: "COPY", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 2, Step: 1, CrossDB: true,
+			Category: "keyspace", Syntax: "source destination [DB destination-db] [REPLACE]",
+			Handler: copyCommand,
+		},
+		&Command{
+			Name: "MOVE", Arity: 3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1, CrossDB: true,
+			Category: "keyspace", Syntax: "key db",
+			Handler: moveCommand,
+		},
+		&Command{
+			Name: "RANDOMKEY", Arity: 1, Flags: FlagReadOnly,
+			Category: "keyspace",
+			Handler:  randomKeyCommand,
+		},
+		&Command{
+			Name: "DBSIZE", Arity: 1, Flags: FlagReadOnly | FlagFast,
+			Category: "keyspace",
+			Handler:  dbSizeCommand,
+		},
+		&Command{
+			Name: "FLUSHDB", Arity: -1, Flags: FlagWrite | FlagAdmin,
+			Category: "keyspace", Syntax: "[ASYNC|SYNC]",
+			Handler: flushCommand,
+		},
+		&Command{
+			Name: "FLUSHALL", Arity: -1, Flags: FlagWrite | FlagAdmin,
+			Category: "keyspace", Syntax: "[ASYNC|SYNC]",
+			Handler: flushCommand,
+		},
+		&Command{
+			Name: "SWAPDB", Arity: 3, Flags: FlagWrite | FlagAdmin | FlagFast,
+			Category: "keyspace", Syntax: "index1 index2",
+			Handler: swapDBCommand,
+		},
+		&Command{
+			Name: "EXPIRE", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key seconds [NX|XX|GT|LT]",
+			Handler: expireCommand,
+		},
+		&Command{
+			Name: "PEXPIRE", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key milliseconds [NX|XX|GT|LT]",
+			Handler: expireCommand,
+		},
+		&Command{
+			Name: "EXPIREAT", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key unix-time-seconds [NX|XX|GT|LT]",
+			Handler: expireCommand,
+		},
+		&Command{
+			Name: "PEXPIREAT", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key unix-time-milliseconds [NX|XX|GT|LT]",
+			Handler: expireCommand,
+		},
+		&Command{
+			Name: "TTL", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: ttlCommand,
+		},
+		&Command{
+			Name: "PTTL", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: ttlCommand,
+		},
+		&Command{
+			Name: "EXPIRETIME", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: expireTimeCommand,
+		},
+		&Command{
+			Name: "PEXPIRETIME", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: expireTimeCommand,
+		},
+		&Command{
+			Name: "PERSIST", Arity: 2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "keyspace", Syntax: "key",
+			Handler: persistCommand,
+		},
+		&Command{
+			Name: "KEYS", Arity: 2, Flags: FlagReadOnly,
+			Category: "keyspace", Syntax: "pattern",
+			Handler: keysCommand,
+		},
+		&Command{
+			Name: "SCAN", Arity: -2, Flags: FlagReadOnly,
+			Category: "keyspace", Syntax: "cursor [MATCH pattern] [COUNT count] [TYPE type]",
+			Handler: scanCommand,
+		},
+		&Command{
+			Name: "OBJECT", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 2, LastKey: 2, Step: 1,
+			Category: "keyspace", Syntax: "ENCODING|IDLETIME|FREQ|REFCOUNT key",
+			Handler: objectCommand,
+		},
+	)
+}
+
+func delCommand(ctx *Context, args []string) Reply {
+	deleted := ctx.store.Del(ctx.DB, args[1:]...)
+	if deleted == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(deleted))
+}
+
+// expireCommand menangani EXPIRE, PEXPIRE, EXPIREAT dan PEXPIREAT.
+// Semuanya dipropagasi sebagai PEXPIREAT agar replay tidak memperpanjang
+// TTL.
+func expireCommand(ctx *Context, args []string) Reply {
+	name := strings.ToUpper(args[0])
+	absolute := strings.HasSuffix(name, "AT")
+
+	n, err := strconv.ParseInt(args[2], 10, 64)
+	switch {
+	case err != nil && absolute:
+		return Error("ERR invalid timestamp")
+	case err != nil || (!absolute && n <= 0):
+		return Error("ERR invalid TTL")
+	}
+
+	cond, err := parseExpireCond(args[3:])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	// semua bentuk dinormalisasi ke unix milliseconds
+	unit := int64(1000)
+	if strings.HasPrefix(name, "P") {
+		unit = 1
+	}
+	if n > math.MaxInt64/unit || n < math.MinInt64/unit {
+		return Errorf("ERR invalid expire time in '%s' command", strings.ToLower(name))
+	}
+	at := n * unit
+	if !absolute {
+		now := time.Now().UnixMilli()
+		if at > math.MaxInt64-now {
+			return Errorf("ERR invalid expire time in '%s' command", strings.ToLower(name))
+		}
+		at += now
+	}
+
+	if !ctx.store.ExpireAt(ctx.DB, args[1], at, cond) {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+
+	ctx.Propagate("PEXPIREAT", args[1], strconv.FormatInt(at, 10))
+	return Integer(1)
+}
+
+// parseExpireCond membaca opsi NX|XX|GT|LT dengan aturan kombinasi Redis.
+func parseExpireCond(opts []string) (storage.ExpireCond, error) {
+	var nx, xx, gt, lt bool
+	for _, opt := range opts {
+		switch strings.ToUpper(opt) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "GT":
+			gt = true
+		case "LT":
+			lt = true
+		default:
+			return 0, fmt.Errorf("ERR Unsupported option %s", opt)
+		}
+	}
+
+	switch {
+	case nx && (xx || gt || lt):
+		return 0, errors.New("ERR NX and XX, GT or LT options at the same time are not compatible")
+	case gt && lt:
+		return 0, errors.New("ERR GT and LT options at the same time are not compatible")
+	case nx:
+		return storage.ExpireNX, nil
+	case gt:
+		return storage.ExpireGT, nil
+	case lt:
+		return storage.ExpireLT, nil
+	case xx:
+		return storage.ExpireXX, nil
+	}
+	return storage.ExpireAlways, nil
+}
+
+// ttlCommand menangani TTL (detik, dibulatkan) dan PTTL (milidetik).
+func ttlCommand(ctx *Context, args []string) Reply {
+	ttl := ctx.store.TTL(ctx.DB, args[1])
+	if ttl >= 0 && strings.EqualFold(args[0], "TTL") {
+		ttl = (ttl + 500) / 1000
+	}
+	return Integer(ttl)
+}
+
+// expireTimeCommand menangani EXPIRETIME (detik) dan PEXPIRETIME.
+func expireTimeCommand(ctx *Context, args []string) Reply {
+	at := ctx.store.ExpireTime(ctx.DB, args[1])
+	if at >= 0 && strings.EqualFold(args[0], "EXPIRETIME") {
+		at /= 1000
+	}
+	return Integer(at)
+}
+
+func persistCommand(ctx *Context, args []string) Reply {
+	if !ctx.store.Persist(ctx.DB, args[1]) {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+	return Integer(1)
+}
+
+func keysCommand(ctx *Context, args []string) Reply {
+	keys := ctx.store.Keys(ctx.DB)
+	if args[1] == "*" {
+		return BulkArray(keys)
+	}
+
+	out := keys[:0]
+	for _, key := range keys {
+		if glob.Match(args[1], key) {
+			out = append(out, key)
+		}
+	}
+	return BulkArray(out)
+}
+
+// scanCommand mengiterasi keyspace secara bertahap. Cursor berasal dari
+// index di store sehingga key yang ada sepanjang iterasi pasti muncul.
+func scanCommand(ctx *Context, args []string) Reply {
+	opts, typ, err := parseKeyScanArgs(args)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	next, keys := ctx.store.Scan(ctx.DB, opts.cursor, opts.count)
+
+	var items []Reply
+	for _, key := range keys {
+		if !opts.matches(key) {
+			continue
+		}
+		if typ != "" && !typeMatches(ctx.store, ctx.DB, key, typ) {
+			continue
+		}
+		items = append(items, Bulk(key))
+	}
+	return scanReply(next, items)
+}
+
+func existsCommand(ctx *Context, args []string) Reply {
+	return Integer(int64(ctx.store.Exists(ctx.DB, args[1:]...)))
+}
+
+func typeCommand(ctx *Context, args []string) Reply {
+	return Simple(keyType(ctx.store, ctx.DB, args[1]))
+}
+
+// renameCommand menangani RENAME dan RENAMENX; TTL ikut pindah.
+func renameCommand(ctx *Context, args []string) Reply {
+	nx := strings.EqualFold(args[0], "RENAMENX")
+
+	renamed, err := ctx.store.Rename(ctx.DB, args[1], args[2], nx)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !renamed {
+		ctx.SkipPropagate()
+	} else {
+		ctx.engine.waits.signal(ctx.DB, args[2])
+	}
+
+	if !nx {
+		return OK()
+	}
+	if renamed {
+		return Integer(1)
+	}
+	return Integer(0)
+}
+
+func copyCommand(ctx *Context, args []string) Reply {
+	dstDB := ctx.DB
+	replace := false
+
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "REPLACE":
+			replace = true
+		case "DB":
+			if i+1 >= len(args) {
+				return errorReply(errSyntax)
+			}
+			db, err := parseDBIndex(ctx, args[i+1])
+			if err != nil {
+				return errorReply(err)
+			}
+			dstDB = db
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	copied, err := ctx.store.Copy(ctx.DB, args[1], dstDB, args[2], replace)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !copied {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+
+	ctx.engine.waits.signal(dstDB, args[2])
+	ctx.engine.notify(notifyGeneric, "copy_to", dstDB, args[2])
+	return Integer(1)
+}
+
+func moveCommand(ctx *Context, args []string) Reply {
+	dstDB, err := parseDBIndex(ctx, args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	moved, err := ctx.store.Move(ctx.DB, args[1], dstDB)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !moved {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+
+	ctx.engine.waits.signal(dstDB, args[1])
+	ctx.engine.notify(notifyGeneric, "move_to", dstDB, args[1])
+	return Integer(1)
+}
+
+func randomKeyCommand(ctx *Context, args []string) Reply {
+	key, ok := ctx.store.RandomKey(ctx.DB)
+	if !ok {
+		return Null()
+	}
+	return Bulk(key)
+}
+
+func dbSizeCommand(ctx *Context, args []string) Reply {
+	return Integer(int64(ctx.store.DBSize(ctx.DB)))
+}
+
+// flushCommand menangani FLUSHDB dan FLUSHALL. ASYNC dan SYNC sama-sama
+// O(1) karena map lama cukup dilepas ke GC.
+func flushCommand(ctx *Context, args []string) Reply {
+	if len(args) > 2 {
+		return errorReply(errSyntax)
+	}
+	if len(args) == 2 {
+		if mode := strings.ToUpper(args[1]); mode != "ASYNC" && mode != "SYNC" {
+			return errorReply(errSyntax)
+		}
+	}
+
+	if strings.EqualFold(args[0], "FLUSHALL") {
+		ctx.store.FlushAll()
+		ctx.Propagate("FLUSHALL")
+	} else {
+		ctx.store.Flush(ctx.DB)
+		ctx.Propagate("FLUSHDB")
+	}
+	return OK()
+}
+
+func swapDBCommand(ctx *Context, args []string) Reply {
+	a, err := strconv.Atoi(args[1])
+	if err != nil {
+		return Error("ERR invalid first DB index")
+	}
+	b, err := strconv.Atoi(args[2])
+	if err != nil {
+		return Error("ERR invalid second DB index")
+	}
+	if !validDB(ctx, a) || !validDB(ctx, b) {
+		return errorReply(errDBRange)
+	}
+
+	ctx.store.SwapDB(a, b)
+
+	// client blocking di kedua DB mungkin kini punya data
+	ctx.engine.waits.signalDB(a)
+	ctx.engine.waits.signalDB(b)
+	return OK()
+}
+
+func validDB(ctx *Context, db int) bool {
+	return db >= 0 && db < ctx.store.DBCount()
+}
+
+func parseDBIndex(ctx *Context, s string) (int, error) {
+	db, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, storage.ErrNotInteger
+	}
+	if !validDB(ctx, db) {
+		return 0, errDBRange
+	}
+	return db, nil
+}
+
+// objectCommand membaca metadata key tanpa memperbarui waktu akses atau
+// counter LFU-nya.
+func objectCommand(ctx *Context, args []string) Reply {
+	sub := strings.ToUpper(args[1])
+
+	switch {
+	case sub == "HELP" && len(args) == 2:
+		return BulkArray([]string{
+			"OBJECT <subcommand> [<arg> [value] [opt] ...]. Subcommands are:",
+			"ENCODING <key>",
+			"    Return the kind of internal representation used in order to store the value",
+			"    associated with a <key>.",
+			"FREQ <key>",
+			"    Return the access frequency index of the <key>.",
+			"IDLETIME <key>",
+			"    Return the idle time of the <key>, that is the approximated number of",
+			"    seconds elapsed since the last access to the key.",
+			"REFCOUNT <key>",
+			"    Return the number of references of the value associated with the specified",
+			"    <key>.",
+		})
+
+	case sub == "ENCODING" || sub == "IDLETIME" || sub == "FREQ" || sub == "REFCOUNT":
+		if len(args) != 3 {
+			return Errorf("ERR wrong number of arguments for 'object|%s' command", strings.ToLower(sub))
+		}
+
+		info, ok := ctx.store.Object(ctx.DB, args[2])
+		if !ok {
+			return Null()
+		}
+
+		switch sub {
+		case "ENCODING":
+			return Bulk(info.Encoding)
+		case "IDLETIME":
+			return Integer(info.Idle / 1000)
+		case "FREQ":
+			return Integer(int64(info.Freq))
+		default:
+			// value tidak pernah dibagi antar key
+			return Integer(1)
+		}
+	}
+	return Errorf("ERR unknown subcommand '%s'. Try OBJECT HELP.", args[1])
+}
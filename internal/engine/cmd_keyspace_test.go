@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// scanAll mengikuti cursor SCAN sampai kembali ke 0 dan mengembalikan
+// semua key yang dikembalikan, terurut.
+func scanAll(t *testing.T, e *Engine, db int, opts ...string) []string {
+	t.Helper()
+
+	var keys []string
+	for cursor, calls := "0", 0; ; calls++ {
+		if calls > 10000 {
+			t.Fatal("SCAN did not terminate")
+		}
+		r := e.Execute(db, append([]string{"SCAN", cursor}, opts...))
+		if r.IsError() {
+			t.Fatalf("SCAN %s: %v", cursor, r)
+		}
+		for _, el := range r.Elems[1].Elems {
+			keys = append(keys, el.Str)
+		}
+		if cursor = r.Elems[0].Str; cursor == "0" {
+			break
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestScan(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	var want []string
+	for i := 0; i < 200; i++ {
+		key := "k" + strconv.Itoa(i)
+		mustExec(t, e, "SET", key, "v")
+		want = append(want, key)
+	}
+	mustExec(t, e, "RPUSH", "list", "a")
+	mustExec(t, e, "SET", "gone", "v", "PX", "1")
+	e.Execute(1, []string{"SET", "other-db", "v"})
+	time.Sleep(5 * time.Millisecond)
+	sort.Strings(want)
+
+	// COUNT kecil tetap mengembalikan setiap key tepat sekali
+	got := scanAll(t, e, 0, "COUNT", "3", "TYPE", "string")
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SCAN TYPE string returned %d keys, want %d", len(got), len(want))
+	}
+	if got := scanAll(t, e, 0, "MATCH", "k1?", "COUNT", "7"); !reflect.DeepEqual(got, []string{
+		"k10", "k11", "k12", "k13", "k14", "k15", "k16", "k17", "k18", "k19",
+	}) {
+		t.Fatalf("SCAN MATCH k1? = %v", got)
+	}
+	if got := scanAll(t, e, 0, "TYPE", "LIST"); !reflect.DeepEqual(got, []string{"list"}) {
+		t.Fatalf("SCAN TYPE list = %v", got)
+	}
+	if got := scanAll(t, e, 1); !reflect.DeepEqual(got, []string{"other-db"}) {
+		t.Fatalf("SCAN on db 1 = %v", got)
+	}
+
+	execCases(t, e, []execCase{
+		{[]string{"SCAN", "x"}, Error("ERR invalid cursor")},
+		{[]string{"SCAN", "0", "COUNT", "0"}, Error("ERR syntax error")},
+		{[]string{"SCAN", "0", "COUNT", "x"}, Error("ERR value is not an integer or out of range")},
+		{[]string{"SCAN", "0", "MATCH"}, Error("ERR syntax error")},
+		{[]string{"SCAN", "0", "LIMIT", "1"}, Error("ERR syntax error")},
+		{[]string{"SCAN", "18446744073709551615"}, Array(Bulk("0"), Array())},
+	})
+}
+
+// TestScanTypeNoTouch memastikan filter TYPE pada SCAN hanya mengintip
+// key: counter LFU tidak berubah dan key expired tidak ikut dikembalikan.
+func TestScanTypeNoTouch(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "s", "v")
+	mustExec(t, e, "HSET", "h", "f", "v")
+	mustExec(t, e, "SET", "gone", "v", "PX", "1")
+	time.Sleep(5 * time.Millisecond)
+
+	freq := mustExec(t, e, "OBJECT", "FREQ", "s")
+	for i := 0; i < 20; i++ {
+		got := mustExec(t, e, "SCAN", "0", "TYPE", "STRING", "COUNT", "100")
+		if want := Array(Bulk("0"), BulkArray([]string{"s"})); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SCAN TYPE string = %v, want %v", got, want)
+		}
+	}
+	if got := mustExec(t, e, "OBJECT", "FREQ", "s"); !reflect.DeepEqual(got, freq) {
+		t.Fatalf("OBJECT FREQ after SCAN = %v, want %v", got, freq)
+	}
+}
+
+func TestKeysPattern(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	for _, key := range []string{"user:1", "user:2", "user:*", "admin"} {
+		mustExec(t, e, "SET", key, "v")
+	}
+
+	cases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*", []string{"admin", "user:*", "user:1", "user:2"}},
+		{"user:[^2]", []string{"user:*", "user:1"}},
+		{`user:\*`, []string{"user:*"}},
+		{"[a-b]*", []string{"admin"}},
+		{"nothing*", []string{}},
+	}
+	for _, tc := range cases {
+		if got := members(mustExec(t, e, "KEYS", tc.pattern)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("KEYS %q = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestKeyspaceCommands(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	execCases(t, e, []execCase{
+		{[]string{"SET", "a", "1"}, OK()},
+		{[]string{"RPUSH", "l", "x"}, Integer(1)},
+		{[]string{"EXISTS", "a", "a", "missing"}, Integer(2)},
+		{[]string{"TYPE", "l"}, Simple("list")},
+		{[]string{"TYPE", "missing"}, Simple("none")},
+		{[]string{"DBSIZE"}, Integer(2)},
+
+		// RENAME membawa TTL dan menimpa tujuan
+		{[]string{"EXPIRE", "a", "100"}, Integer(1)},
+		{[]string{"RENAME", "a", "l"}, OK()},
+		{[]string{"TTL", "l"}, Integer(100)},
+		{[]string{"TYPE", "l"}, Simple("string")},
+		{[]string{"RENAME", "missing", "x"}, Error("ERR no such key")},
+		{[]string{"RENAMENX", "l", "l"}, Integer(0)},
+		{[]string{"SET", "b", "2"}, OK()},
+		{[]string{"RENAMENX", "l", "b"}, Integer(0)},
+		{[]string{"RENAMENX", "l", "c"}, Integer(1)},
+
+		// COPY adalah salinan terpisah dan butuh REPLACE untuk menimpa
+		{[]string{"RPUSH", "src", "x"}, Integer(1)},
+		{[]string{"COPY", "src", "dst"}, Integer(1)},
+		{[]string{"COPY", "src", "b"}, Integer(0)},
+		{[]string{"COPY", "src", "b", "REPLACE"}, Integer(1)},
+		{[]string{"RPUSH", "dst", "y"}, Integer(2)},
+		{[]string{"LLEN", "src"}, Integer(1)},
+		{[]string{"COPY", "src", "src"}, Error("ERR source and destination objects are the same")},
+		{[]string{"COPY", "src", "other", "DB", "16"}, Error("ERR DB index is out of range")},
+		{[]string{"COPY", "src", "other", "DB", "1"}, Integer(1)},
+
+		{[]string{"MOVE", "c", "1"}, Integer(1)},
+		{[]string{"MOVE", "c", "1"}, Integer(0)},
+		{[]string{"MOVE", "b", "0"}, Error("ERR source and destination objects are the same")},
+		{[]string{"MOVE", "b", "x"}, Error("ERR value is not an integer or out of range")},
+		{[]string{"EXISTS", "c"}, Integer(0)},
+	})
+
+	if got := e.Execute(1, []string{"TTL", "c"}); !reflect.DeepEqual(got, Integer(100)) {
+		t.Fatalf("TTL after MOVE = %v, want 100", got)
+	}
+	if got := e.Execute(1, []string{"DBSIZE"}); !reflect.DeepEqual(got, Integer(2)) {
+		t.Fatalf("DBSIZE 1 = %v, want 2", got)
+	}
+}
+
+func TestFlushAndSwapDB(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "no")
+	e := New(cfg)
+
+	mustExec(t, e, "SET", "a", "0")
+	e.Execute(1, []string{"SET", "b", "1"})
+	e.Execute(2, []string{"SET", "c", "2"})
+
+	execCases(t, e, []execCase{
+		{[]string{"SWAPDB", "0", "1"}, OK()},
+		{[]string{"SWAPDB", "0", "x"}, Error("ERR invalid second DB index")},
+		{[]string{"SWAPDB", "0", "16"}, Error("ERR DB index is out of range")},
+		{[]string{"RANDOMKEY"}, Bulk("b")},
+		{[]string{"FLUSHDB", "LAZY"}, Error("ERR syntax error")},
+	})
+	if got := e.Execute(1, []string{"GET", "a"}); !reflect.DeepEqual(got, Bulk("0")) {
+		t.Fatalf("GET a on db 1 after SWAPDB = %v", got)
+	}
+	if got := e.Execute(2, []string{"FLUSHDB", "ASYNC"}); !reflect.DeepEqual(got, OK()) {
+		t.Fatalf("FLUSHDB ASYNC = %v", got)
+	}
+
+	// SWAPDB dan FLUSHDB ikut di-replay dari AOF
+	e = restart(t, e, cfg)
+	for db, want := range []Reply{Integer(1), Integer(1), Integer(0)} {
+		if got := e.Execute(db, []string{"DBSIZE"}); !reflect.DeepEqual(got, want) {
+			t.Errorf("DBSIZE %d after restart = %v, want %v", db, got, want)
+		}
+	}
+	if got := mustExec(t, e, "GET", "b"); !reflect.DeepEqual(got, Bulk("1")) {
+		t.Fatalf("GET b on db 0 after restart = %v", got)
+	}
+
+	execCases(t, e, []execCase{
+		{[]string{"FLUSHALL"}, OK()},
+		{[]string{"RANDOMKEY"}, Null()},
+	})
+	if got := e.Execute(1, []string{"DBSIZE"}); !reflect.DeepEqual(got, Integer(0)) {
+		t.Fatalf("DBSIZE 1 after FLUSHALL = %v", got)
+	}
+}
+
+func TestObject(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "int", "12345")
+	mustExec(t, e, "SET", "short", "hello")
+	mustExec(t, e, "SET", "long", string(make([]byte, 45)))
+	mustExec(t, e, "HSET", "h", "f", "v")
+	mustExec(t, e, "SADD", "s", "m")
+	mustExec(t, e, "RPUSH", "l", "x")
+	mustExec(t, e, "ZADD", "z", "1", "m")
+	mustExec(t, e, "XADD", "x", "*", "f", "v")
+
+	encodings := map[string]string{
+		"int": "int", "short": "embstr", "long": "raw",
+		"h": "hashtable", "s": "hashtable", "l": "quicklist",
+		"z": "skiplist", "x": "stream",
+	}
+	for key, want := range encodings {
+		if got := mustExec(t, e, "OBJECT", "ENCODING", key); !reflect.DeepEqual(got, Bulk(want)) {
+			t.Errorf("OBJECT ENCODING %s = %v, want %s", key, got, want)
+		}
+	}
+
+	execCases(t, e, []execCase{
+		{[]string{"OBJECT", "FREQ", "short"}, Integer(5)},
+		{[]string{"OBJECT", "IDLETIME", "short"}, Integer(0)},
+		{[]string{"OBJECT", "REFCOUNT", "short"}, Integer(1)},
+		{[]string{"OBJECT", "ENCODING", "missing"}, Null()},
+		{[]string{"OBJECT", "ENCODING"}, Error("ERR wrong number of arguments for 'object|encoding' command")},
+		{[]string{"OBJECT", "NOPE", "short"}, Error("ERR unknown subcommand 'NOPE'. Try OBJECT HELP.")},
+	})
+}
@@ -0,0 +1,390 @@
+package engine
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "LPUSH", Arity: -3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key element [element ...]",
+			Handler: pushCommand,
+		},
+		&Command{
+			Name: "RPUSH", Arity: -3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key element [element ...]",
+			Handler: pushCommand,
+		},
+		&Command{
+			Name: "LPUSHX", Arity: -3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key element [element ...]",
+			Handler: pushCommand,
+		},
+		&Command{
+			Name: "RPUSHX", Arity: -3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key element [element ...]",
+			Handler: pushCommand,
+		},
+		&Command{
+			Name: "LPOP", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key [count]",
+			Handler: popCommand,
+		},
+		&Command{
+			Name: "RPOP", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key [count]",
+			Handler: popCommand,
+		},
+		&Command{
+			Name: "LLEN", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key",
+			Handler: llenCommand,
+		},
+		&Command{
+			Name: "LRANGE", Arity: 4, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key start stop",
+			Handler: lrangeCommand,
+		},
+		&Command{
+			Name: "LINDEX", Arity: 3, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key index",
+			Handler: lindexCommand,
+		},
+		&Command{
+			Name: "LSET", Arity: 4, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key index element",
+			Handler: lsetCommand,
+		},
+		&Command{
+			Name: "LREM", Arity: 4, Flags: FlagWrite,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key count element",
+			Handler: lremCommand,
+		},
+		&Command{
+			Name: "LTRIM", Arity: 4, Flags: FlagWrite,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key start stop",
+			Handler: ltrimCommand,
+		},
+		&Command{
+			Name: "LINSERT", Arity: 5, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "list", Syntax: "key BEFORE|AFTER pivot element",
+			Handler: linsertCommand,
+		},
+		&Command{
+			Name: "LMOVE", Arity: 5, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 2, Step: 1,
+			Category: "list", Syntax: "source destination LEFT|RIGHT LEFT|RIGHT",
+			Handler: lmoveCommand,
+		},
+		&Command{
+			Name: "BLPOP", Arity: -3, Flags: FlagWrite | FlagBlocking,
+			FirstKey: 1, LastKey: -2, Step: 1,
+			Category: "list", Syntax: "key [key ...] timeout",
+			Handler: bpopCommand,
+		},
+		&Command{
+			Name: "BRPOP", Arity: -3, Flags: FlagWrite | FlagBlocking,
+			FirstKey: 1, LastKey: -2, Step: 1,
+			Category: "list", Syntax: "key [key ...] timeout",
+			Handler: bpopCommand,
+		},
+		&Command{
+			Name: "BLMOVE", Arity: 6, Flags: FlagWrite | FlagDenyOOM | FlagBlocking,
+			FirstKey: 1, LastKey: 2, Step: 1,
+			Category: "list", Syntax: "source destination LEFT|RIGHT LEFT|RIGHT timeout",
+			Handler: blmoveCommand,
+		},
+	)
+}
+
+// parseTimeout membaca timeout blocking dalam detik (boleh pecahan).
+func parseTimeout(arg string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(secs) || math.IsInf(secs, 0) {
+		return 0, errTimeoutNotFloat
+	}
+	if secs < 0 {
+		return 0, errTimeoutNegative
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func parseSide(arg string) (left bool, ok bool) {
+	switch strings.ToUpper(arg) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
+
+func sideName(left bool) string {
+	if left {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
+func pushCommand(ctx *Context, args []string) Reply {
+	name := strings.ToUpper(args[0])
+	left := name[0] == 'L'
+	onlyExisting := strings.HasSuffix(name, "X")
+
+	n, err := ctx.store.Push(ctx.DB, args[1], args[2:], left, onlyExisting)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if n == 0 {
+		ctx.SkipPropagate()
+	} else {
+		ctx.engine.waits.signal(ctx.DB, args[1])
+	}
+	return Integer(int64(n))
+}
+
+func popCommand(ctx *Context, args []string) Reply {
+	left := strings.EqualFold(args[0], "LPOP")
+
+	if len(args) > 3 {
+		return wrongArgs(args[0])
+	}
+
+	count := 1
+	if len(args) == 3 {
+		n, err := strconv.Atoi(args[2])
+		if err != nil || n < 0 {
+			return Error("ERR value is out of range, must be positive")
+		}
+		count = n
+	}
+
+	values, err := ctx.store.Pop(ctx.DB, args[1], left, count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if len(values) == 0 {
+		ctx.SkipPropagate()
+	} else {
+		ctx.Propagate(strings.ToUpper(args[0]), args[1], strconv.Itoa(len(values)))
+	}
+
+	// tanpa count: bulk tunggal, dengan count: array
+	if len(args) == 2 {
+		if len(values) == 0 {
+			return Null()
+		}
+		return Bulk(values[0])
+	}
+	if values == nil {
+		return NullArray()
+	}
+	return BulkArray(values)
+}
+
+func llenCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.LLen(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func lrangeCommand(ctx *Context, args []string) Reply {
+	start, err1 := strconv.Atoi(args[2])
+	stop, err2 := strconv.Atoi(args[3])
+	if err1 != nil || err2 != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	values, err := ctx.store.LRange(ctx.DB, args[1], start, stop)
+	if err != nil {
+		return errorReply(err)
+	}
+	return BulkArray(values)
+}
+
+func lindexCommand(ctx *Context, args []string) Reply {
+	index, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	val, ok, err := ctx.store.LIndex(ctx.DB, args[1], index)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return Null()
+	}
+	return Bulk(val)
+}
+
+func lsetCommand(ctx *Context, args []string) Reply {
+	index, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	if err := ctx.store.LSet(ctx.DB, args[1], index, args[3]); err != nil {
+		return errorReply(err)
+	}
+	return OK()
+}
+
+func lremCommand(ctx *Context, args []string) Reply {
+	count, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	n, err := ctx.store.LRem(ctx.DB, args[1], count, args[3])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func ltrimCommand(ctx *Context, args []string) Reply {
+	start, err1 := strconv.Atoi(args[2])
+	stop, err2 := strconv.Atoi(args[3])
+	if err1 != nil || err2 != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	if err := ctx.store.LTrim(ctx.DB, args[1], start, stop); err != nil {
+		return errorReply(err)
+	}
+	return OK()
+}
+
+func linsertCommand(ctx *Context, args []string) Reply {
+	var before bool
+	switch strings.ToUpper(args[2]) {
+	case "BEFORE":
+		before = true
+	case "AFTER":
+	default:
+		return errorReply(errSyntax)
+	}
+
+	n, err := ctx.store.LInsert(ctx.DB, args[1], before, args[3], args[4])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n <= 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+// moveOnce menjalankan satu LMOVE dan mengembalikan elemen yang dipindah.
+func moveOnce(ctx *Context, src, dst string, fromLeft, toLeft bool) (Reply, bool) {
+	v, ok, err := ctx.store.LMove(ctx.DB, src, dst, fromLeft, toLeft)
+	if err != nil {
+		return errorReply(err), true
+	}
+	if !ok {
+		return Null(), false
+	}
+
+	ctx.Propagate("LMOVE", src, dst, sideName(fromLeft), sideName(toLeft))
+	ctx.engine.waits.signal(ctx.DB, dst)
+	return Bulk(v), true
+}
+
+func lmoveCommand(ctx *Context, args []string) Reply {
+	fromLeft, ok1 := parseSide(args[3])
+	toLeft, ok2 := parseSide(args[4])
+	if !ok1 || !ok2 {
+		return errorReply(errSyntax)
+	}
+
+	r, ok := moveOnce(ctx, args[1], args[2], fromLeft, toLeft)
+	if !ok {
+		ctx.SkipPropagate()
+	}
+	return r
+}
+
+func blmoveCommand(ctx *Context, args []string) Reply {
+	fromLeft, ok1 := parseSide(args[3])
+	toLeft, ok2 := parseSide(args[4])
+	if !ok1 || !ok2 {
+		return errorReply(errSyntax)
+	}
+
+	timeout, err := parseTimeout(args[5])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	r := ctx.block(args[1:2], timeout, func() (Reply, bool) {
+		return moveOnce(ctx, args[1], args[2], fromLeft, toLeft)
+	})
+
+	if r.Kind == ReplyNullArray {
+		// BLMOVE membalas null bulk saat timeout, bukan null array
+		ctx.SkipPropagate()
+		return Null()
+	}
+	return r
+}
+
+func bpopCommand(ctx *Context, args []string) Reply {
+	left := strings.EqualFold(args[0], "BLPOP")
+	keys := args[1 : len(args)-1]
+
+	timeout, err := parseTimeout(args[len(args)-1])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	name := "RPOP"
+	if left {
+		name = "LPOP"
+	}
+
+	r := ctx.block(keys, timeout, func() (Reply, bool) {
+		for _, key := range keys {
+			values, err := ctx.store.Pop(ctx.DB, key, left, 1)
+			if err != nil {
+				return errorReply(err), true
+			}
+			if len(values) > 0 {
+				ctx.Propagate(name, key)
+				return Array(Bulk(key), Bulk(values[0])), true
+			}
+		}
+		return Reply{}, false
+	})
+
+	if r.Kind == ReplyNullArray {
+		ctx.SkipPropagate()
+	}
+	return r
+}
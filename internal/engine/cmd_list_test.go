@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPopCount(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	cases := []struct {
+		args []string
+		want Reply
+	}{
+		{[]string{"LPOP", "l"}, Bulk("a")},
+		{[]string{"LPOP", "l", "0"}, Array()},
+		{[]string{"RPOP", "l", "0"}, Array()},
+		{[]string{"LPOP", "l", "2"}, BulkArray([]string{"b", "c"})},
+		{[]string{"RPOP", "l", "5"}, BulkArray([]string{"e", "d"})},
+		{[]string{"LPOP", "l"}, Null()},
+		{[]string{"LPOP", "l", "0"}, NullArray()},
+		{[]string{"RPOP", "l", "2"}, NullArray()},
+		{[]string{"LPOP", "l", "-1"}, Error("ERR value is out of range, must be positive")},
+	}
+
+	mustExec(t, e, "RPUSH", "l", "a", "b", "c", "d", "e")
+	for _, tc := range cases {
+		if got := e.Execute(0, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v = %#v, want %#v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestBlockingPopTimeout(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	cases := []execCase{
+		{[]string{"BLPOP", "a", "b", "0.1"}, NullArray()},
+		{[]string{"BRPOP", "a", "0.1"}, NullArray()},
+		{[]string{"BLMOVE", "a", "b", "LEFT", "RIGHT", "0.1"}, Null()},
+		{[]string{"BZPOPMIN", "z", "0.1"}, NullArray()},
+	}
+	for _, tc := range cases {
+		start := time.Now()
+		got := e.Execute(0, tc.args)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v = %v, want %v", tc.args, got, tc.want)
+		}
+		if d := time.Since(start); d < 100*time.Millisecond || d > 2*time.Second {
+			t.Errorf("%v returned after %v, want about 100ms", tc.args, d)
+		}
+	}
+
+	execCases(t, e, []execCase{
+		{[]string{"BLPOP", "a", "-1"}, Error("ERR timeout is negative")},
+		{[]string{"BLPOP", "a", "x"}, Error("ERR timeout is not a float or out of range")},
+	})
+}
+
+func TestBlockingPopReady(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	// key pertama yang tidak kosong dipakai, tanpa menunggu
+	mustExec(t, e, "RPUSH", "b", "1", "2")
+	mustExec(t, e, "RPUSH", "c", "3")
+	execCases(t, e, []execCase{
+		{[]string{"BLPOP", "a", "b", "c", "0"}, BulkArray([]string{"b", "1"})},
+		{[]string{"BRPOP", "a", "c", "b", "0"}, BulkArray([]string{"c", "3"})},
+		{[]string{"BLMOVE", "b", "d", "LEFT", "LEFT", "0"}, Bulk("2")},
+		{[]string{"EXISTS", "b", "c"}, Integer(0)},
+		{[]string{"LRANGE", "d", "0", "-1"}, BulkArray([]string{"2"})},
+	})
+}
+
+func TestBlockingPopWakeup(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	done := make(chan Reply, 2)
+	go func() { done <- e.Execute(0, []string{"BLPOP", "q", "5"}) }()
+	go func() { done <- e.Execute(0, []string{"BLPOP", "q", "5"}) }()
+	time.Sleep(50 * time.Millisecond)
+
+	// setiap elemen hanya diterima satu client
+	mustExec(t, e, "RPUSH", "q", "x", "y")
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-done:
+			if len(r.Elems) != 2 || r.Elems[0].Str != "q" {
+				t.Fatalf("BLPOP = %v", r)
+			}
+			got[r.Elems[1].Str] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("BLPOP was not woken by RPUSH")
+		}
+	}
+	if !got["x"] || !got["y"] {
+		t.Fatalf("BLPOP received %v, want x and y", got)
+	}
+}
+
+func TestBlockingPopCanceled(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	c, cancel := context.WithCancel(context.Background())
+	done := make(chan Reply, 1)
+	go func() { done <- e.ExecuteContext(c, 0, []string{"BLPOP", "q", "0"}) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case r := <-done:
+		if !reflect.DeepEqual(r, NullArray()) {
+			t.Fatalf("BLPOP = %v, want null array", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("BLPOP did not return after the context was canceled")
+	}
+
+	// client yang sudah pergi tidak boleh mengambil elemen
+	mustExec(t, e, "RPUSH", "q", "x")
+	if got := mustExec(t, e, "LLEN", "q"); !reflect.DeepEqual(got, Integer(1)) {
+		t.Fatalf("LLEN q = %v, want 1", got)
+	}
+}
@@ -0,0 +1,32 @@
+package engine
+
+// Pub/sub ditangani server karena hub dan mode subscribe melekat pada
+// koneksi; di sini hanya metadata untuk COMMAND, HELP dan ACL.
+func init() {
+	registerCommands(
+		&Command{
+			Name: "SUBSCRIBE", Arity: -2, Flags: FlagConnection,
+			Category: "pubsub", Syntax: "channel [channel ...]",
+		},
+		&Command{
+			Name: "UNSUBSCRIBE", Arity: -1, Flags: FlagConnection,
+			Category: "pubsub", Syntax: "[channel ...]",
+		},
+		&Command{
+			Name: "PSUBSCRIBE", Arity: -2, Flags: FlagConnection,
+			Category: "pubsub", Syntax: "pattern [pattern ...]",
+		},
+		&Command{
+			Name: "PUNSUBSCRIBE", Arity: -1, Flags: FlagConnection,
+			Category: "pubsub", Syntax: "[pattern ...]",
+		},
+		&Command{
+			Name: "PUBLISH", Arity: 3, Flags: FlagConnection | FlagFast,
+			Category: "pubsub", Syntax: "channel message",
+		},
+		&Command{
+			Name: "PUBSUB", Arity: -2, Flags: FlagConnection,
+			Category: "pubsub", Syntax: "CHANNELS [pattern]|NUMSUB [channel ...]|NUMPAT",
+		},
+	)
+}
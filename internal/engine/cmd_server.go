@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "PING", Arity: -1, Flags: FlagNoAuth | FlagFast,
+			Category: "connection", Syntax: "[message]",
+			Handler: pingCommand,
+		},
+		&Command{
+			Name: "ECHO", Arity: 2, Flags: FlagNoAuth | FlagFast,
+			Category: "connection", Syntax: "message",
+			Handler: echoCommand,
+		},
+		&Command{
+			Name: "COMMAND", Arity: -1, Flags: FlagNoAuth,
+			Category: "connection", Syntax: "[COUNT|LIST|INFO command...|DOCS]",
+			Handler: commandCommand,
+		},
+		&Command{
+			Name: "HELP", Arity: 1, Flags: FlagNoAuth,
+			Category: "connection",
+			Handler:  helpCommand,
+		},
+		&Command{
+			Name: "INFO", Arity: -1, Flags: FlagNoAuth,
+			Category: "server", Syntax: "[section]",
+			Handler: infoCommand,
+		},
+		&Command{
+			Name: "BGREWRITEAOF", Arity: 1, Flags: FlagAdmin,
+			Category: "server",
+			Handler:  bgRewriteAOFCommand,
+		},
+		&Command{
+			Name: "MEMORY", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 2, LastKey: 2, Step: 1,
+			Category: "server", Syntax: "USAGE key [SAMPLES count]|STATS",
+			Handler: memoryCommand,
+		},
+	)
+}
+
+func pingCommand(ctx *Context, args []string) Reply {
+	if len(args) > 2 {
+		return wrongArgs("PING")
+	}
+	if len(args) == 2 {
+		return Bulk(args[1])
+	}
+	return Simple("PONG")
+}
+
+func echoCommand(ctx *Context, args []string) Reply {
+	return Bulk(args[1])
+}
+
+func commandCommand(ctx *Context, args []string) Reply {
+	if len(args) == 1 {
+		var out []Reply
+		for _, c := range Commands() {
+			out = append(out, commandInfo(c))
+		}
+		return Array(out...)
+	}
+
+	switch strings.ToUpper(args[1]) {
+	case "COUNT":
+		return Integer(int64(len(commandTable)))
+
+	case "LIST":
+		var names []string
+		for _, c := range Commands() {
+			names = append(names, strings.ToLower(c.Name))
+		}
+		return BulkArray(names)
+
+	case "INFO":
+		var out []Reply
+		for _, name := range args[2:] {
+			c, ok := LookupCommand(name)
+			if !ok {
+				out = append(out, Null())
+				continue
+			}
+			out = append(out, commandInfo(c))
+		}
+		return Array(out...)
+
+	case "DOCS":
+		return Map()
+
+	default:
+		return Errorf("ERR unknown subcommand '%s'", args[1])
+	}
+}
+
+func commandInfo(c *Command) Reply {
+	var flags []Reply
+	for _, f := range c.FlagNames() {
+		flags = append(flags, Simple(f))
+	}
+
+	var cats []Reply
+	for _, cat := range c.ACLCategories() {
+		cats = append(cats, Simple("@"+cat))
+	}
+
+	return Array(
+		Bulk(strings.ToLower(c.Name)),
+		Integer(int64(c.Arity)),
+		Array(flags...),
+		Integer(int64(c.FirstKey)),
+		Integer(int64(c.LastKey)),
+		Integer(int64(c.Step)),
+		Array(cats...),
+	)
+}
+
+func helpCommand(ctx *Context, args []string) Reply {
+	var lines []Reply
+	for _, c := range Commands() {
+		line := c.Name
+		if c.Syntax != "" {
+			line += " " + c.Syntax
+		}
+		lines = append(lines, Simple(line))
+	}
+	return Array(lines...)
+}
+
+func infoCommand(ctx *Context, args []string) Reply {
+	return Bulk(ctx.engine.info(ctx.store))
+}
+
+func bgRewriteAOFCommand(ctx *Context, args []string) Reply {
+	if err := ctx.engine.BackgroundRewriteAOF(); err != nil {
+		return errorReply(err)
+	}
+	return Simple("Background append only file rewriting started")
+}
+
+func memoryCommand(ctx *Context, args []string) Reply {
+	sub := strings.ToUpper(args[1])
+
+	switch {
+	case sub == "HELP" && len(args) == 2:
+		return BulkArray([]string{
+			"MEMORY <subcommand> [<arg> [value] [opt] ...]. Subcommands are:",
+			"STATS",
+			"    Return information about the memory usage of the server.",
+			"USAGE <key> [SAMPLES <count>]",
+			"    Return memory in bytes used by <key> and its value. Nested values are",
+			"    sampled up to <count> times (default: 5, 0 means sample all).",
+		})
+
+	case sub == "USAGE" && len(args) >= 3:
+		return memoryUsage(ctx, args)
+
+	case sub == "STATS" && len(args) == 2:
+		return memoryStats(ctx.store)
+
+	case sub == "USAGE" || sub == "STATS":
+		return Errorf("ERR wrong number of arguments for 'memory|%s' command", strings.ToLower(sub))
+	}
+	return Errorf("ERR unknown subcommand '%s'. Try MEMORY HELP.", args[1])
+}
+
+// memoryUsage menangani MEMORY USAGE key [SAMPLES count].
+func memoryUsage(ctx *Context, args []string) Reply {
+	samples := 5
+	for i := 3; i < len(args); i++ {
+		if !strings.EqualFold(args[i], "SAMPLES") || i+1 >= len(args) {
+			return errorReply(errSyntax)
+		}
+
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 0 {
+			return errorReply(storage.ErrNotInteger)
+		}
+		samples = n
+		i++
+	}
+
+	// SAMPLES 0 berarti seluruh elemen dihitung
+	if samples == 0 {
+		samples = -1
+	}
+
+	size, ok := ctx.store.MemoryUsage(ctx.DB, args[2], samples)
+	if !ok {
+		return Null()
+	}
+	return Integer(size)
+}
+
+// memoryStats meniru MEMORY STATS Redis. Angka allocator berasal dari
+// runtime Go, sedangkan dataset dari perkiraan memUsage store.
+func memoryStats(store *storage.MemoryStore) Reply {
+	var rt runtime.MemStats
+	runtime.ReadMemStats(&rt)
+
+	mem := store.MemoryStats()
+	keys := store.Size()
+
+	perKey, percent := int64(0), 0.0
+	if keys > 0 {
+		perKey = mem.Used / int64(keys)
+	}
+	if rt.HeapAlloc > 0 {
+		percent = float64(mem.Used) * 100 / float64(rt.HeapAlloc)
+	}
+
+	out := []Reply{
+		Bulk("peak.allocated"), Integer(int64(rt.HeapSys)),
+		Bulk("total.allocated"), Integer(int64(rt.HeapAlloc)),
+		Bulk("startup.allocated"), Integer(0),
+		Bulk("maxmemory"), Integer(mem.MaxMemory),
+		Bulk("maxmemory.policy"), Bulk(mem.Policy.String()),
+		Bulk("evicted.keys"), Integer(mem.EvictedKeys),
+	}
+	for db := range store.DBCount() {
+		stats := store.DBMemory(db)
+		if stats.Keys == 0 {
+			continue
+		}
+		out = append(out, Bulk(fmt.Sprintf("db.%d", db)), Map(
+			Bulk("keys"), Integer(int64(stats.Keys)),
+			Bulk("expires"), Integer(int64(stats.Expires)),
+			Bulk("dataset.bytes"), Integer(stats.Bytes),
+		))
+	}
+	out = append(out,
+		Bulk("keys.count"), Integer(int64(keys)),
+		Bulk("keys.bytes-per-key"), Integer(perKey),
+		Bulk("dataset.bytes"), Integer(mem.Used),
+		Bulk("dataset.percentage"), Bulk(strconv.FormatFloat(percent, 'f', 2, 64)),
+	)
+	return Map(out...)
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// mapField mengambil value field name dari reply Map datar.
+func mapField(r Reply, name string) (Reply, bool) {
+	for i := 0; i+1 < len(r.Elems); i += 2 {
+		if r.Elems[i].Str == name {
+			return r.Elems[i+1], true
+		}
+	}
+	return Reply{}, false
+}
+
+func TestMemoryUsage(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "s1", "v")
+	mustExec(t, e, "SET", "s2", string(make([]byte, 10000)))
+	small := mustExec(t, e, "MEMORY", "USAGE", "s1")
+	big := mustExec(t, e, "MEMORY", "USAGE", "s2")
+	if small.Int <= 0 || big.Int-small.Int != 9999 {
+		t.Fatalf("MEMORY USAGE small = %d, big = %d", small.Int, big.Int)
+	}
+
+	// koleksi disampling; SAMPLES 0 menghitung semua elemen
+	for i := 0; i < 100; i++ {
+		field := strconv.Itoa(i)
+		if i == 99 {
+			field = string(make([]byte, 5000))
+		}
+		mustExec(t, e, "HSET", "h", field, "v")
+	}
+	all := mustExec(t, e, "MEMORY", "USAGE", "h", "SAMPLES", "0")
+	if all.Int < 5000 {
+		t.Fatalf("MEMORY USAGE h SAMPLES 0 = %d, want the large field counted", all.Int)
+	}
+
+	execCases(t, e, []execCase{
+		{[]string{"MEMORY", "USAGE", "missing"}, Null()},
+		{[]string{"MEMORY", "USAGE", "h", "SAMPLES", "-1"}, Error("ERR value is not an integer or out of range")},
+		{[]string{"MEMORY", "USAGE", "h", "COUNT", "1"}, Error("ERR syntax error")},
+		{[]string{"MEMORY", "USAGE"}, Error("ERR wrong number of arguments for 'memory|usage' command")},
+	})
+}
+
+func TestMemoryStats(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "a", "v")
+	mustExec(t, e, "SET", "b", "v", "EX", "100")
+	e.Execute(2, []string{"SET", "c", "v"})
+
+	stats := mustExec(t, e, "MEMORY", "STATS")
+	if got, _ := mapField(stats, "keys.count"); !reflect.DeepEqual(got, Integer(3)) {
+		t.Fatalf("keys.count = %v, want 3", got)
+	}
+	if got, _ := mapField(stats, "maxmemory.policy"); !reflect.DeepEqual(got, Bulk("noeviction")) {
+		t.Fatalf("maxmemory.policy = %v", got)
+	}
+
+	db0, ok := mapField(stats, "db.0")
+	if !ok {
+		t.Fatal("MEMORY STATS has no db.0")
+	}
+	if got, _ := mapField(db0, "keys"); !reflect.DeepEqual(got, Integer(2)) {
+		t.Fatalf("db.0 keys = %v, want 2", got)
+	}
+	if got, _ := mapField(db0, "expires"); !reflect.DeepEqual(got, Integer(1)) {
+		t.Fatalf("db.0 expires = %v, want 1", got)
+	}
+	if _, ok := mapField(stats, "db.1"); ok {
+		t.Fatal("MEMORY STATS reports empty db.1")
+	}
+}
@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "SADD", Arity: -3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key member [member ...]",
+			Handler: saddCommand,
+		},
+		&Command{
+			Name: "SREM", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key member [member ...]",
+			Handler: sremCommand,
+		},
+		&Command{
+			Name: "SMEMBERS", Arity: 2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key",
+			Handler: smembersCommand,
+		},
+		&Command{
+			Name: "SISMEMBER", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key member",
+			Handler: sismemberCommand,
+		},
+		&Command{
+			Name: "SMISMEMBER", Arity: -3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key member [member ...]",
+			Handler: sismemberCommand,
+		},
+		&Command{
+			Name: "SCARD", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key",
+			Handler: scardCommand,
+		},
+		&Command{
+			Name: "SPOP", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key [count]",
+			Handler: spopCommand,
+		},
+		&Command{
+			Name: "SRANDMEMBER", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key [count]",
+			Handler: srandMemberCommand,
+		},
+		&Command{
+			Name: "SMOVE", Arity: 4, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 2, Step: 1,
+			Category: "set", Syntax: "source destination member",
+			Handler: smoveCommand,
+		},
+		&Command{
+			Name: "SINTER", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "key [key ...]",
+			Handler: setAlgebraCommand,
+		},
+		&Command{
+			Name: "SUNION", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "key [key ...]",
+			Handler: setAlgebraCommand,
+		},
+		&Command{
+			Name: "SDIFF", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "key [key ...]",
+			Handler: setAlgebraCommand,
+		},
+		&Command{
+			Name: "SINTERSTORE", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "destination key [key ...]",
+			Handler: setAlgebraStoreCommand,
+		},
+		&Command{
+			Name: "SUNIONSTORE", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "destination key [key ...]",
+			Handler: setAlgebraStoreCommand,
+		},
+		&Command{
+			Name: "SDIFFSTORE", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "set", Syntax: "destination key [key ...]",
+			Handler: setAlgebraStoreCommand,
+		},
+		&Command{
+			Name: "SSCAN", Arity: -3, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "set", Syntax: "key cursor [MATCH pattern] [COUNT count]",
+			Handler: sscanCommand,
+		},
+	)
+}
+
+func saddCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.SAdd(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func sremCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.SRem(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func smembersCommand(ctx *Context, args []string) Reply {
+	members, err := ctx.store.SMembers(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return BulkArray(members)
+}
+
+// sismemberCommand melayani SISMEMBER (integer) dan SMISMEMBER (array).
+func sismemberCommand(ctx *Context, args []string) Reply {
+	found, err := ctx.store.SIsMember(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	out := make([]Reply, len(found))
+	for i, ok := range found {
+		if ok {
+			out[i] = Integer(1)
+		} else {
+			out[i] = Integer(0)
+		}
+	}
+
+	if strings.EqualFold(args[0], "SISMEMBER") {
+		return out[0]
+	}
+	return Array(out...)
+}
+
+func scardCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.SCard(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func spopCommand(ctx *Context, args []string) Reply {
+	if len(args) > 3 {
+		return errorReply(errSyntax)
+	}
+
+	count := 1
+	if len(args) == 3 {
+		n, err := strconv.Atoi(args[2])
+		if err != nil || n < 0 {
+			return Error("ERR value is out of range, must be positive")
+		}
+		count = n
+	}
+
+	members, err := ctx.store.SPop(ctx.DB, args[1], count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	// member dipilih acak, jadi AOF mencatat member yang benar-benar dihapus
+	if len(members) == 0 {
+		ctx.SkipPropagate()
+	} else {
+		ctx.Propagate(append([]string{"SREM", args[1]}, members...)...)
+	}
+
+	if len(args) == 2 {
+		if len(members) == 0 {
+			return Null()
+		}
+		return Bulk(members[0])
+	}
+	return BulkArray(members)
+}
+
+func srandMemberCommand(ctx *Context, args []string) Reply {
+	if len(args) > 3 {
+		return errorReply(errSyntax)
+	}
+
+	count := 1
+	if len(args) == 3 {
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			return errorReply(storage.ErrNotInteger)
+		}
+		count = n
+	}
+
+	members, err := ctx.store.SRandMember(ctx.DB, args[1], count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if len(args) == 2 {
+		if len(members) == 0 {
+			return Null()
+		}
+		return Bulk(members[0])
+	}
+	return BulkArray(members)
+}
+
+func smoveCommand(ctx *Context, args []string) Reply {
+	moved, err := ctx.store.SMove(ctx.DB, args[1], args[2], args[3])
+	if err != nil {
+		return errorReply(err)
+	}
+	if !moved {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+	return Integer(1)
+}
+
+func setOpFromName(name string) storage.SetOp {
+	switch {
+	case strings.HasPrefix(name, "SINTER"):
+		return storage.SetInter
+	case strings.HasPrefix(name, "SUNION"):
+		return storage.SetUnion
+	default:
+		return storage.SetDiff
+	}
+}
+
+func setAlgebraCommand(ctx *Context, args []string) Reply {
+	op := setOpFromName(strings.ToUpper(args[0]))
+
+	members, err := ctx.store.SetAlgebra(ctx.DB, op, args[1:])
+	if err != nil {
+		return errorReply(err)
+	}
+	return BulkArray(members)
+}
+
+func setAlgebraStoreCommand(ctx *Context, args []string) Reply {
+	op := setOpFromName(strings.ToUpper(args[0]))
+
+	n, err := ctx.store.SetAlgebraStore(ctx.DB, op, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func sscanCommand(ctx *Context, args []string) Reply {
+	opts, err := parseScanArgs(args, 2)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	next, members, err := ctx.store.SScan(ctx.DB, args[1], opts.cursor, opts.count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var items []Reply
+	for _, mem := range members {
+		if opts.matches(mem) {
+			items = append(items, Bulk(mem))
+		}
+	}
+	return scanReply(next, items)
+}
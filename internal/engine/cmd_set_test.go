@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// members mengembalikan isi reply array terurut, untuk command yang
+// urutan hasilnya tidak ditentukan.
+func members(r Reply) []string {
+	out := make([]string, 0, len(r.Elems))
+	for _, el := range r.Elems {
+		out = append(out, el.Str)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestSetCommands(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	execCases(t, e, []execCase{
+		{[]string{"SADD", "s", "a", "b", "a"}, Integer(2)},
+		{[]string{"SADD", "s", "c"}, Integer(1)},
+		{[]string{"SCARD", "s"}, Integer(3)},
+		{[]string{"SISMEMBER", "s", "a"}, Integer(1)},
+		{[]string{"SMISMEMBER", "s", "a", "x"}, Array(Integer(1), Integer(0))},
+		{[]string{"SREM", "s", "c", "x"}, Integer(1)},
+		{[]string{"SMOVE", "s", "t", "a"}, Integer(1)},
+		{[]string{"SMOVE", "s", "t", "a"}, Integer(0)},
+		{[]string{"SMOVE", "s", "t", "b"}, Integer(1)},
+		{[]string{"EXISTS", "s"}, Integer(0)},
+		{[]string{"SPOP", "missing"}, Null()},
+		{[]string{"SRANDMEMBER", "missing", "2"}, Array()},
+		{[]string{"SET", "str", "v"}, OK()},
+		{[]string{"SADD", "str", "a"}, Error("WRONGTYPE Operation against a key holding the wrong kind of value")},
+	})
+
+	if got := members(mustExec(t, e, "SMEMBERS", "t")); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("SMEMBERS t = %v", got)
+	}
+
+	// SPOP dengan count menghapus member yang dikembalikan
+	popped := members(mustExec(t, e, "SPOP", "t", "5"))
+	if !reflect.DeepEqual(popped, []string{"a", "b"}) {
+		t.Fatalf("SPOP t 5 = %v", popped)
+	}
+	if got := mustExec(t, e, "EXISTS", "t"); !reflect.DeepEqual(got, Integer(0)) {
+		t.Fatalf("EXISTS t after SPOP = %v", got)
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SADD", "a", "1", "2", "3", "4")
+	mustExec(t, e, "SADD", "b", "3", "4", "5")
+	mustExec(t, e, "SADD", "c", "4", "6")
+
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"SINTER", "a", "b", "c"}, []string{"4"}},
+		{[]string{"SINTER", "a", "missing"}, []string{}},
+		{[]string{"SUNION", "b", "c", "missing"}, []string{"3", "4", "5", "6"}},
+		{[]string{"SDIFF", "a", "b", "c"}, []string{"1", "2"}},
+		{[]string{"SDIFF", "missing", "a"}, []string{}},
+	}
+	for _, tc := range cases {
+		if got := members(mustExec(t, e, tc.args...)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	// *STORE menimpa tujuan dan menghapusnya bila hasilnya kosong
+	mustExec(t, e, "SET", "dst", "v")
+	execCases(t, e, []execCase{
+		{[]string{"SUNIONSTORE", "dst", "a", "c"}, Integer(5)},
+		{[]string{"SINTERSTORE", "dst", "dst", "b"}, Integer(2)},
+		{[]string{"SDIFFSTORE", "dst", "dst", "a"}, Integer(0)},
+		{[]string{"EXISTS", "dst"}, Integer(0)},
+		{[]string{"SINTER", "a", "str"}, Array()},
+	})
+	mustExec(t, e, "SET", "str", "v")
+	if got := e.Execute(0, []string{"SUNION", "a", "str"}); !got.IsError() {
+		t.Fatalf("SUNION with a string key = %v, want WRONGTYPE", got)
+	}
+}
+
+func TestSetScan(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SADD", "s", "a1", "a2", "b1")
+	execCases(t, e, []execCase{
+		{[]string{"SSCAN", "s", "0", "MATCH", "b*", "COUNT", "100"}, Array(Bulk("0"), BulkArray([]string{"b1"}))},
+		{[]string{"SSCAN", "missing", "0"}, Array(Bulk("0"), Array())},
+	})
+}
@@ -0,0 +1,1074 @@
+package engine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"ferrodb/internal/storage"
+)
+
+var errXGroupNoKey = errors.New("ERR The XGROUP subcommand requires the key to exist. " +
+	"Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "XADD", Arity: -5, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...]",
+			Handler: xaddCommand,
+		},
+		&Command{
+			Name: "XTRIM", Arity: -4, Flags: FlagWrite,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key MAXLEN|MINID [=|~] threshold [LIMIT count]",
+			Handler: xtrimCommand,
+		},
+		&Command{
+			Name: "XDEL", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key id [id ...]",
+			Handler: xdelCommand,
+		},
+		&Command{
+			Name: "XLEN", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key",
+			Handler: xlenCommand,
+		},
+		&Command{
+			Name: "XRANGE", Arity: -4, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key start end [COUNT count]",
+			Handler: xrangeCommand,
+		},
+		&Command{
+			Name: "XREVRANGE", Arity: -4, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key end start [COUNT count]",
+			Handler: xrangeCommand,
+		},
+		&Command{
+			Name: "XSETID", Arity: -3, Flags: FlagWrite,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key last-id [ENTRIESADDED entries-added]",
+			Handler: xsetidCommand,
+		},
+		&Command{
+			Name: "XREAD", Arity: -4, Flags: FlagReadOnly | FlagBlocking,
+			FirstKey: 1, LastKey: 1, Step: 1, GetKeys: xreadKeys,
+			Category: "stream", Syntax: "[COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]",
+			Handler: xreadCommand,
+		},
+		&Command{
+			Name: "XREADGROUP", Arity: -7, Flags: FlagWrite | FlagBlocking,
+			FirstKey: 1, LastKey: 1, Step: 1, GetKeys: xreadKeys,
+			Category: "stream", Syntax: "GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]",
+			Handler: xreadgroupCommand,
+		},
+		&Command{
+			Name: "XGROUP", Arity: -2, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 2, LastKey: 2, Step: 1,
+			Category: "stream", Syntax: "CREATE|SETID|DESTROY|CREATECONSUMER|DELCONSUMER key group [arg ...]",
+			Handler: xgroupCommand,
+		},
+		&Command{
+			Name: "XACK", Arity: -4, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key group id [id ...]",
+			Handler: xackCommand,
+		},
+		&Command{
+			Name: "XPENDING", Arity: -3, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key group [[IDLE min-idle-time] start end count [consumer]]",
+			Handler: xpendingCommand,
+		},
+		&Command{
+			Name: "XCLAIM", Arity: -6, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-ms] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid]",
+			Handler: xclaimCommand,
+		},
+		&Command{
+			Name: "XAUTOCLAIM", Arity: -6, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "stream", Syntax: "key group consumer min-idle-time start [COUNT count] [JUSTID]",
+			Handler: xautoclaimCommand,
+		},
+		&Command{
+			Name: "XINFO", Arity: -2, Flags: FlagReadOnly,
+			FirstKey: 2, LastKey: 2, Step: 1,
+			Category: "stream", Syntax: "STREAM|GROUPS|CONSUMERS key [group]",
+			Handler: xinfoCommand,
+		},
+	)
+}
+
+func nowMs() int64 {
+	return time.Now().UnixMilli()
+}
+
+func entryReply(e storage.StreamEntry) Reply {
+	if e.Fields == nil {
+		// entry sudah dihapus dari stream tetapi masih ada di PEL
+		return Array(Bulk(e.ID.String()), NullArray())
+	}
+	return Array(Bulk(e.ID.String()), BulkArray(e.Fields))
+}
+
+func entriesReply(entries []storage.StreamEntry) Reply {
+	out := make([]Reply, len(entries))
+	for i, e := range entries {
+		out[i] = entryReply(e)
+	}
+	return Array(out...)
+}
+
+func idsReply(ids []storage.StreamID) Reply {
+	out := make([]Reply, len(ids))
+	for i, id := range ids {
+		out[i] = Bulk(id.String())
+	}
+	return Array(out...)
+}
+
+// streamError menerjemahkan ErrNoGroup menjadi pesan NOGROUP lengkap.
+func streamError(err error, key, group string) Reply {
+	if errors.Is(err, storage.ErrNoGroup) {
+		return Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+	return errorReply(err)
+}
+
+// parseRangeID membaca batas XRANGE/XPENDING: "-", "+", ID lengkap atau
+// sebagian, dan "(" untuk batas eksklusif.
+func parseRangeID(s string, end bool) (storage.StreamID, error) {
+	switch s {
+	case "-":
+		return storage.StreamID{}, nil
+	case "+":
+		return storage.MaxStreamID, nil
+	}
+
+	excl := strings.HasPrefix(s, "(")
+	if excl {
+		s = s[1:]
+	}
+
+	var missing uint64
+	if end {
+		missing = storage.MaxStreamID.Seq
+	}
+
+	id, err := storage.ParseStreamID(s, missing)
+	if err != nil || !excl {
+		return id, err
+	}
+
+	var ok bool
+	if end {
+		id, ok = id.Prev()
+	} else {
+		id, ok = id.Next()
+	}
+	if !ok {
+		if end {
+			return id, errors.New("ERR invalid end ID for the interval")
+		}
+		return id, errors.New("ERR invalid start ID for the interval")
+	}
+	return id, nil
+}
+
+func parseStreamIDs(args []string) ([]storage.StreamID, error) {
+	ids := make([]storage.StreamID, len(args))
+	for i, a := range args {
+		id, err := storage.ParseStreamID(a, 0)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// parseTrim membaca "MAXLEN|MINID [=|~] threshold [LIMIT count]" mulai
+// dari args[i] dan mengembalikan index argumen berikutnya.
+func parseTrim(args []string, i int) (*storage.StreamTrim, int, error) {
+	t := &storage.StreamTrim{MinID: strings.EqualFold(args[i], "MINID")}
+	i++
+
+	approx := false
+	if i < len(args) && (args[i] == "=" || args[i] == "~") {
+		approx = args[i] == "~"
+		i++
+	}
+	if i >= len(args) {
+		return nil, i, errSyntax
+	}
+
+	if t.MinID {
+		id, err := storage.ParseStreamID(args[i], 0)
+		if err != nil {
+			return nil, i, err
+		}
+		t.Threshold = id
+	} else {
+		n, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil {
+			return nil, i, storage.ErrNotInteger
+		}
+		if n < 0 {
+			return nil, i, errors.New("ERR The MAXLEN argument must be >= 0.")
+		}
+		t.MaxLen = n
+	}
+	i++
+
+	if i+1 < len(args) && strings.EqualFold(args[i], "LIMIT") {
+		if !approx {
+			return nil, i, errors.New("ERR syntax error, LIMIT cannot be used without the special ~ option")
+		}
+		n, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || n < 0 {
+			return nil, i, errors.New("ERR The LIMIT argument must be >= 0.")
+		}
+		t.Limit = n
+		i += 2
+	}
+	return t, i, nil
+}
+
+func xaddCommand(ctx *Context, args []string) Reply {
+	var opts storage.XAddOptions
+
+	i := 2
+loop:
+	for i < len(args) {
+		switch strings.ToUpper(args[i]) {
+		case "NOMKSTREAM":
+			opts.NoMkStream = true
+			i++
+		case "MAXLEN", "MINID":
+			t, next, err := parseTrim(args, i)
+			if err != nil {
+				return errorReply(err)
+			}
+			opts.Trim, i = t, next
+		default:
+			break loop
+		}
+	}
+
+	if i >= len(args) {
+		return errorReply(errSyntax)
+	}
+	fields := args[i+1:]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return wrongArgs(args[0])
+	}
+
+	switch idArg := args[i]; {
+	case idArg == "*":
+		opts.AutoID = true
+	case strings.HasSuffix(idArg, "-*"):
+		ms, err := strconv.ParseUint(strings.TrimSuffix(idArg, "-*"), 10, 64)
+		if err != nil {
+			return errorReply(storage.ErrStreamID)
+		}
+		opts.ID, opts.AutoSeq = storage.StreamID{Ms: ms}, true
+	default:
+		id, err := storage.ParseStreamID(idArg, 0)
+		if err != nil {
+			return errorReply(err)
+		}
+		opts.ID = id
+	}
+
+	id, ok, err := ctx.store.XAdd(ctx.DB, args[1], opts, fields, nowMs())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		ctx.SkipPropagate()
+		return Null()
+	}
+
+	// ID otomatis dicatat sebagai ID eksplisit agar replay menghasilkan
+	// entry yang sama
+	prop := append([]string(nil), args...)
+	prop[0], prop[i] = "XADD", id.String()
+	ctx.Propagate(prop...)
+
+	ctx.engine.waits.signal(ctx.DB, args[1])
+	return Bulk(id.String())
+}
+
+func xtrimCommand(ctx *Context, args []string) Reply {
+	if !strings.EqualFold(args[2], "MAXLEN") && !strings.EqualFold(args[2], "MINID") {
+		return errorReply(errSyntax)
+	}
+
+	t, next, err := parseTrim(args, 2)
+	if err != nil {
+		return errorReply(err)
+	}
+	if next != len(args) {
+		return errorReply(errSyntax)
+	}
+
+	n, err := ctx.store.XTrim(ctx.DB, args[1], *t)
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(n)
+}
+
+func xdelCommand(ctx *Context, args []string) Reply {
+	ids, err := parseStreamIDs(args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	n, err := ctx.store.XDel(ctx.DB, args[1], ids)
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func xlenCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.XLen(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func xrangeCommand(ctx *Context, args []string) Reply {
+	rev := strings.EqualFold(args[0], "XREVRANGE")
+
+	startArg, endArg := args[2], args[3]
+	if rev {
+		startArg, endArg = endArg, startArg
+	}
+
+	start, err := parseRangeID(startArg, false)
+	if err != nil {
+		return errorReply(err)
+	}
+	end, err := parseRangeID(endArg, true)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	count := 0
+	switch {
+	case len(args) == 6 && strings.EqualFold(args[4], "COUNT"):
+		n, err := strconv.Atoi(args[5])
+		if err != nil {
+			return errorReply(storage.ErrNotInteger)
+		}
+		if n <= 0 {
+			return Array()
+		}
+		count = n
+	case len(args) != 4:
+		return errorReply(errSyntax)
+	}
+
+	entries, err := ctx.store.XRange(ctx.DB, args[1], start, end, count, rev)
+	if err != nil {
+		return errorReply(err)
+	}
+	return entriesReply(entries)
+}
+
+func xsetidCommand(ctx *Context, args []string) Reply {
+	id, err := storage.ParseStreamID(args[2], 0)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	entriesAdded := int64(-1)
+	switch {
+	case len(args) == 5 && strings.EqualFold(args[3], "ENTRIESADDED"):
+		n, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil || n < 0 {
+			return Error("ERR entries_added must be positive")
+		}
+		entriesAdded = n
+	case len(args) != 3:
+		return errorReply(errSyntax)
+	}
+
+	if err := ctx.store.XSetID(ctx.DB, args[1], id, entriesAdded); err != nil {
+		return errorReply(err)
+	}
+	return OK()
+}
+
+// streamsIndex mengembalikan index argumen setelah STREAMS, -1 bila tidak ada.
+func streamsIndex(args []string) int {
+	for i := 1; i < len(args); i++ {
+		if strings.EqualFold(args[i], "STREAMS") {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func xreadKeys(args []string) []string {
+	i := streamsIndex(args)
+	if i < 0 {
+		return nil
+	}
+	rest := args[i:]
+	return rest[:len(rest)/2]
+}
+
+// streamReadArgs adalah opsi bersama XREAD dan XREADGROUP.
+type streamReadArgs struct {
+	count   int
+	block   bool
+	timeout time.Duration
+	noAck   bool
+	keys    []string
+	ids     []string
+}
+
+// parseStreamRead membaca [COUNT n] [BLOCK ms] [NOACK] STREAMS ... mulai
+// dari args[i]. NOACK hanya valid untuk XREADGROUP.
+func parseStreamRead(args []string, i int, group bool) (streamReadArgs, Reply, bool) {
+	var r streamReadArgs
+
+	for ; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); {
+		case opt == "COUNT" && i+1 < len(args):
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return r, errorReply(storage.ErrNotInteger), false
+			}
+			if n > 0 {
+				r.count = n
+			}
+			i++
+		case opt == "BLOCK" && i+1 < len(args):
+			ms, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return r, Error("ERR timeout is not an integer or out of range"), false
+			}
+			if ms < 0 {
+				return r, errorReply(errTimeoutNegative), false
+			}
+			r.block, r.timeout = true, time.Duration(ms)*time.Millisecond
+			i++
+		case opt == "NOACK" && group:
+			r.noAck = true
+		case opt == "STREAMS":
+			rest := args[i+1:]
+			if len(rest) == 0 || len(rest)%2 != 0 {
+				name, special := "xread", "$"
+				if group {
+					name, special = "xreadgroup", ">"
+				}
+				return r, Errorf("ERR Unbalanced '%s' list of streams: for each stream key an ID or '%s' must be specified.",
+					name, special), false
+			}
+			r.keys, r.ids = rest[:len(rest)/2], rest[len(rest)/2:]
+			return r, Reply{}, true
+		default:
+			return r, errorReply(errSyntax), false
+		}
+	}
+	return r, errorReply(errSyntax), false
+}
+
+func xreadCommand(ctx *Context, args []string) Reply {
+	r, errReply, ok := parseStreamRead(args, 1, false)
+	if !ok {
+		return errReply
+	}
+
+	// "$" diganti ID terakhir saat command diterima, bukan saat data datang
+	after := make([]storage.StreamID, len(r.keys))
+	for i, idArg := range r.ids {
+		if idArg == "$" {
+			last, err := ctx.store.XLastID(ctx.DB, r.keys[i])
+			if err != nil {
+				return errorReply(err)
+			}
+			after[i] = last
+			continue
+		}
+
+		id, err := storage.ParseStreamID(idArg, 0)
+		if err != nil {
+			return errorReply(err)
+		}
+		after[i] = id
+	}
+
+	try := func() (Reply, bool) {
+		var out []Reply
+		for i, key := range r.keys {
+			from, ok := after[i].Next()
+			if !ok {
+				continue
+			}
+
+			entries, err := ctx.store.XRange(ctx.DB, key, from, storage.MaxStreamID, r.count, false)
+			if err != nil {
+				return errorReply(err), true
+			}
+			if len(entries) > 0 {
+				out = append(out, Array(Bulk(key), entriesReply(entries)))
+			}
+		}
+		if out == nil {
+			return Reply{}, false
+		}
+		return Array(out...), true
+	}
+
+	if !r.block {
+		if reply, ok := try(); ok {
+			return reply
+		}
+		return NullArray()
+	}
+	return ctx.block(r.keys, r.timeout, try)
+}
+
+func xreadgroupCommand(ctx *Context, args []string) Reply {
+	if !strings.EqualFold(args[1], "GROUP") {
+		return errorReply(errSyntax)
+	}
+	group, consumer := args[2], args[3]
+
+	r, errReply, ok := parseStreamRead(args, 4, true)
+	if !ok {
+		return errReply
+	}
+
+	// hanya ">" yang bisa menunggu; pembacaan history selalu langsung kembali
+	newOnly := make([]bool, len(r.keys))
+	start := make([]storage.StreamID, len(r.keys))
+	history := false
+	for i, idArg := range r.ids {
+		if idArg == ">" {
+			newOnly[i] = true
+			continue
+		}
+
+		id, err := storage.ParseStreamID(idArg, 0)
+		if err != nil {
+			return errorReply(err)
+		}
+		start[i], history = id, true
+	}
+
+	try := func() (Reply, bool) {
+		var out []Reply
+		for i, key := range r.keys {
+			res, err := ctx.store.XReadGroup(ctx.DB, key, group, consumer,
+				start[i], newOnly[i], r.count, r.noAck, nowMs())
+			if err != nil {
+				if errors.Is(err, storage.ErrNoGroup) {
+					return Errorf("NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option",
+						key, group), true
+				}
+				return errorReply(err), true
+			}
+
+			propagateReadGroup(ctx, key, group, consumer, res)
+
+			if !newOnly[i] || len(res.Entries) > 0 {
+				out = append(out, Array(Bulk(key), entriesReply(res.Entries)))
+			}
+		}
+		if out == nil {
+			return Reply{}, false
+		}
+		return Array(out...), true
+	}
+
+	var reply Reply
+	if r.block && !history {
+		reply = ctx.block(r.keys, r.timeout, try)
+	} else if reply, ok = try(); !ok {
+		reply = NullArray()
+	}
+
+	if len(ctx.propagate) == 0 {
+		ctx.SkipPropagate()
+	}
+	return reply
+}
+
+// propagateReadGroup mencatat efek XREADGROUP ke AOF dalam bentuk yang
+// deterministik: XCLAIM FORCE untuk setiap entry yang masuk PEL lalu
+// XGROUP SETID untuk posisi terakhir group.
+func propagateReadGroup(ctx *Context, key, group, consumer string, res storage.XReadGroupResult) {
+	if res.ConsumerCreated {
+		ctx.Propagate("XGROUP", "CREATECONSUMER", key, group, consumer)
+	}
+
+	for _, pe := range res.Delivered {
+		propagateClaim(ctx, key, group, pe)
+	}
+
+	if !res.LastID.IsZero() {
+		ctx.Propagate("XGROUP", "SETID", key, group, res.LastID.String())
+	}
+}
+
+func propagateClaim(ctx *Context, key, group string, pe *storage.PendingEntry) {
+	ctx.Propagate("XCLAIM", key, group, pe.Consumer, "0", pe.ID.String(),
+		"TIME", strconv.FormatInt(pe.DeliveredAt, 10),
+		"RETRYCOUNT", strconv.FormatInt(pe.Deliveries, 10),
+		"FORCE", "JUSTID")
+}
+
+func xgroupCommand(ctx *Context, args []string) Reply {
+	sub := strings.ToUpper(args[1])
+
+	need := map[string]int{
+		"CREATE": 5, "SETID": 5, "DESTROY": 4,
+		"CREATECONSUMER": 5, "DELCONSUMER": 5,
+	}
+	n, ok := need[sub]
+	if !ok {
+		return Errorf("ERR unknown subcommand '%s'. Try XGROUP HELP.", args[1])
+	}
+	if len(args) < n {
+		return Errorf("ERR wrong number of arguments for 'xgroup|%s' command", strings.ToLower(sub))
+	}
+
+	key, group := args[2], args[3]
+	noGroup := func(err error) Reply {
+		if errors.Is(err, storage.ErrNoGroup) {
+			return Errorf("NOGROUP No such consumer group '%s' for key name '%s'", group, key)
+		}
+		if errors.Is(err, storage.ErrStreamNotFound) {
+			return errorReply(errXGroupNoKey)
+		}
+		return errorReply(err)
+	}
+
+	switch sub {
+	case "CREATE", "SETID":
+		mkStream := false
+		for i := 5; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "MKSTREAM":
+				if sub != "CREATE" {
+					return errorReply(errSyntax)
+				}
+				mkStream = true
+			case "ENTRIESREAD":
+				// diterima untuk kompatibilitas, lag tidak dihitung
+				i++
+			default:
+				return errorReply(errSyntax)
+			}
+		}
+
+		useLast := args[4] == "$"
+		var id storage.StreamID
+		if !useLast {
+			var err error
+			if id, err = storage.ParseStreamID(args[4], 0); err != nil {
+				return errorReply(err)
+			}
+		}
+
+		var err error
+		if sub == "CREATE" {
+			id, err = ctx.store.XGroupCreate(ctx.DB, key, group, id, useLast, mkStream)
+		} else {
+			id, err = ctx.store.XGroupSetID(ctx.DB, key, group, id, useLast)
+		}
+		if err != nil {
+			return noGroup(err)
+		}
+
+		prop := []string{"XGROUP", sub, key, group, id.String()}
+		if mkStream {
+			prop = append(prop, "MKSTREAM")
+		}
+		ctx.Propagate(prop...)
+		return OK()
+
+	case "DESTROY":
+		ok, err := ctx.store.XGroupDestroy(ctx.DB, key, group)
+		if err != nil {
+			return noGroup(err)
+		}
+		if !ok {
+			ctx.SkipPropagate()
+			return Integer(0)
+		}
+		return Integer(1)
+
+	case "CREATECONSUMER":
+		created, err := ctx.store.XGroupCreateConsumer(ctx.DB, key, group, args[4], nowMs())
+		if err != nil {
+			return noGroup(err)
+		}
+		if !created {
+			ctx.SkipPropagate()
+			return Integer(0)
+		}
+		return Integer(1)
+
+	default: // DELCONSUMER
+		pending, ok, err := ctx.store.XGroupDelConsumer(ctx.DB, key, group, args[4])
+		if err != nil {
+			return noGroup(err)
+		}
+		if !ok {
+			ctx.SkipPropagate()
+		}
+		return Integer(int64(pending))
+	}
+}
+
+func xackCommand(ctx *Context, args []string) Reply {
+	ids, err := parseStreamIDs(args[3:])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	n, err := ctx.store.XAck(ctx.DB, args[1], args[2], ids)
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func xpendingCommand(ctx *Context, args []string) Reply {
+	key, group := args[1], args[2]
+
+	if len(args) == 3 {
+		sum, err := ctx.store.XPendingSummary(ctx.DB, key, group)
+		if err != nil {
+			return streamError(err, key, group)
+		}
+		if sum.Count == 0 {
+			return Array(Integer(0), Null(), Null(), NullArray())
+		}
+
+		consumers := make([]Reply, len(sum.Consumers))
+		for i, c := range sum.Consumers {
+			consumers[i] = Array(Bulk(c.Name), Bulk(strconv.Itoa(c.Count)))
+		}
+		return Array(
+			Integer(int64(sum.Count)),
+			Bulk(sum.Min.String()),
+			Bulk(sum.Max.String()),
+			Array(consumers...),
+		)
+	}
+
+	rest := args[3:]
+	var minIdle int64
+	if strings.EqualFold(rest[0], "IDLE") {
+		if len(rest) < 2 {
+			return errorReply(errSyntax)
+		}
+		n, err := strconv.ParseInt(rest[1], 10, 64)
+		if err != nil {
+			return errorReply(storage.ErrNotInteger)
+		}
+		minIdle, rest = n, rest[2:]
+	}
+	if len(rest) != 3 && len(rest) != 4 {
+		return errorReply(errSyntax)
+	}
+
+	start, err := parseRangeID(rest[0], false)
+	if err != nil {
+		return errorReply(err)
+	}
+	end, err := parseRangeID(rest[1], true)
+	if err != nil {
+		return errorReply(err)
+	}
+	count, err := strconv.Atoi(rest[2])
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+	if count <= 0 {
+		return Array()
+	}
+
+	consumer := ""
+	if len(rest) == 4 {
+		consumer = rest[3]
+	}
+
+	now := nowMs()
+	pending, err := ctx.store.XPendingRange(ctx.DB, key, group, start, end, count, consumer, minIdle, now)
+	if err != nil {
+		return streamError(err, key, group)
+	}
+
+	out := make([]Reply, len(pending))
+	for i, pe := range pending {
+		out[i] = Array(
+			Bulk(pe.ID.String()),
+			Bulk(pe.Consumer),
+			Integer(now-pe.DeliveredAt),
+			Integer(pe.Deliveries),
+		)
+	}
+	return Array(out...)
+}
+
+// claimReply merender hasil XCLAIM/XAUTOCLAIM sebagai entry lengkap atau
+// hanya ID bila JUSTID.
+func claimReply(entries []storage.StreamEntry, justID bool) Reply {
+	if !justID {
+		return entriesReply(entries)
+	}
+	ids := make([]storage.StreamID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return idsReply(ids)
+}
+
+func propagateClaimResult(ctx *Context, key, group string, res storage.XClaimResult) {
+	for i := range res.Pending {
+		propagateClaim(ctx, key, group, &res.Pending[i])
+	}
+	if len(res.Deleted) > 0 {
+		prop := []string{"XACK", key, group}
+		for _, id := range res.Deleted {
+			prop = append(prop, id.String())
+		}
+		ctx.Propagate(prop...)
+	}
+}
+
+func parseMinIdle(arg string) (int64, error) {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, errors.New("ERR Invalid min-idle-time argument for XCLAIM")
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n, nil
+}
+
+func xclaimCommand(ctx *Context, args []string) Reply {
+	key, group, consumer := args[1], args[2], args[3]
+
+	minIdle, err := parseMinIdle(args[4])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	i := 5
+	var ids []storage.StreamID
+	for ; i < len(args); i++ {
+		id, err := storage.ParseStreamID(args[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return errorReply(storage.ErrStreamID)
+	}
+
+	now := nowMs()
+	opts := storage.XClaimOptions{DeliveredAt: -1, RetryCount: -1}
+
+	for ; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "FORCE":
+			opts.Force = true
+			continue
+		case "JUSTID":
+			opts.JustID = true
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return Errorf("ERR Unrecognized XCLAIM option '%s'", args[i])
+		}
+		val := args[i+1]
+		i++
+
+		switch opt {
+		case "IDLE", "TIME", "RETRYCOUNT":
+			n, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return Errorf("ERR Invalid %s option argument for XCLAIM", opt)
+			}
+			switch opt {
+			case "IDLE":
+				opts.DeliveredAt = now - n
+			case "TIME":
+				opts.DeliveredAt = n
+			default:
+				opts.RetryCount = n
+			}
+		case "LASTID":
+			id, err := storage.ParseStreamID(val, 0)
+			if err != nil {
+				return errorReply(err)
+			}
+			opts.LastID = &id
+		default:
+			return Errorf("ERR Unrecognized XCLAIM option '%s'", args[i-1])
+		}
+	}
+
+	res, err := ctx.store.XClaim(ctx.DB, key, group, consumer, minIdle, ids, opts, now)
+	if err != nil {
+		return streamError(err, key, group)
+	}
+
+	propagateClaimResult(ctx, key, group, res)
+	if opts.LastID != nil {
+		ctx.Propagate("XGROUP", "SETID", key, group, res.LastID.String())
+	}
+	if len(ctx.propagate) == 0 {
+		ctx.SkipPropagate()
+	}
+	return claimReply(res.Claimed, opts.JustID)
+}
+
+func xautoclaimCommand(ctx *Context, args []string) Reply {
+	key, group, consumer := args[1], args[2], args[3]
+
+	minIdle, err := parseMinIdle(args[4])
+	if err != nil {
+		return errorReply(err)
+	}
+	start, err := parseRangeID(args[5], false)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	count, justID := 100, false
+	for i := 6; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "JUSTID":
+			justID = true
+		case "COUNT":
+			if i+1 >= len(args) {
+				return errorReply(errSyntax)
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return errorReply(storage.ErrNotInteger)
+			}
+			if n < 1 {
+				return Error("ERR COUNT must be > 0")
+			}
+			count = n
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	res, err := ctx.store.XAutoClaim(ctx.DB, key, group, consumer, minIdle, start, count, justID, nowMs())
+	if err != nil {
+		return streamError(err, key, group)
+	}
+
+	propagateClaimResult(ctx, key, group, res)
+	if len(ctx.propagate) == 0 {
+		ctx.SkipPropagate()
+	}
+
+	return Array(
+		Bulk(res.Next.String()),
+		claimReply(res.Claimed, justID),
+		idsReply(res.Deleted),
+	)
+}
+
+func xinfoCommand(ctx *Context, args []string) Reply {
+	sub := strings.ToUpper(args[1])
+
+	switch {
+	case sub == "STREAM" && len(args) == 3:
+		info, err := ctx.store.XInfoStream(ctx.DB, args[2])
+		if err != nil {
+			return errorReply(err)
+		}
+
+		first, last := Null(), Null()
+		if info.First != nil {
+			first, last = entryReply(*info.First), entryReply(*info.Last)
+		}
+		return Map(
+			Bulk("length"), Integer(int64(info.Length)),
+			Bulk("last-generated-id"), Bulk(info.LastID.String()),
+			Bulk("entries-added"), Integer(info.EntriesAdded),
+			Bulk("groups"), Integer(int64(info.Groups)),
+			Bulk("first-entry"), first,
+			Bulk("last-entry"), last,
+		)
+
+	case sub == "GROUPS" && len(args) == 3:
+		groups, err := ctx.store.XInfoGroups(ctx.DB, args[2])
+		if err != nil {
+			return errorReply(err)
+		}
+
+		out := make([]Reply, len(groups))
+		for i, g := range groups {
+			out[i] = Map(
+				Bulk("name"), Bulk(g.Name),
+				Bulk("consumers"), Integer(int64(g.Consumers)),
+				Bulk("pending"), Integer(int64(g.Pending)),
+				Bulk("last-delivered-id"), Bulk(g.LastID.String()),
+			)
+		}
+		return Array(out...)
+
+	case sub == "CONSUMERS" && len(args) == 4:
+		consumers, err := ctx.store.XInfoConsumers(ctx.DB, args[2], args[3], nowMs())
+		if errors.Is(err, storage.ErrNoGroup) {
+			return Errorf("NOGROUP No such consumer group '%s' for key name '%s'", args[3], args[2])
+		}
+		if err != nil {
+			return errorReply(err)
+		}
+
+		out := make([]Reply, len(consumers))
+		for i, c := range consumers {
+			out[i] = Map(
+				Bulk("name"), Bulk(c.Name),
+				Bulk("pending"), Integer(int64(c.Pending)),
+				Bulk("idle"), Integer(c.Idle),
+			)
+		}
+		return Array(out...)
+
+	case sub == "STREAM" || sub == "GROUPS" || sub == "CONSUMERS":
+		return Errorf("ERR wrong number of arguments for 'xinfo|%s' command", strings.ToLower(sub))
+	}
+	return Errorf("ERR unknown subcommand '%s'. Try XINFO HELP.", args[1])
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreamCommands(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	entry := func(id string, fields ...string) Reply {
+		return Array(Bulk(id), BulkArray(fields))
+	}
+	execCases(t, e, []execCase{
+		{[]string{"XADD", "s", "1-1", "a", "1"}, Bulk("1-1")},
+		{[]string{"XADD", "s", "1-*", "b", "2"}, Bulk("1-2")},
+		{[]string{"XADD", "s", "1-2", "c", "3"}, Error("ERR The ID specified in XADD is equal or smaller than the target stream top item")},
+		{[]string{"XADD", "s", "0-0", "c", "3"}, Error("ERR The ID specified in XADD must be greater than 0-0")},
+		{[]string{"XADD", "s", "2-0", "c"}, Error("ERR wrong number of arguments for 'xadd' command")},
+		{[]string{"XADD", "s", "2-0", "c", "3"}, Bulk("2-0")},
+		{[]string{"XADD", "missing", "NOMKSTREAM", "*", "a", "1"}, Null()},
+		{[]string{"XLEN", "s"}, Integer(3)},
+
+		{[]string{"XRANGE", "s", "-", "+"}, Array(entry("1-1", "a", "1"), entry("1-2", "b", "2"), entry("2-0", "c", "3"))},
+		{[]string{"XRANGE", "s", "(1-1", "1"}, Array(entry("1-2", "b", "2"))},
+		{[]string{"XREVRANGE", "s", "+", "-", "COUNT", "1"}, Array(entry("2-0", "c", "3"))},
+		{[]string{"XRANGE", "s", "-", "+", "COUNT", "0"}, Array()},
+
+		{[]string{"XDEL", "s", "1-2", "9-9"}, Integer(1)},
+		{[]string{"XTRIM", "s", "MAXLEN", "1"}, Integer(1)},
+		{[]string{"XRANGE", "s", "-", "+"}, Array(entry("2-0", "c", "3"))},
+		{[]string{"XTRIM", "s", "MAXLEN", "1", "LIMIT", "5"}, Error("ERR syntax error, LIMIT cannot be used without the special ~ option")},
+		{[]string{"XADD", "s", "1-5", "d", "4"}, Error("ERR The ID specified in XADD is equal or smaller than the target stream top item")},
+
+		{[]string{"SET", "str", "v"}, OK()},
+		{[]string{"XADD", "str", "*", "a", "1"}, Error("WRONGTYPE Operation against a key holding the wrong kind of value")},
+	})
+}
+
+func TestStreamGroups(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "no")
+	e := New(cfg)
+
+	mustExec(t, e, "XADD", "s", "1-0", "a", "1")
+	mustExec(t, e, "XADD", "s", "2-0", "b", "2")
+
+	entry := func(id string, fields ...string) Reply {
+		return Array(Bulk(id), BulkArray(fields))
+	}
+	execCases(t, e, []execCase{
+		{[]string{"XGROUP", "CREATE", "s", "g", "0"}, OK()},
+		{[]string{"XGROUP", "CREATE", "s", "g", "0"}, Error("BUSYGROUP Consumer Group name already exists")},
+		{[]string{"XGROUP", "CREATE", "missing", "g", "$"}, errorReply(errXGroupNoKey)},
+		{[]string{"XREADGROUP", "GROUP", "g", "alice", "COUNT", "1", "STREAMS", "s", ">"},
+			Array(Array(Bulk("s"), Array(entry("1-0", "a", "1"))))},
+		{[]string{"XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"},
+			Array(Array(Bulk("s"), Array(entry("2-0", "b", "2"))))},
+		{[]string{"XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"}, NullArray()},
+		{[]string{"XREADGROUP", "GROUP", "nope", "bob", "STREAMS", "s", ">"},
+			Error("NOGROUP No such key 's' or consumer group 'nope' in XREADGROUP with GROUP option")},
+		{[]string{"XPENDING", "s", "g"}, Array(Integer(2), Bulk("1-0"), Bulk("2-0"),
+			Array(Array(Bulk("alice"), Bulk("1")), Array(Bulk("bob"), Bulk("1"))))},
+		{[]string{"XACK", "s", "g", "1-0", "1-0"}, Integer(1)},
+		// history consumer hanya berisi entry yang belum di-ack
+		{[]string{"XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "0"},
+			Array(Array(Bulk("s"), Array()))},
+		{[]string{"XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", "0"},
+			Array(Array(Bulk("s"), Array(entry("2-0", "b", "2"))))},
+	})
+
+	// PEL dan posisi group dipulihkan dari AOF
+	e = restart(t, e, cfg)
+	execCases(t, e, []execCase{
+		{[]string{"XPENDING", "s", "g"}, Array(Integer(1), Bulk("2-0"), Bulk("2-0"),
+			Array(Array(Bulk("bob"), Bulk("1"))))},
+		{[]string{"XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"}, NullArray()},
+		{[]string{"XACK", "s", "g", "2-0"}, Integer(1)},
+		{[]string{"XPENDING", "s", "g"}, Array(Integer(0), Null(), Null(), NullArray())},
+	})
+}
+
+func TestStreamBlockingRead(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "XADD", "s", "1-0", "a", "1")
+
+	start := time.Now()
+	if got := e.Execute(0, []string{"XREAD", "BLOCK", "100", "STREAMS", "s", "$"}); !reflect.DeepEqual(got, NullArray()) {
+		t.Fatalf("XREAD BLOCK timeout = %v, want null array", got)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Fatalf("XREAD BLOCK returned after %v", d)
+	}
+
+	// "$" berarti entry setelah command diterima, bukan setelah wakeup
+	done := make(chan Reply)
+	go func() { done <- e.Execute(0, []string{"XREAD", "BLOCK", "5000", "STREAMS", "s", "$"}) }()
+	time.Sleep(50 * time.Millisecond)
+	mustExec(t, e, "XADD", "s", "2-0", "b", "2")
+
+	want := Array(Array(Bulk("s"), Array(Array(Bulk("2-0"), BulkArray([]string{"b", "2"})))))
+	select {
+	case got := <-done:
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("XREAD BLOCK = %v, want %v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("XREAD BLOCK was not woken by XADD")
+	}
+}
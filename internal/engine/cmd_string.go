@@ -0,0 +1,472 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ferrodb/internal/storage"
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "SET", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string",
+			Syntax:   "key value [NX|XX] [GET] [EX s|PX ms|EXAT ts|PXAT ms-ts|KEEPTTL]",
+			Handler:  setCommand,
+		},
+		&Command{
+			Name: "SETNX", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key value",
+			Handler: setNXCommand,
+		},
+		&Command{
+			Name: "GET", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key",
+			Handler: getCommand,
+		},
+		&Command{
+			Name: "GETSET", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key value",
+			Handler: getSetCommand,
+		},
+		&Command{
+			Name: "GETDEL", Arity: 2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key",
+			Handler: getDelCommand,
+		},
+		&Command{
+			Name: "GETEX", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key [EX s|PX ms|EXAT ts|PXAT ms-ts|PERSIST]",
+			Handler: getExCommand,
+		},
+		&Command{
+			Name: "MSET", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: -1, Step: 2,
+			Category: "string", Syntax: "key value [key value ...]",
+			Handler: msetCommand,
+		},
+		&Command{
+			Name: "MSETNX", Arity: -3, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: -1, Step: 2,
+			Category: "string", Syntax: "key value [key value ...]",
+			Handler: msetCommand,
+		},
+		&Command{
+			Name: "MGET", Arity: -2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: -1, Step: 1,
+			Category: "string", Syntax: "key [key ...]",
+			Handler: mgetCommand,
+		},
+		&Command{
+			Name: "INCR", Arity: 2, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key",
+			Handler: incrCommand,
+		},
+		&Command{
+			Name: "DECR", Arity: 2, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key",
+			Handler: incrCommand,
+		},
+		&Command{
+			Name: "INCRBY", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key increment",
+			Handler: incrCommand,
+		},
+		&Command{
+			Name: "DECRBY", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key decrement",
+			Handler: incrCommand,
+		},
+		&Command{
+			Name: "INCRBYFLOAT", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key increment",
+			Handler: incrByFloatCommand,
+		},
+		&Command{
+			Name: "APPEND", Arity: 3, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key value",
+			Handler: appendCommand,
+		},
+		&Command{
+			Name: "STRLEN", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key",
+			Handler: strlenCommand,
+		},
+		&Command{
+			Name: "GETRANGE", Arity: 4, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key start end",
+			Handler: getRangeCommand,
+		},
+		&Command{
+			Name: "SETRANGE", Arity: 4, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "string", Syntax: "key offset value",
+			Handler: setRangeCommand,
+		},
+	)
+}
+
+// expireAtFromOption mengubah opsi EX/PX/EXAT/PXAT menjadi unix timestamp
+// absolut (milidetik) yang dipakai storage.
+func expireAtFromOption(cmdName, opt, arg string) (int64, error) {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, storage.ErrNotInteger
+	}
+
+	invalid := fmt.Errorf("ERR invalid expire time in '%s' command", strings.ToLower(cmdName))
+	if n <= 0 {
+		return 0, invalid
+	}
+
+	now := time.Now().UnixMilli()
+	switch opt {
+	case "EX":
+		if n > (math.MaxInt64-now)/1000 {
+			return 0, invalid
+		}
+		return now + n*1000, nil
+	case "PX":
+		if n > math.MaxInt64-now {
+			return 0, invalid
+		}
+		return now + n, nil
+	case "EXAT":
+		if n > math.MaxInt64/1000 {
+			return 0, invalid
+		}
+		return n * 1000, nil
+	case "PXAT":
+		return n, nil
+	}
+	return 0, errSyntax
+}
+
+func isExpireOption(opt string) bool {
+	switch opt {
+	case "EX", "PX", "EXAT", "PXAT":
+		return true
+	}
+	return false
+}
+
+func setCommand(ctx *Context, args []string) Reply {
+	key, value := args[1], args[2]
+
+	var opts storage.SetOptions
+	get := false
+	hasExpire := false
+
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+
+		switch {
+		case opt == "NX" && opts.Mode != storage.SetIfExists:
+			opts.Mode = storage.SetIfNotExists
+		case opt == "XX" && opts.Mode != storage.SetIfNotExists:
+			opts.Mode = storage.SetIfExists
+		case opt == "GET":
+			get = true
+		case opt == "KEEPTTL" && !hasExpire:
+			opts.KeepTTL = true
+		case isExpireOption(opt) && !opts.KeepTTL && !hasExpire && i+1 < len(args):
+			at, err := expireAtFromOption("SET", opt, args[i+1])
+			if err != nil {
+				return errorReply(err)
+			}
+			opts.ExpireAt = at
+			hasExpire = true
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	opts.Get = get
+	old, existed, written, err := ctx.store.SetWithOptions(ctx.DB, key, value, opts)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if written {
+		// NX/XX/GET tidak relevan saat replay, TTL ditulis absolut
+		entry := []string{"SET", key, value}
+		switch {
+		case hasExpire:
+			entry = append(entry, "PXAT", strconv.FormatInt(opts.ExpireAt, 10))
+		case opts.KeepTTL:
+			entry = append(entry, "KEEPTTL")
+		}
+		ctx.Propagate(entry...)
+	} else {
+		ctx.SkipPropagate()
+	}
+
+	if get {
+		if !existed {
+			return Null()
+		}
+		return Bulk(old)
+	}
+	if !written {
+		return Null()
+	}
+	return OK()
+}
+
+func setNXCommand(ctx *Context, args []string) Reply {
+	opts := storage.SetOptions{Mode: storage.SetIfNotExists}
+	_, _, written, _ := ctx.store.SetWithOptions(ctx.DB, args[1], args[2], opts)
+	if !written {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+
+	ctx.Propagate("SET", args[1], args[2])
+	return Integer(1)
+}
+
+func getCommand(ctx *Context, args []string) Reply {
+	val, ok, err := ctx.store.Get(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return Null()
+	}
+	return Bulk(val)
+}
+
+func getSetCommand(ctx *Context, args []string) Reply {
+	opts := storage.SetOptions{Get: true}
+	old, existed, _, err := ctx.store.SetWithOptions(ctx.DB, args[1], args[2], opts)
+	if err != nil {
+		return errorReply(err)
+	}
+	ctx.Propagate("SET", args[1], args[2])
+
+	if !existed {
+		return Null()
+	}
+	return Bulk(old)
+}
+
+func getDelCommand(ctx *Context, args []string) Reply {
+	val, ok, err := ctx.store.GetDel(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		ctx.SkipPropagate()
+		return Null()
+	}
+
+	ctx.Propagate("DEL", args[1])
+	return Bulk(val)
+}
+
+func getExCommand(ctx *Context, args []string) Reply {
+	var expireAt int64
+	persist := false
+
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+
+		switch {
+		case opt == "PERSIST" && expireAt == 0 && !persist:
+			persist = true
+		case isExpireOption(opt) && expireAt == 0 && !persist && i+1 < len(args):
+			at, err := expireAtFromOption("GETEX", opt, args[i+1])
+			if err != nil {
+				return errorReply(err)
+			}
+			expireAt = at
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	val, ok, err := ctx.store.GetEx(ctx.DB, args[1], expireAt, persist)
+	switch {
+	case err != nil:
+		return errorReply(err)
+	case !ok:
+		ctx.SkipPropagate()
+		return Null()
+	case expireAt > 0:
+		ctx.Propagate("PEXPIREAT", args[1], strconv.FormatInt(expireAt, 10))
+	case persist:
+		ctx.Propagate("PERSIST", args[1])
+	default:
+		ctx.SkipPropagate()
+	}
+
+	return Bulk(val)
+}
+
+func msetCommand(ctx *Context, args []string) Reply {
+	pairs := args[1:]
+	if len(pairs)%2 != 0 {
+		return wrongArgs(args[0])
+	}
+
+	nx := strings.EqualFold(args[0], "MSETNX")
+	ok := ctx.store.MSet(ctx.DB, pairs, nx)
+
+	if !nx {
+		return OK()
+	}
+	if !ok {
+		ctx.SkipPropagate()
+		return Integer(0)
+	}
+	return Integer(1)
+}
+
+func mgetCommand(ctx *Context, args []string) Reply {
+	values, found := ctx.store.MGet(ctx.DB, args[1:])
+
+	out := make([]Reply, len(values))
+	for i := range values {
+		if found[i] {
+			out[i] = Bulk(values[i])
+		} else {
+			out[i] = Null()
+		}
+	}
+	return Array(out...)
+}
+
+func incrCommand(ctx *Context, args []string) Reply {
+	var delta int64 = 1
+
+	name := strings.ToUpper(args[0])
+	if name == "INCRBY" || name == "DECRBY" {
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return errorReply(storage.ErrNotInteger)
+		}
+		delta = n
+	}
+
+	if name == "DECR" || name == "DECRBY" {
+		if delta == math.MinInt64 {
+			return Error("ERR decrement would overflow")
+		}
+		delta = -delta
+	}
+
+	n, err := ctx.store.IncrBy(ctx.DB, args[1], delta)
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(n)
+}
+
+func incrByFloatCommand(ctx *Context, args []string) Reply {
+	delta, err := strconv.ParseFloat(args[2], 64)
+	if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return errorReply(storage.ErrNotFloat)
+	}
+
+	val, err := ctx.store.IncrByFloat(ctx.DB, args[1], delta)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	// hasil float ditulis apa adanya supaya replay tidak bergeser presisinya
+	ctx.Propagate("SET", args[1], val, "KEEPTTL")
+	return Bulk(val)
+}
+
+func appendCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.Append(ctx.DB, args[1], args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func strlenCommand(ctx *Context, args []string) Reply {
+	val, _, err := ctx.store.Get(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(len(val)))
+}
+
+func getRangeCommand(ctx *Context, args []string) Reply {
+	start, err1 := strconv.ParseInt(args[2], 10, 64)
+	end, err2 := strconv.ParseInt(args[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+
+	val, _, err := ctx.store.Get(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	n := int64(len(val))
+
+	if start < 0 && end < 0 && start > end {
+		return Bulk("")
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 {
+		end = 0
+	}
+	if end >= n {
+		end = n - 1
+	}
+	if n == 0 || start > end {
+		return Bulk("")
+	}
+
+	return Bulk(val[start : end+1])
+}
+
+func setRangeCommand(ctx *Context, args []string) Reply {
+	offset, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+	if offset < 0 || offset > storage.MaxStringSize {
+		return Error("ERR offset is out of range")
+	}
+
+	n, err := ctx.store.SetRange(ctx.DB, args[1], int(offset), args[3])
+	if err != nil {
+		return errorReply(err)
+	}
+	if args[3] == "" {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
@@ -0,0 +1,583 @@
+package engine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/storage"
+)
+
+var (
+	errMinMaxNotFloat = errors.New("ERR min or max is not a float")
+	errLexRange       = errors.New("ERR min or max not valid string range item")
+	errWeightNotFloat = errors.New("ERR weight value is not a float")
+)
+
+func init() {
+	registerCommands(
+		&Command{
+			Name: "ZADD", Arity: -4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...]",
+			Handler: zaddCommand,
+		},
+		&Command{
+			Name: "ZINCRBY", Arity: 4, Flags: FlagWrite | FlagDenyOOM | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key increment member",
+			Handler: zincrbyCommand,
+		},
+		&Command{
+			Name: "ZREM", Arity: -3, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key member [member ...]",
+			Handler: zremCommand,
+		},
+		&Command{
+			Name: "ZSCORE", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key member",
+			Handler: zscoreCommand,
+		},
+		&Command{
+			Name: "ZCARD", Arity: 2, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key",
+			Handler: zcardCommand,
+		},
+		&Command{
+			Name: "ZRANK", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key member",
+			Handler: zrankCommand,
+		},
+		&Command{
+			Name: "ZREVRANK", Arity: 3, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key member",
+			Handler: zrankCommand,
+		},
+		&Command{
+			Name: "ZRANGE", Arity: -4, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key start stop [BYSCORE|BYLEX] [REV] [LIMIT offset count] [WITHSCORES]",
+			Handler: zrangeCommand,
+		},
+		&Command{
+			Name: "ZCOUNT", Arity: 4, Flags: FlagReadOnly | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key min max",
+			Handler: zcountCommand,
+		},
+		&Command{
+			Name: "ZPOPMIN", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key [count]",
+			Handler: zpopCommand,
+		},
+		&Command{
+			Name: "ZPOPMAX", Arity: -2, Flags: FlagWrite | FlagFast,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key [count]",
+			Handler: zpopCommand,
+		},
+		&Command{
+			Name: "BZPOPMIN", Arity: -3, Flags: FlagWrite | FlagBlocking,
+			FirstKey: 1, LastKey: -2, Step: 1,
+			Category: "sortedset", Syntax: "key [key ...] timeout",
+			Handler: bzpopCommand,
+		},
+		&Command{
+			Name: "BZPOPMAX", Arity: -3, Flags: FlagWrite | FlagBlocking,
+			FirstKey: 1, LastKey: -2, Step: 1,
+			Category: "sortedset", Syntax: "key [key ...] timeout",
+			Handler: bzpopCommand,
+		},
+		&Command{
+			Name: "ZUNIONSTORE", Arity: -4, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1, GetKeys: zstoreKeys,
+			Category: "sortedset", Syntax: "destination numkeys key [key ...] [WEIGHTS weight ...] [AGGREGATE SUM|MIN|MAX]",
+			Handler: zstoreCommand,
+		},
+		&Command{
+			Name: "ZINTERSTORE", Arity: -4, Flags: FlagWrite | FlagDenyOOM,
+			FirstKey: 1, LastKey: 1, Step: 1, GetKeys: zstoreKeys,
+			Category: "sortedset", Syntax: "destination numkeys key [key ...] [WEIGHTS weight ...] [AGGREGATE SUM|MIN|MAX]",
+			Handler: zstoreCommand,
+		},
+		&Command{
+			Name: "ZSCAN", Arity: -3, Flags: FlagReadOnly,
+			FirstKey: 1, LastKey: 1, Step: 1,
+			Category: "sortedset", Syntax: "key cursor [MATCH pattern] [COUNT count]",
+			Handler: zscanCommand,
+		},
+	)
+}
+
+// zmembersReply merender member (dan score bila withScores) sebagai array
+// datar member, score, member, score, ...
+func zmembersReply(members []storage.ZMember, withScores bool) Reply {
+	out := make([]Reply, 0, len(members)*2)
+	for _, m := range members {
+		out = append(out, Bulk(m.Member))
+		if withScores {
+			out = append(out, Bulk(storage.FormatScore(m.Score)))
+		}
+	}
+	return Array(out...)
+}
+
+func zaddCommand(ctx *Context, args []string) Reply {
+	var opts storage.ZAddOptions
+	ch := false
+
+	i := 2
+loop:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			opts.NX = true
+		case "XX":
+			opts.XX = true
+		case "GT":
+			opts.GT = true
+		case "LT":
+			opts.LT = true
+		case "CH":
+			ch = true
+		case "INCR":
+			opts.Incr = true
+		default:
+			break loop
+		}
+	}
+
+	rest := args[i:]
+	if len(rest) == 0 || len(rest)%2 != 0 {
+		return errorReply(errSyntax)
+	}
+	if opts.NX && opts.XX {
+		return Error("ERR XX and NX options at the same time are not compatible")
+	}
+	if (opts.GT && opts.LT) || (opts.NX && (opts.GT || opts.LT)) {
+		return Error("ERR GT, LT, and/or NX options at the same time are not compatible")
+	}
+	if opts.Incr && len(rest) != 2 {
+		return Error("ERR INCR option supports a single increment-element pair")
+	}
+
+	members := make([]storage.ZMember, 0, len(rest)/2)
+	for j := 0; j < len(rest); j += 2 {
+		score, err := storage.ParseScore(rest[j])
+		if err != nil {
+			return errorReply(err)
+		}
+		members = append(members, storage.ZMember{Member: rest[j+1], Score: score})
+	}
+
+	res, err := ctx.store.ZAdd(ctx.DB, args[1], opts, members)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if res.Added+res.Updated == 0 {
+		ctx.SkipPropagate()
+	} else {
+		ctx.engine.waits.signal(ctx.DB, args[1])
+		if opts.Incr {
+			// score akhir dicatat langsung agar replay tidak bergantung
+			// pada score sebelumnya
+			ctx.Propagate("ZADD", args[1], storage.FormatScore(res.Score), members[0].Member)
+		}
+	}
+
+	if opts.Incr {
+		if !res.Applied {
+			return Null()
+		}
+		return Bulk(storage.FormatScore(res.Score))
+	}
+	if ch {
+		return Integer(int64(res.Added + res.Updated))
+	}
+	return Integer(int64(res.Added))
+}
+
+func zincrbyCommand(ctx *Context, args []string) Reply {
+	incr, err := storage.ParseScore(args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	res, err := ctx.store.ZAdd(ctx.DB, args[1],
+		storage.ZAddOptions{Incr: true},
+		[]storage.ZMember{{Member: args[3], Score: incr}})
+	if err != nil {
+		return errorReply(err)
+	}
+
+	score := storage.FormatScore(res.Score)
+	ctx.Propagate("ZADD", args[1], score, args[3])
+	ctx.engine.waits.signal(ctx.DB, args[1])
+	return Bulk(score)
+}
+
+func zremCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.ZRem(ctx.DB, args[1], args[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	if n == 0 {
+		ctx.SkipPropagate()
+	}
+	return Integer(int64(n))
+}
+
+func zscoreCommand(ctx *Context, args []string) Reply {
+	score, ok, err := ctx.store.ZScore(ctx.DB, args[1], args[2])
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return Null()
+	}
+	return Bulk(storage.FormatScore(score))
+}
+
+func zcardCommand(ctx *Context, args []string) Reply {
+	n, err := ctx.store.ZCard(ctx.DB, args[1])
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func zrankCommand(ctx *Context, args []string) Reply {
+	rev := strings.EqualFold(args[0], "ZREVRANK")
+
+	rank, ok, err := ctx.store.ZRank(ctx.DB, args[1], args[2], rev)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return Null()
+	}
+	return Integer(int64(rank))
+}
+
+// parseScoreBound membaca batas score: "-inf", "+inf", "1.5" atau "(1.5".
+func parseScoreBound(s string) (float64, bool, error) {
+	excl := strings.HasPrefix(s, "(")
+	if excl {
+		s = s[1:]
+	}
+
+	f, err := storage.ParseScore(s)
+	if err != nil {
+		return 0, false, errMinMaxNotFloat
+	}
+	return f, excl, nil
+}
+
+func parseScoreRange(min, max string) (storage.ScoreRange, error) {
+	var r storage.ScoreRange
+	var err error
+
+	if r.Min, r.MinEx, err = parseScoreBound(min); err != nil {
+		return r, err
+	}
+	if r.Max, r.MaxEx, err = parseScoreBound(max); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// parseLexBound membaca batas BYLEX: "-", "+", "[a" (inklusif), "(a".
+func parseLexBound(s string) (val string, excl, inf bool, err error) {
+	switch {
+	case s == "-" || s == "+":
+		return "", false, true, nil
+	case strings.HasPrefix(s, "["):
+		return s[1:], false, false, nil
+	case strings.HasPrefix(s, "("):
+		return s[1:], true, false, nil
+	}
+	return "", false, false, errLexRange
+}
+
+func parseLexRange(min, max string) (storage.LexRange, error) {
+	var r storage.LexRange
+	var err error
+
+	if r.Min, r.MinEx, r.MinInf, err = parseLexBound(min); err != nil {
+		return r, err
+	}
+	if r.Max, r.MaxEx, r.MaxInf, err = parseLexBound(max); err != nil {
+		return r, err
+	}
+
+	// "+" sebagai min atau "-" sebagai max berarti range kosong
+	if min == "+" || max == "-" {
+		return storage.LexRange{MinEx: true}, nil
+	}
+	return r, nil
+}
+
+func zrangeCommand(ctx *Context, args []string) Reply {
+	var byScore, byLex, rev, withScores, limit bool
+	offset, count := 0, -1
+
+	for i := 4; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "BYSCORE":
+			byScore = true
+		case "BYLEX":
+			byLex = true
+		case "REV":
+			rev = true
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return errorReply(errSyntax)
+			}
+			o, err1 := strconv.Atoi(args[i+1])
+			c, err2 := strconv.Atoi(args[i+2])
+			if err1 != nil || err2 != nil {
+				return errorReply(storage.ErrNotInteger)
+			}
+			offset, count, limit = o, c, true
+			i += 2
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	if byScore && byLex {
+		return errorReply(errSyntax)
+	}
+	if limit && !byScore && !byLex {
+		return Error("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+	}
+	if withScores && byLex {
+		return Error("ERR syntax error, WITHSCORES not supported in combination with BYLEX")
+	}
+
+	// dengan REV, range score/lex ditulis max dulu lalu min
+	lo, hi := args[2], args[3]
+	if rev && (byScore || byLex) {
+		lo, hi = hi, lo
+	}
+
+	var members []storage.ZMember
+	var err error
+
+	switch {
+	case byScore:
+		r, perr := parseScoreRange(lo, hi)
+		if perr != nil {
+			return errorReply(perr)
+		}
+		members, err = ctx.store.ZRangeByScore(ctx.DB, args[1], r, rev, offset, count)
+
+	case byLex:
+		r, perr := parseLexRange(lo, hi)
+		if perr != nil {
+			return errorReply(perr)
+		}
+		members, err = ctx.store.ZRangeByLex(ctx.DB, args[1], r, rev, offset, count)
+
+	default:
+		start, err1 := strconv.Atoi(lo)
+		stop, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil {
+			return errorReply(storage.ErrNotInteger)
+		}
+		members, err = ctx.store.ZRangeByRank(ctx.DB, args[1], start, stop, rev)
+	}
+
+	if err != nil {
+		return errorReply(err)
+	}
+	return zmembersReply(members, withScores)
+}
+
+func zcountCommand(ctx *Context, args []string) Reply {
+	r, err := parseScoreRange(args[2], args[3])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	n, err := ctx.store.ZCount(ctx.DB, args[1], r)
+	if err != nil {
+		return errorReply(err)
+	}
+	return Integer(int64(n))
+}
+
+func zpopCommand(ctx *Context, args []string) Reply {
+	max := strings.EqualFold(args[0], "ZPOPMAX")
+
+	if len(args) > 3 {
+		return errorReply(errSyntax)
+	}
+
+	count := 1
+	if len(args) == 3 {
+		n, err := strconv.Atoi(args[2])
+		if err != nil || n < 0 {
+			return Error("ERR value is out of range, must be positive")
+		}
+		count = n
+	}
+
+	members, err := ctx.store.ZPop(ctx.DB, args[1], max, count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	if len(members) == 0 {
+		ctx.SkipPropagate()
+	} else {
+		ctx.Propagate(strings.ToUpper(args[0]), args[1], strconv.Itoa(len(members)))
+	}
+	return zmembersReply(members, true)
+}
+
+func bzpopCommand(ctx *Context, args []string) Reply {
+	max := strings.EqualFold(args[0], "BZPOPMAX")
+	keys := args[1 : len(args)-1]
+
+	timeout, err := parseTimeout(args[len(args)-1])
+	if err != nil {
+		return errorReply(err)
+	}
+
+	name := "ZPOPMIN"
+	if max {
+		name = "ZPOPMAX"
+	}
+
+	r := ctx.block(keys, timeout, func() (Reply, bool) {
+		for _, key := range keys {
+			members, err := ctx.store.ZPop(ctx.DB, key, max, 1)
+			if err != nil {
+				return errorReply(err), true
+			}
+			if len(members) > 0 {
+				ctx.Propagate(name, key)
+				return Array(
+					Bulk(key),
+					Bulk(members[0].Member),
+					Bulk(storage.FormatScore(members[0].Score)),
+				), true
+			}
+		}
+		return Reply{}, false
+	})
+
+	if r.Kind == ReplyNullArray {
+		ctx.SkipPropagate()
+	}
+	return r
+}
+
+// zstoreKeys: destination numkeys key [key ...]
+func zstoreKeys(args []string) []string {
+	if len(args) < 3 {
+		return nil
+	}
+	keys := []string{args[1]}
+
+	n, err := strconv.Atoi(args[2])
+	if err != nil || n < 0 || 3+n > len(args) {
+		return keys
+	}
+	return append(keys, args[3:3+n]...)
+}
+
+func zstoreCommand(ctx *Context, args []string) Reply {
+	inter := strings.EqualFold(args[0], "ZINTERSTORE")
+
+	numKeys, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errorReply(storage.ErrNotInteger)
+	}
+	if numKeys < 1 {
+		return Errorf("ERR at least 1 input key is needed for '%s' command", strings.ToLower(args[0]))
+	}
+	if 3+numKeys > len(args) {
+		return errorReply(errSyntax)
+	}
+
+	keys := args[3 : 3+numKeys]
+	weights := make([]float64, numKeys)
+	for i := range weights {
+		weights[i] = 1
+	}
+	agg := storage.ZAggSum
+
+	for i := 3 + numKeys; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "WEIGHTS":
+			if i+numKeys >= len(args) {
+				return errorReply(errSyntax)
+			}
+			for j := 0; j < numKeys; j++ {
+				w, err := storage.ParseScore(args[i+1+j])
+				if err != nil {
+					return errorReply(errWeightNotFloat)
+				}
+				weights[j] = w
+			}
+			i += numKeys
+		case "AGGREGATE":
+			if i+1 >= len(args) {
+				return errorReply(errSyntax)
+			}
+			switch strings.ToUpper(args[i+1]) {
+			case "SUM":
+				agg = storage.ZAggSum
+			case "MIN":
+				agg = storage.ZAggMin
+			case "MAX":
+				agg = storage.ZAggMax
+			default:
+				return errorReply(errSyntax)
+			}
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	n, err := ctx.store.ZSetStore(ctx.DB, args[1], keys, weights, agg, inter)
+	if err != nil {
+		return errorReply(err)
+	}
+	if n > 0 {
+		ctx.engine.waits.signal(ctx.DB, args[1])
+	}
+	return Integer(int64(n))
+}
+
+func zscanCommand(ctx *Context, args []string) Reply {
+	opts, err := parseScanArgs(args, 2)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	next, members, err := ctx.store.ZScan(ctx.DB, args[1], opts.cursor, opts.count)
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var items []Reply
+	for _, m := range members {
+		if opts.matches(m.Member) {
+			items = append(items, Bulk(m.Member), Bulk(storage.FormatScore(m.Score)))
+		}
+	}
+	return scanReply(next, items)
+}
@@ -0,0 +1,82 @@
+package engine
+
+import "testing"
+
+func TestZSetCommands(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	execCases(t, e, []execCase{
+		{[]string{"ZADD", "z", "1", "a", "2", "b", "3", "c"}, Integer(3)},
+		{[]string{"ZADD", "z", "NX", "XX", "1", "a"}, Error("ERR XX and NX options at the same time are not compatible")},
+		{[]string{"ZADD", "z", "CH", "5", "a", "4", "d"}, Integer(2)},
+		{[]string{"ZADD", "z", "GT", "1", "a"}, Integer(0)},
+		{[]string{"ZADD", "z", "INCR", "1", "b"}, Bulk("3")},
+		{[]string{"ZINCRBY", "z", "-0.5", "c"}, Bulk("2.5")},
+		{[]string{"ZSCORE", "z", "a"}, Bulk("5")},
+		{[]string{"ZSCORE", "z", "x"}, Null()},
+		{[]string{"ZCARD", "z"}, Integer(4)},
+		{[]string{"ZRANK", "z", "b"}, Integer(1)},
+		{[]string{"ZREVRANK", "z", "b"}, Integer(2)},
+		{[]string{"ZRANK", "z", "x"}, Null()},
+		{[]string{"ZCOUNT", "z", "(2.5", "+inf"}, Integer(3)},
+
+		{[]string{"ZRANGE", "z", "0", "-1"}, BulkArray([]string{"c", "b", "d", "a"})},
+		{[]string{"ZRANGE", "z", "0", "1", "WITHSCORES"}, BulkArray([]string{"c", "2.5", "b", "3"})},
+		{[]string{"ZRANGE", "z", "0", "-1", "REV"}, BulkArray([]string{"a", "d", "b", "c"})},
+		{[]string{"ZRANGE", "z", "3", "(5", "BYSCORE"}, BulkArray([]string{"b", "d"})},
+		{[]string{"ZRANGE", "z", "+inf", "-inf", "BYSCORE", "REV", "LIMIT", "1", "2"}, BulkArray([]string{"d", "b"})},
+		{[]string{"ZRANGE", "z", "0", "1", "LIMIT", "0", "1"}, Error("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")},
+
+		{[]string{"ZPOPMIN", "z"}, BulkArray([]string{"c", "2.5"})},
+		{[]string{"ZPOPMAX", "z", "2"}, BulkArray([]string{"a", "5", "d", "4"})},
+		{[]string{"ZPOPMIN", "z", "-1"}, Error("ERR value is out of range, must be positive")},
+		{[]string{"ZREM", "z", "b", "x"}, Integer(1)},
+		{[]string{"EXISTS", "z"}, Integer(0)},
+
+		{[]string{"SET", "s", "v"}, OK()},
+		{[]string{"ZADD", "s", "1", "a"}, Error("WRONGTYPE Operation against a key holding the wrong kind of value")},
+	})
+}
+
+func TestZSetLex(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "ZADD", "z", "0", "a", "0", "b", "0", "c", "0", "d")
+	execCases(t, e, []execCase{
+		{[]string{"ZRANGE", "z", "[b", "(d", "BYLEX"}, BulkArray([]string{"b", "c"})},
+		{[]string{"ZRANGE", "z", "-", "+", "BYLEX", "LIMIT", "1", "2"}, BulkArray([]string{"b", "c"})},
+		{[]string{"ZRANGE", "z", "+", "(b", "BYLEX", "REV"}, BulkArray([]string{"d", "c"})},
+		{[]string{"ZRANGE", "z", "-", "+", "BYLEX", "WITHSCORES"}, Error("ERR syntax error, WITHSCORES not supported in combination with BYLEX")},
+	})
+}
+
+func TestZSetStore(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "ZADD", "a", "1", "x", "2", "y")
+	mustExec(t, e, "ZADD", "b", "10", "y", "20", "z")
+	execCases(t, e, []execCase{
+		{[]string{"ZUNIONSTORE", "u", "2", "a", "b"}, Integer(3)},
+		{[]string{"ZRANGE", "u", "0", "-1", "WITHSCORES"}, BulkArray([]string{"x", "1", "y", "12", "z", "20"})},
+		{[]string{"ZINTERSTORE", "i", "2", "a", "b", "WEIGHTS", "2", "1", "AGGREGATE", "MAX"}, Integer(1)},
+		{[]string{"ZRANGE", "i", "0", "-1", "WITHSCORES"}, BulkArray([]string{"y", "10"})},
+		{[]string{"ZINTERSTORE", "i", "2", "a", "missing"}, Integer(0)},
+		{[]string{"EXISTS", "i"}, Integer(0)},
+		{[]string{"ZUNIONSTORE", "u", "0", "a"}, Error("ERR at least 1 input key is needed for 'zunionstore' command")},
+	})
+}
+
+func TestZSetScan(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "no"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "ZADD", "z", "1", "a1", "2", "a2", "1.5", "b1")
+	execCases(t, e, []execCase{
+		{[]string{"ZSCAN", "z", "0", "MATCH", "b*", "COUNT", "100"}, Array(Bulk("0"), BulkArray([]string{"b1", "1.5"}))},
+		{[]string{"ZSCAN", "missing", "0"}, Array(Bulk("0"), Array())},
+		{[]string{"ZSCAN", "z", "-1"}, Error("ERR invalid cursor")},
+	})
+}
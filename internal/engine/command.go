@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"ferrodb/internal/storage"
+)
+
+type CommandFlag uint
+
+const (
+	FlagWrite CommandFlag = 1 << iota
+	FlagReadOnly
+	FlagAdmin
+	FlagFast
+	FlagNoAuth     // boleh dipanggil sebelum AUTH
+	FlagConnection // dieksekusi oleh layer koneksi (server), bukan engine
+	FlagBlocking   // bisa parkir menunggu data (BLPOP, ...)
+	FlagDenyOOM    // ditolak saat memori melewati maxmemory
+)
+
+var flagNames = []struct {
+	flag CommandFlag
+	name string
+}{
+	{FlagWrite, "write"},
+	{FlagReadOnly, "readonly"},
+	{FlagAdmin, "admin"},
+	{FlagFast, "fast"},
+	{FlagNoAuth, "no-auth"},
+	{FlagConnection, "connection"},
+	{FlagBlocking, "blocking"},
+	{FlagDenyOOM, "denyoom"},
+}
+
+// HandlerFunc menerima argv lengkap (args[0] = nama command).
+type HandlerFunc func(ctx *Context, args []string) Reply
+
+// Command mendeskripsikan satu entry command table. Table ini menjadi
+// satu-satunya sumber untuk dispatch, COMMAND, HELP, ACL dan AOF.
+type Command struct {
+	Name  string
+	Arity int // > 0 jumlah argv pasti, < 0 minimal -Arity
+	Flags CommandFlag
+
+	// posisi key di argv (1-based), 0 bila command tidak punya key
+	FirstKey int
+	LastKey  int // -1 = sampai argumen terakhir
+	Step     int
+
+	// GetKeys dipakai command yang jumlah key-nya ditentukan argumen
+	// (mis. numkeys di ZUNIONSTORE); bila ada, menggantikan posisi di atas
+	GetKeys func(args []string) []string
+
+	// CrossDB menandai command yang bisa menulis ke DB lain (MOVE, COPY);
+	// command seperti ini dijalankan sambil memegang lock semua shard
+	CrossDB bool
+
+	Category string // kategori ACL utama, mis. "string", "keyspace"
+	Syntax   string // argumen untuk HELP, mis. "key value"
+
+	Handler HandlerFunc
+}
+
+var commandTable = map[string]*Command{}
+
+func registerCommands(cmds ...*Command) {
+	for _, c := range cmds {
+		commandTable[c.Name] = c
+	}
+}
+
+// LookupCommand mencari command berdasarkan nama (case-insensitive).
+func LookupCommand(name string) (*Command, bool) {
+	c, ok := commandTable[strings.ToUpper(name)]
+	return c, ok
+}
+
+// Commands mengembalikan seluruh command table terurut berdasarkan nama.
+func Commands() []*Command {
+	out := make([]*Command, 0, len(commandTable))
+	for _, c := range commandTable {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func (c *Command) Has(flag CommandFlag) bool {
+	return c.Flags&flag != 0
+}
+
+func (c *Command) CheckArity(argc int) bool {
+	if c.Arity > 0 {
+		return argc == c.Arity
+	}
+	return argc >= -c.Arity
+}
+
+func (c *Command) FlagNames() []string {
+	var out []string
+	for _, f := range flagNames {
+		if c.Has(f.flag) {
+			out = append(out, f.name)
+		}
+	}
+	if c.GetKeys != nil {
+		out = append(out, "movablekeys")
+	}
+	return out
+}
+
+// ACLCategories menggabungkan kategori utama dengan kategori yang
+// diturunkan dari flags (read/write/admin/fast/slow).
+func (c *Command) ACLCategories() []string {
+	cats := []string{c.Category}
+
+	if c.Has(FlagWrite) {
+		cats = append(cats, "write")
+	}
+	if c.Has(FlagReadOnly) {
+		cats = append(cats, "read")
+	}
+	if c.Has(FlagAdmin) {
+		cats = append(cats, "admin", "dangerous")
+	}
+	if c.Has(FlagBlocking) {
+		cats = append(cats, "blocking")
+	}
+	if c.Has(FlagFast) {
+		cats = append(cats, "fast")
+	} else {
+		cats = append(cats, "slow")
+	}
+	return cats
+}
+
+func (c *Command) InCategory(cat string) bool {
+	for _, have := range c.ACLCategories() {
+		if have == cat {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys mengembalikan argumen yang merupakan key berdasarkan posisi key.
+func (c *Command) Keys(args []string) []string {
+	if c.GetKeys != nil {
+		return c.GetKeys(args)
+	}
+	if c.FirstKey <= 0 || c.FirstKey >= len(args) {
+		return nil
+	}
+
+	last := c.LastKey
+	if last < 0 {
+		last = len(args) + last
+	}
+	if last >= len(args) {
+		last = len(args) - 1
+	}
+
+	step := c.Step
+	if step <= 0 {
+		step = 1
+	}
+
+	var keys []string
+	for i := c.FirstKey; i <= last; i += step {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// ACLCategoryNames mengembalikan semua kategori yang dipakai command table.
+func ACLCategoryNames() []string {
+	seen := map[string]bool{}
+	for _, c := range commandTable {
+		for _, cat := range c.ACLCategories() {
+			seen[cat] = true
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for cat := range seen {
+		out = append(out, cat)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Context membawa state satu eksekusi command ke handler.
+type Context struct {
+	engine *Engine
+	store  *storage.MemoryStore // store, atau view yang memegang lock key command
+	DB     int
+
+	done    <-chan struct{} // ditutup bila client putus
+	noBlock bool            // command blocking langsung kembali (replay)
+	held    []sync.Locker   // lock yang dipegang selama eksekusi, urut diambil
+	existed map[string]bool // key yang ada sebelum handler, lihat notifyBefore
+
+	// AOF propagation: nil = tulis argv asli, selain itu tulis command ini
+	propagate   [][]string
+	noPropagate bool
+}
+
+// Propagate mengganti argv yang ditulis ke AOF dengan bentuk yang aman
+// untuk replay (mis. EXPIRE -> EXPIREAT). Boleh dipanggil berkali-kali.
+func (ctx *Context) Propagate(args ...string) {
+	ctx.propagate = append(ctx.propagate, args)
+}
+
+// SkipPropagate menandai command write yang tidak mengubah data.
+func (ctx *Context) SkipPropagate() {
+	ctx.noPropagate = true
+}
+
+func wrongArgs(name string) Reply {
+	return Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
@@ -1,229 +1,340 @@
 package engine
 
 import (
-	"fmt"
-	"strconv"
+	"context"
+	"errors"
+	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"ferrodb/internal/config"
-	"ferrodb/internal/parser"
 	"ferrodb/internal/persistence"
+	"ferrodb/internal/pubsub"
 	"ferrodb/internal/storage"
 )
 
 type Engine struct {
 	store     *storage.MemoryStore
 	aof       *persistence.AOF
+	waits     *waitQueue
 	startTime time.Time
+
+	gate      sync.RWMutex // exclusive selama EXEC
+	rewriting atomic.Bool  // BGREWRITEAOF sedang berjalan
+
+	pubsub      *pubsub.Hub
+	notifyFlags int // notify_keyspace_events, lihat notify.go
 }
 
 func New(cfg *config.Config) *Engine {
 	store := storage.NewMemoryStore(cfg.Engine.DBCount, cfg.Engine.CleanupIntervalSec)
 
-	aof, err := persistence.OpenAOF(cfg.AOFPath())
+	fsync, err := persistence.ParseFsyncPolicy(cfg.Data.AppendFsync)
+	if err != nil {
+		panic(err)
+	}
+	aof, err := persistence.OpenAOF(cfg.AOFPath(), fsync)
+	if err != nil {
+		panic(err)
+	}
+
+	maxmemory, err := cfg.MaxMemoryBytes()
+	if err != nil {
+		panic(err)
+	}
+	policy, err := storage.ParseEvictionPolicy(cfg.Memory.MaxMemoryPolicy)
 	if err != nil {
 		panic(err)
 	}
+	store.SetMaxMemory(maxmemory, policy, cfg.Memory.MaxMemorySamples)
 
 	engine := &Engine{
 		store:     store,
 		aof:       aof,
+		waits:     newWaitQueue(),
 		startTime: time.Now(),
+		pubsub:    pubsub.NewHub(),
 	}
 
-	aof.Replay(func(line string) {
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			return
-		}
-
-		db, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return
+	// expiry baru aktif setelah replay, lihat MemoryStore.SetLoading
+	store.SetLoading(true)
+	err = aof.Replay(cfg.Data.AOFLoadTruncated, func(db int, args []string) error {
+		reply := engine.executeInternal(context.Background(), db, args, false)
+		if reply.IsError() {
+			return errors.New(reply.Str)
 		}
-
-		// buang arg DB
-		cmd := strings.Join(append([]string{parts[0]}, parts[2:]...), " ")
-		engine.executeInternal(db, cmd, false)
+		return nil
 	})
+	if err != nil {
+		log.Fatalf("failed to load AOF: %v", err)
+	}
+
+	// notifikasi baru aktif setelah replay agar isi AOF tidak di-publish
+	flags, err := parseNotifyFlags(cfg.Engine.NotifyKeyspaceEvents)
+	if err != nil {
+		panic(err)
+	}
+	engine.notifyFlags = flags
+	store.SetExpireHook(engine.expired)
+	store.SetEvictHook(engine.evicted)
+	store.SetLoading(false)
 
 	return engine
 }
 
-func (e *Engine) Execute(db int, input string) string {
-	return e.executeInternal(db, input, true)
+// Execute menjalankan command yang sudah ter-tokenize. args[0] adalah nama
+// command; value tidak pernah dipecah ulang sehingga aman untuk data biner.
+func (e *Engine) Execute(db int, args []string) Reply {
+	return e.executeInternal(context.Background(), db, args, true)
 }
 
-func (e *Engine) executeInternal(db int, input string, persist bool) string {
-	cmd := parser.Parse(input)
-
-	switch cmd.Name {
+// ExecuteContext sama dengan Execute, tetapi command blocking (BLPOP, ...)
+// berhenti menunggu begitu c dibatalkan, mis. saat client putus.
+func (e *Engine) ExecuteContext(c context.Context, db int, args []string) Reply {
+	return e.executeInternal(c, db, args, true)
+}
 
-	case "SET":
-		if len(cmd.Args) < 2 {
-			return "ERR SET requires key and value"
-		}
+func (e *Engine) executeInternal(c context.Context, db int, args []string, persist bool) Reply {
+	cmd, errReply := e.lookup(db, args)
+	if cmd == nil {
+		return errReply
+	}
 
-		e.store.Set(db, cmd.Args[0], cmd.Args[1])
+	// gate dipegang shared; EXEC mengambilnya exclusive agar isi transaksi
+	// tidak pernah berselang-seling dengan command client lain
+	e.gate.RLock()
+	defer e.gate.RUnlock()
+
+	ctx := &Context{
+		engine:  e,
+		store:   e.store,
+		DB:      db,
+		done:    c.Done(),
+		noBlock: !persist,
+		held:    []sync.Locker{e.gate.RLocker()},
+	}
+	if !cmd.Has(FlagWrite) {
+		reply, _ := e.run(ctx, cmd, args)
+		return reply
+	}
 
-		if persist {
-			e.aof.Write(fmt.Sprintf("SET %d %s %s", db, cmd.Args[0], cmd.Args[1]))
-		}
-		return "OK"
+	reply, pending := e.runWrite(ctx, cmd, args, persist)
+	waitAOF(pending)
+	return reply
+}
 
-	case "GET":
-		if len(cmd.Args) < 1 {
-			return "ERR GET requires key"
+// runWrite menjalankan command write sambil memegang lock shard milik
+// key-nya, lalu mengantrikan entry AOF sebelum lock dilepas. Write pada
+// key yang sama terserialkan oleh lock shard sehingga urutannya di AOF
+// sama dengan urutan diterapkan di memori, sementara write di shard lain
+// tetap berjalan paralel. Menunggu fsync dilakukan pemanggil setelah lock
+// dilepas.
+func (e *Engine) runWrite(ctx *Context, cmd *Command, args []string, persist bool) (Reply, persistence.Pending) {
+	if persist {
+		if oom := e.freeMemory(ctx.store, cmd); oom.IsError() {
+			return oom, persistence.Pending{}
 		}
+	}
 
-		val, ok := e.store.Get(db, cmd.Args[0])
-		if !ok {
-			return "(nil)"
-		}
-		return val
+	view := e.lockScope(ctx.DB, cmd, args)
+	defer view.Unlock()
+	ctx.store = view
+	ctx.held = append(ctx.held, view)
 
-	case "DEL":
-		if len(cmd.Args) < 1 {
-			return "ERR DEL requires key"
-		}
+	reply, entries := e.run(ctx, cmd, args)
+	if !persist {
+		return reply, persistence.Pending{}
+	}
+	return reply, e.appendAOF(entries)
+}
 
-		deleted := e.store.Del(db, cmd.Args[0])
+// lockScope mengunci shard milik key command write. Command tanpa key
+// (FLUSHDB, SWAPDB, ...) dan command CrossDB mengunci semua shard.
+func (e *Engine) lockScope(db int, cmd *Command, args []string) *storage.MemoryStore {
+	keys := cmd.Keys(args)
+	if len(keys) == 0 || cmd.CrossDB {
+		return e.store.LockAll()
+	}
+	return e.store.LockKeys(db, keys...)
+}
 
-		if persist {
-			e.aof.Write(fmt.Sprintf("DEL %d %s", db, cmd.Args[0]))
-		}
-		return strconv.Itoa(deleted)
+// freeMemory menjalankan eviction sebelum command bila memori melewati
+// maxmemory; key yang di-evict dipropagasi oleh evicted. Command denyoom
+// ditolak bila memori tetap penuh.
+func (e *Engine) freeMemory(store *storage.MemoryStore, cmd *Command) Reply {
+	if err := store.FreeMemory(); err != nil && cmd.Has(FlagDenyOOM) {
+		return errorReply(err)
+	}
+	return Reply{}
+}
 
-	case "EXPIRE":
-		if len(cmd.Args) < 2 {
-			return "ERR EXPIRE requires key and seconds"
-		}
+// expired dipanggil store setiap kali key expired dihapus (lazy maupun
+// active expire), sambil memegang lock shard milik key. DEL ditulis ke AOF
+// saat itu juga agar replay tidak bergantung pada jam. Command write juga
+// mengantrikan entry AOF-nya sebelum melepas lock shard yang sama (lihat
+// runWrite), sehingga DEL ini selalu berada tepat di antara write sebelum
+// dan sesudahnya pada key itu. fsync tidak ditunggu.
+func (e *Engine) expired(db int, key string) {
+	e.appendAOF([]persistence.Entry{{DB: db, Args: []string{"DEL", key}}})
+	e.notify(notifyExpired, "expired", db, key)
+}
 
-		seconds, err := strconv.ParseInt(cmd.Args[1], 10, 64)
-		if err != nil || seconds <= 0 {
-			return "ERR invalid TTL"
-		}
+// evicted seperti expired untuk key yang dihapus eviction; DEL-nya
+// di-publish sebagai event "evicted".
+func (e *Engine) evicted(db int, key string) {
+	e.appendAOF([]persistence.Entry{{DB: db, Args: []string{"DEL", key}}})
+	e.notify(notifyEvicted, "evicted", db, key)
+}
 
-		expireAt := time.Now().Unix() + seconds
-		ok := e.store.ExpireAt(db, cmd.Args[0], expireAt)
-		if !ok {
-			return "(nil)"
-		}
+// lookup memvalidasi argv dan mengembalikan command-nya, atau reply error.
+func (e *Engine) lookup(db int, args []string) (*Command, Reply) {
+	if len(args) == 0 {
+		return nil, Error("ERR empty command")
+	}
 
-		if persist {
-			e.aof.Write(fmt.Sprintf("EXPIREAT %d %s %d", db, cmd.Args[0], expireAt))
-		}
-		return "OK"
+	if db < 0 || db >= e.store.DBCount() {
+		return nil, Error("ERR DB index is out of range")
+	}
 
-	case "EXPIREAT":
-		if len(cmd.Args) < 3 {
-			return "ERR EXPIREAT requires db key timestamp"
-		}
+	cmd, ok := LookupCommand(args[0])
+	if !ok {
+		return nil, Errorf("ERR unknown command '%s'", args[0])
+	}
 
-		dbi, err := strconv.Atoi(cmd.Args[0])
-		if err != nil {
-			return "ERR invalid DB"
-		}
+	if !cmd.CheckArity(len(args)) {
+		return nil, wrongArgs(cmd.Name)
+	}
 
-		timestamp, err := strconv.ParseInt(cmd.Args[2], 10, 64)
-		if err != nil {
-			return "ERR invalid timestamp"
-		}
+	if cmd.Handler == nil {
+		return nil, Errorf("ERR '%s' must be sent by a client connection", strings.ToLower(cmd.Name))
+	}
+	return cmd, Reply{}
+}
 
-		e.store.ExpireAt(dbi, cmd.Args[1], timestamp)
-		return "OK"
+// run menjalankan handler lalu mengembalikan entry AOF hasil command.
+// Key yang tersentuh write dinaikkan versinya untuk WATCH.
+func (e *Engine) run(ctx *Context, cmd *Command, args []string) (Reply, []persistence.Entry) {
+	ctx.existed = e.notifyBefore(ctx.store, ctx.DB, cmd, args)
+	reply := cmd.Handler(ctx, args)
 
-	case "TTL":
-		if len(cmd.Args) < 1 {
-			return "ERR TTL requires key"
-		}
+	if !cmd.Has(FlagWrite) || reply.IsError() || ctx.noPropagate {
+		return reply, nil
+	}
 
-		ttl := e.store.TTL(db, cmd.Args[0])
-		return strconv.FormatInt(ttl, 10)
+	propagate := ctx.propagate
+	if propagate == nil {
+		propagate = [][]string{args}
+	}
 
-	case "PERSIST":
-		if len(cmd.Args) < 1 {
-			return "ERR PERSIST requires key"
+	entries := make([]persistence.Entry, 0, len(propagate))
+	for _, p := range propagate {
+		if c, ok := LookupCommand(p[0]); ok {
+			ctx.store.Touch(ctx.DB, c.Keys(p)...)
+			e.notifyWrite(ctx.store, ctx.DB, cmd, c, p, ctx.existed)
 		}
 
-		ok := e.store.Persist(db, cmd.Args[0])
-		if !ok {
-			return "0"
-		}
+		line := make([]string, len(p))
+		line[0] = strings.ToUpper(p[0])
+		copy(line[1:], p[1:])
+		entries = append(entries, persistence.Entry{DB: ctx.DB, Args: line})
+	}
+	return reply, entries
+}
 
-		if persist {
-			e.aof.Write(fmt.Sprintf("PERSIST %d %s", db, cmd.Args[0]))
-		}
-		return "1"
+// appendAOF mengantrikan entries ke AOF tanpa menunggu fsync. Pemanggil
+// wajib memegang lock shard milik key di entries agar urutannya terjaga.
+func (e *Engine) appendAOF(entries []persistence.Entry) persistence.Pending {
+	if len(entries) == 0 {
+		return persistence.Pending{}
+	}
+	return e.aof.Append(entries)
+}
 
-	case "KEYS":
-		if len(cmd.Args) < 1 {
-			return "ERR KEYS requires pattern"
-		}
+func waitAOF(p persistence.Pending) {
+	if err := p.Wait(); err != nil {
+		log.Println("aof write error:", err)
+	}
+}
 
-		if cmd.Args[0] != "*" {
-			return "ERR only '*' pattern supported"
-		}
+// BackgroundRewriteAOF memulai rewrite AOF di goroutine terpisah. Hanya
+// satu rewrite yang boleh berjalan; ErrRewriteInProgress dikembalikan
+// bila masih ada rewrite lain.
+func (e *Engine) BackgroundRewriteAOF() error {
+	if !e.rewriting.CompareAndSwap(false, true) {
+		return persistence.ErrRewriteInProgress
+	}
 
-		keys := e.store.Keys(db)
-		if len(keys) == 0 {
-			return "(nil)"
+	go func() {
+		defer e.rewriting.Store(false)
+		if err := e.RewriteAOF(); err != nil {
+			log.Println("aof rewrite failed:", err)
 		}
-
-		return strings.Join(keys, "\n")
-
-	case "BGREWRITEAOF":
-		go e.RewriteAOF()
-		return "OK"
-
-	case "INFO":
-		return e.Info()
-
-	case "HELP":
-		return strings.Join([]string{
-			"SET key value",
-			"GET key",
-			"DEL key",
-			"EXPIRE key seconds",
-			"TTL key",
-			"PERSIST key",
-			"BGREWRITEAOF",
-			"INFO",
-			"SELECT db",
-			"ACL WHOAMI",
-			"ACL LIST",
-			"ACL CAT",
-			"AUTH username password",
-			"KEYS *",
-			"LOGOUT",
-			"EXIT",
-		}, "\n")
-
-	default:
-		return "ERR unknown command"
-	}
+	}()
+	return nil
 }
 
-func (e *Engine) RewriteAOF() string {
-	snapshot := e.store.Snapshot()
-	if err := e.aof.Rewrite(snapshot); err != nil {
-		return "ERR rewrite failed"
+// RewriteAOF menulis ulang AOF dari snapshot dataset. Snapshot ditandai
+// bersamaan dengan awal rewrite buffer selagi semua shard terkunci sesaat,
+// sehingga setiap write tercatat tepat sekali: di snapshot atau di rewrite
+// buffer. Setelah itu shard disalin satu per satu (copy-on-write) tanpa
+// menahan command lain.
+func (e *Engine) RewriteAOF() error {
+	snapshot, err := e.store.BeginSnapshot(e.aof.BeginRewrite)
+	if err != nil {
+		return err
 	}
-	return "OK"
+	defer snapshot.Close()
+
+	return e.aof.Rewrite(snapshot)
 }
 
 func (e *Engine) Shutdown() {
+	e.store.Close()
 	if e.aof != nil {
 		e.aof.Sync()
 		e.aof.Close()
 	}
 }
 
+// PubSub mengembalikan hub pub/sub yang juga menerima keyspace
+// notification.
+func (e *Engine) PubSub() *pubsub.Hub {
+	return e.pubsub
+}
+
 func (e *Engine) DBCount() int {
 	return e.store.DBCount()
 }
 
+// KeyType mengembalikan nama tipe value ("string", "hash", ...) atau
+// "none" bila key tidak ada.
+func (e *Engine) KeyType(db int, key string) string {
+	return keyType(e.store, db, key)
+}
+
+func keyType(store *storage.MemoryStore, db int, key string) string {
+	t, ok := store.Type(db, key)
+	if !ok {
+		return "none"
+	}
+	return t.String()
+}
+
+// typeMatches memeriksa tipe key untuk filter TYPE pada SCAN tanpa
+// menghapus key expired maupun mencatatnya sebagai akses.
+func typeMatches(store *storage.MemoryStore, db int, key, typ string) bool {
+	t, ok := store.TypeOf(db, key)
+	return ok && t.String() == typ
+}
+
 func (e *Engine) Keys(db int) []string {
 	return e.store.Keys(db)
 }
+
+// KeyStats mengembalikan distribusi tipe dan top key terbesar di db untuk
+// admin API.
+func (e *Engine) KeyStats(db, top int) storage.DBKeyStats {
+	return e.store.KeyStats(db, top)
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// Benchmark ini menunjukkan skala throughput command write terhadap
+// GOMAXPROCS, mis.:
+//
+//	go test ./internal/engine -run '^$' -bench Write -cpu 1,2,4,8
+//
+// Write hanya mengunci shard milik key-nya, jadi ns/op pada key yang
+// tersebar di banyak shard seharusnya turun seiring bertambahnya CPU,
+// sedangkan write ke satu key tetap terserialkan.
+
+func benchEngine(b *testing.B) *Engine {
+	b.Helper()
+
+	e := New(testConfig(b.TempDir(), "no"))
+	b.Cleanup(e.Shutdown)
+	return e
+}
+
+// writeParallel menjalankan SET dari banyak goroutine; key(i) menentukan
+// key yang ditulis pada iterasi ke-i.
+func writeParallel(b *testing.B, key func(i int) string) {
+	e := benchEngine(b)
+	var seed atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seed.Add(1)) * 7919
+		args := []string{"SET", "", "value"}
+		for pb.Next() {
+			args[1] = key(i)
+			if r := e.Execute(0, args); r.IsError() {
+				b.Error(r.Str)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkWriteParallel(b *testing.B) {
+	writeParallel(b, func(i int) string {
+		return "key:" + strconv.Itoa(i&0xffff)
+	})
+}
+
+func BenchmarkWriteParallelSameKey(b *testing.B) {
+	writeParallel(b, func(int) string {
+		return "key"
+	})
+}
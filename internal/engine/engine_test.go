@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMaxMemory(t *testing.T) {
+	oom := Error("OOM command not allowed when used memory > 'maxmemory'.")
+
+	t.Run("noeviction", func(t *testing.T) {
+		cfg := testConfig(t.TempDir(), "no")
+		cfg.Memory.MaxMemory = "1kb"
+		e := New(cfg)
+		t.Cleanup(e.Shutdown)
+
+		for i := 0; ; i++ {
+			if i > 1000 {
+				t.Fatal("SET never hit maxmemory")
+			}
+			if got := e.Execute(0, []string{"SET", "k" + strconv.Itoa(i), "v"}); got.IsError() {
+				if !reflect.DeepEqual(got, oom) {
+					t.Fatalf("SET over maxmemory = %v, want %v", got, oom)
+				}
+				break
+			}
+		}
+
+		// command tanpa FlagDenyOOM tetap jalan: read dan DEL
+		execCases(t, e, []execCase{
+			{[]string{"GET", "k0"}, Bulk("v")},
+			{[]string{"APPEND", "k0", "x"}, oom},
+			{[]string{"DEL", "k0"}, Integer(1)},
+		})
+	})
+
+	t.Run("allkeys-lru", func(t *testing.T) {
+		cfg := testConfig(t.TempDir(), "no")
+		cfg.Memory.MaxMemory = "4kb"
+		cfg.Memory.MaxMemoryPolicy = "allkeys-lru"
+		e := New(cfg)
+
+		for i := 0; i < 200; i++ {
+			mustExec(t, e, "SET", "k"+strconv.Itoa(i), "v")
+		}
+		// eviction berjalan sebelum write, jadi write terakhir boleh
+		// melewati batas sebesar key-nya sendiri
+		last := mustExec(t, e, "MEMORY", "USAGE", "k199")
+		if used := e.store.UsedMemory(); used > 4<<10+last.Int {
+			t.Fatalf("used memory %d is above maxmemory", used)
+		}
+		size := mustExec(t, e, "DBSIZE")
+		if size.Int >= 200 {
+			t.Fatalf("DBSIZE = %d, want keys to be evicted", size.Int)
+		}
+
+		// key yang di-evict ditulis sebagai DEL ke AOF
+		e = restart(t, e, cfg)
+		if got := mustExec(t, e, "DBSIZE"); !reflect.DeepEqual(got, size) {
+			t.Fatalf("DBSIZE after restart = %v, want %v", got, size)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package engine
+
+import "errors"
+
+var (
+	errSyntax        = errors.New("ERR syntax error")
+	errInvalidCursor = errors.New("ERR invalid cursor")
+	errDBRange       = errors.New("ERR DB index is out of range")
+
+	errTimeoutNotFloat = errors.New("ERR timeout is not a float or out of range")
+	errTimeoutNegative = errors.New("ERR timeout is negative")
+)
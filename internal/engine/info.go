@@ -4,20 +4,107 @@ import (
 	"fmt"
 	"runtime"
 	"time"
+
+	"ferrodb/internal/storage"
 )
 
+const Version = "0.5.1"
+
 func (e *Engine) Info() string {
+	return e.info(e.store)
+}
+
+// info membangun INFO dari store, atau dari view milik EXEC yang sedang
+// memegang semua shard.
+func (e *Engine) info(store *storage.MemoryStore) string {
 	uptime := time.Since(e.startTime).Seconds()
+	exp := store.ExpireStats()
+	mem := store.MemoryStats()
+	aof := e.aof.Stats()
+	rw := e.aof.RewriteStats()
 
 	return fmt.Sprintf(
-		"FerroDB v0.3.0\n"+
+		"FerroDB v%s\n"+
 			"uptime_seconds: %.0f\n"+
 			"keys: %d\n"+
+			"used_memory: %d\n"+
+			"maxmemory: %d\n"+
+			"maxmemory_policy: %s\n"+
+			"evicted_keys: %d\n"+
+			"expires: %d\n"+
+			"expired_keys: %d\n"+
+			"expired_stale_perc: %.2f\n"+
+			"expired_time_cap_reached_count: %d\n"+
+			"expire_cycle_cpu_milliseconds: %d\n"+
+			"expire_cycle_last_usec: %d\n"+
+			"aof_fsync_policy: %s\n"+
+			"aof_last_fsync_time: %d\n"+
+			"aof_fsync_lag_ms: %d\n"+
+			"aof_pending_bytes: %d\n"+
+			"aof_last_fsync_usec: %d\n"+
+			"aof_max_fsync_usec: %d\n"+
+			"aof_delayed_fsync: %d\n"+
+			"aof_fsync_errors: %d\n"+
+			"aof_rewrite_in_progress: %d\n"+
+			"aof_rewrite_buffer_bytes: %d\n"+
+			"aof_current_rewrite_time_sec: %d\n"+
+			"aof_last_rewrite_time_sec: %d\n"+
+			"aof_last_bgrewrite_status: %s\n"+
+			"aof_rewrites: %d\n"+
 			"goroutines: %d\n"+
 			"go_version: %s",
+		Version,
 		uptime,
-		e.store.Size(),
+		store.Size(),
+		mem.Used,
+		mem.MaxMemory,
+		mem.Policy,
+		mem.EvictedKeys,
+		exp.VolatileKeys,
+		exp.ExpiredKeys,
+		exp.StalePercent,
+		exp.TimeCapReached,
+		exp.CycleTime.Milliseconds(),
+		exp.LastCycleTime.Microseconds(),
+		aof.Policy,
+		aof.LastFsync.Unix(),
+		aof.Lag.Milliseconds(),
+		aof.PendingBytes,
+		aof.LastLatency.Microseconds(),
+		aof.MaxLatency.Microseconds(),
+		aof.Delayed,
+		aof.Errors,
+		boolInt(rw.InProgress),
+		rw.BufferBytes,
+		durationSec(rw.Current, rw.InProgress),
+		durationSec(rw.LastDuration, rw.Rewrites > 0),
+		rewriteStatus(rw.LastStatus),
+		rw.Rewrites,
 		runtime.NumGoroutine(),
 		runtime.Version(),
 	)
 }
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// durationSec mengembalikan d dalam detik, atau -1 bila tidak ada nilainya
+// (mengikuti INFO Redis).
+func durationSec(d time.Duration, ok bool) int64 {
+	if !ok {
+		return -1
+	}
+	return int64(d.Seconds())
+}
+
+// rewriteStatus: Redis melaporkan "ok" sebelum rewrite pertama.
+func rewriteStatus(s string) string {
+	if s == "" {
+		return "ok"
+	}
+	return s
+}
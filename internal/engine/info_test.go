@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// infoFields mengurai INFO menjadi map field ke value.
+func infoFields(e *Engine) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(e.Info(), "\n") {
+		if name, value, ok := strings.Cut(line, ": "); ok {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// checkInfo membandingkan field INFO dengan nilai yang diharapkan.
+func checkInfo(t *testing.T, e *Engine, want map[string]string) {
+	t.Helper()
+
+	fields := infoFields(e)
+	for name, value := range want {
+		if got, ok := fields[name]; !ok || got != value {
+			t.Errorf("INFO %s = %q (present %v), want %q", name, got, ok, value)
+		}
+	}
+}
+
+func TestInfoMemory(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "no")
+	cfg.Memory.MaxMemory = "1mb"
+	cfg.Memory.MaxMemoryPolicy = "volatile-ttl"
+	e := New(cfg)
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "a", "v")
+	mustExec(t, e, "SET", "b", "v", "EX", "100")
+	mustExec(t, e, "SET", "c", "v")
+	mustExec(t, e, "PEXPIREAT", "c", "1")
+	mustExec(t, e, "GET", "c")
+
+	checkInfo(t, e, map[string]string{
+		"keys":             "2",
+		"maxmemory":        "1048576",
+		"maxmemory_policy": "volatile-ttl",
+		"evicted_keys":     "0",
+		"expires":          "1",
+		"expired_keys":     "1",
+	})
+	if used := infoFields(e)["used_memory"]; used == "" || used == "0" {
+		t.Fatalf("used_memory = %q", used)
+	}
+}
+
+func TestInfoAOF(t *testing.T) {
+	e := New(testConfig(t.TempDir(), "always"))
+	t.Cleanup(e.Shutdown)
+
+	mustExec(t, e, "SET", "k", "v")
+	checkInfo(t, e, map[string]string{
+		"aof_fsync_policy":  "always",
+		"aof_pending_bytes": "0",
+		"aof_fsync_lag_ms":  "0",
+		"aof_fsync_errors":  "0",
+	})
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"ferrodb/internal/persistence"
+	"ferrodb/internal/storage"
+)
+
+// WatchedKey adalah key yang di-WATCH client beserta versinya saat itu.
+type WatchedKey struct {
+	DB      int
+	Key     string
+	Version uint64
+}
+
+// Watch mencatat versi keys; EXEC batal bila salah satunya berubah.
+func (e *Engine) Watch(db int, keys []string) []WatchedKey {
+	out := make([]WatchedKey, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, WatchedKey{
+			DB:      db,
+			Key:     key,
+			Version: e.store.Watch(db, key),
+		})
+	}
+	return out
+}
+
+func (e *Engine) Unwatch(watched []WatchedKey) {
+	for _, w := range watched {
+		e.store.Unwatch(w.DB, w.Key)
+	}
+}
+
+// Tx adalah transaksi yang sedang berjalan di dalam EXEC.
+type Tx struct {
+	engine  *Engine
+	store   *storage.MemoryStore // view yang memegang semua shard
+	entries []persistence.Entry
+}
+
+// Execute menjalankan satu command antrian. Command blocking langsung
+// kembali seperti di Redis, dan baris AOF ditahan sampai EXEC selesai.
+func (tx *Tx) Execute(db int, args []string) Reply {
+	cmd, errReply := tx.engine.lookup(db, args)
+	if cmd == nil {
+		return errReply
+	}
+
+	if oom := tx.engine.freeMemory(tx.store, cmd); oom.IsError() {
+		return oom
+	}
+
+	ctx := &Context{
+		engine:  tx.engine,
+		store:   tx.store,
+		DB:      db,
+		noBlock: true,
+	}
+	reply, entries := tx.engine.run(ctx, cmd, args)
+	tx.entries = append(tx.entries, entries...)
+	return reply
+}
+
+// Exec menjalankan fn secara exclusive: tidak ada command lain yang
+// berjalan sampai fn selesai. Mengembalikan false tanpa menjalankan fn
+// bila ada key watched yang berubah. Seluruh write transaksi ditulis ke
+// AOF sebagai satu unit.
+func (e *Engine) Exec(watched []WatchedKey, fn func(tx *Tx)) bool {
+	// fsync ditunggu setelah gate dilepas
+	var pending persistence.Pending
+	defer func() { waitAOF(pending) }()
+
+	e.gate.Lock()
+	defer e.gate.Unlock()
+	store := e.store.LockAll()
+	defer store.Unlock()
+
+	for _, w := range watched {
+		if store.KeyVersion(w.DB, w.Key) != w.Version {
+			return false
+		}
+	}
+
+	tx := &Tx{engine: e, store: store}
+	fn(tx)
+	pending = e.appendAOF(tx.entries)
+	return true
+}
@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/storage"
+)
+
+// Kelas keyspace notification, mengikuti notify-keyspace-events Redis.
+const (
+	notifyKeyspace = 1 << iota // K: __keyspace@<db>__:<key>
+	notifyKeyevent             // E: __keyevent@<db>__:<event>
+	notifyGeneric              // g: del, expire, persist, ...
+	notifyString               // $
+	notifyList                 // l
+	notifySet                  // s
+	notifyHash                 // h
+	notifyZSet                 // z
+	notifyExpired              // x
+	notifyEvicted              // e
+	notifyStream               // t
+
+	notifyAll = notifyGeneric | notifyString | notifyList | notifySet |
+		notifyHash | notifyZSet | notifyExpired | notifyEvicted | notifyStream
+)
+
+var notifyFlagChars = map[rune]int{
+	'K': notifyKeyspace,
+	'E': notifyKeyevent,
+	'g': notifyGeneric,
+	'$': notifyString,
+	'l': notifyList,
+	's': notifySet,
+	'h': notifyHash,
+	'z': notifyZSet,
+	'x': notifyExpired,
+	'e': notifyEvicted,
+	't': notifyStream,
+	'A': notifyAll,
+}
+
+// parseNotifyFlags membaca string seperti "KEA" atau "Kx".
+func parseNotifyFlags(s string) (int, error) {
+	flags := 0
+	for _, c := range s {
+		f, ok := notifyFlagChars[c]
+		if !ok {
+			return 0, fmt.Errorf("invalid notify_keyspace_events flag '%c'", c)
+		}
+		flags |= f
+	}
+	return flags, nil
+}
+
+// categoryClass memetakan kategori command ke kelas notifikasi.
+var categoryClass = map[string]int{
+	"string":    notifyString,
+	"list":      notifyList,
+	"set":       notifySet,
+	"hash":      notifyHash,
+	"sortedset": notifyZSet,
+	"stream":    notifyStream,
+}
+
+// notifyEvents adalah nama event untuk command yang dipropagasi dalam
+// bentuk lain, mis. INCRBYFLOAT ditulis ke AOF sebagai SET tetapi event-nya
+// tetap incrbyfloat seperti Redis.
+var notifyEvents = map[string]string{
+	"INCRBYFLOAT":  "incrbyfloat",
+	"HINCRBYFLOAT": "hincrbyfloat",
+	"SPOP":         "spop",
+	"ZINCRBY":      "zincr",
+}
+
+// notify mem-publish event ke channel keyspace dan/atau keyevent sesuai
+// konfigurasi. Aman dipanggil dari hook store karena hanya menyentuh hub.
+func (e *Engine) notify(class int, event string, db int, key string) {
+	if e.notifyFlags&class == 0 {
+		return
+	}
+
+	dbArg := strconv.Itoa(db)
+	if e.notifyFlags&notifyKeyspace != 0 {
+		e.pubsub.Publish("__keyspace@"+dbArg+"__:"+key, event)
+	}
+	if e.notifyFlags&notifyKeyevent != 0 {
+		e.pubsub.Publish("__keyevent@"+dbArg+"__:"+event, key)
+	}
+}
+
+// notifyBefore mencatat key milik command write yang ada sebelum handler
+// dijalankan, agar notifyWrite hanya mengirim del untuk key yang memang
+// hilang karena write itu. nil bila event del tidak akan dikirim.
+func (e *Engine) notifyBefore(store *storage.MemoryStore, db int, cmd *Command, args []string) map[string]bool {
+	if !cmd.Has(FlagWrite) || e.notifyFlags&notifyGeneric == 0 ||
+		e.notifyFlags&(notifyKeyspace|notifyKeyevent) == 0 {
+		return nil
+	}
+
+	existed := make(map[string]bool)
+	for _, key := range cmd.Keys(args) {
+		if _, ok := store.TypeOf(db, key); ok {
+			existed[key] = true
+		}
+	}
+	return existed
+}
+
+// noteExisting memperbarui catatan notifyBefore untuk keys, dipakai
+// command blocking setelah menunggu.
+func (ctx *Context) noteExisting(keys []string) {
+	if ctx.existed == nil {
+		return
+	}
+	for _, key := range keys {
+		_, ok := ctx.store.TypeOf(ctx.DB, key)
+		ctx.existed[key] = ok
+	}
+}
+
+// notifyWrite menurunkan event dari bentuk propagasi command write, mis.
+// "SET k v EXAT t" menjadi set + expire; nama event mengikuti command
+// asli bila ada di notifyEvents. Key yang ada sebelum write dan hilang
+// sesudahnya (mis. LPOP elemen terakhir) ikut menghasilkan del.
+func (e *Engine) notifyWrite(store *storage.MemoryStore, db int, orig, cmd *Command, entry []string, existed map[string]bool) {
+	if e.notifyFlags&(notifyKeyspace|notifyKeyevent) == 0 {
+		return
+	}
+
+	keys := cmd.Keys(entry)
+	event := strings.ToLower(cmd.Name)
+	if name, ok := notifyEvents[orig.Name]; ok {
+		event = name
+	}
+	class, typed := categoryClass[cmd.Category]
+	if !typed {
+		class = notifyGeneric
+	}
+
+	expire := false
+	switch cmd.Name {
+	case "RENAME", "RENAMENX":
+		e.notify(notifyGeneric, "rename_from", db, entry[1])
+		e.notify(notifyGeneric, "rename_to", db, entry[2])
+		return
+	case "MOVE":
+		// move_to dan copy_to dikirim handler karena DB tujuannya lain
+		e.notify(notifyGeneric, "move_from", db, entry[1])
+		return
+	case "COPY":
+		return
+	case "UNLINK":
+		event = "del"
+	case "EXPIRE", "EXPIREAT", "PEXPIRE", "PEXPIREAT":
+		event = "expire"
+	case "MSET":
+		event = "set"
+	case "SET":
+		for _, opt := range entry[3:] {
+			expire = expire || strings.EqualFold(opt, "EXAT") || strings.EqualFold(opt, "PXAT")
+		}
+	}
+
+	for _, key := range keys {
+		e.notify(class, event, db, key)
+
+		if expire {
+			e.notify(notifyGeneric, "expire", db, key)
+		}
+
+		// write pada list/set/hash/... bisa menghapus key yang jadi kosong
+		if typed && class != notifyString && existed[key] {
+			if _, ok := store.TypeOf(db, key); !ok {
+				e.notify(notifyGeneric, "del", db, key)
+			}
+		}
+	}
+}
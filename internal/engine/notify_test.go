@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"ferrodb/internal/pubsub"
+)
+
+// drain mengambil semua pesan yang sudah di-publish ke sub.
+func drain(sub *pubsub.Subscriber) []string {
+	var out []string
+	for {
+		select {
+		case msg := <-sub.C:
+			out = append(out, msg.Channel+" "+msg.Payload)
+		default:
+			return out
+		}
+	}
+}
+
+func TestKeyspaceNotifications(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "no")
+	cfg.Engine.NotifyKeyspaceEvents = "KEA"
+	e := New(cfg)
+	t.Cleanup(e.Shutdown)
+
+	sub := pubsub.NewSubscriber(64, nil)
+	e.PubSub().PSubscribe(sub, "__keyspace@0__:*")
+	e.PubSub().PSubscribe(sub, "__keyevent@0__:*")
+
+	// events adalah pasangan key dan event yang diharapkan secara berurutan
+	cases := []struct {
+		args   []string
+		events [][2]string
+	}{
+		{[]string{"SET", "s", "v"}, [][2]string{{"s", "set"}}},
+		{[]string{"INCRBYFLOAT", "f", "1.5"}, [][2]string{{"f", "incrbyfloat"}}},
+		{[]string{"HINCRBYFLOAT", "h", "x", "1"}, [][2]string{{"h", "hincrbyfloat"}}},
+		{[]string{"RPUSH", "l", "a", "b"}, [][2]string{{"l", "rpush"}}},
+		{[]string{"LPOP", "l"}, [][2]string{{"l", "lpop"}}},
+		{[]string{"LPOP", "l"}, [][2]string{{"l", "lpop"}, {"l", "del"}}},
+		{[]string{"LPOP", "l"}, nil},
+		// del hanya untuk key yang tadinya ada
+		{[]string{"LTRIM", "t", "1", "0"}, [][2]string{{"t", "ltrim"}}},
+		{[]string{"RPUSH", "t", "a"}, [][2]string{{"t", "rpush"}}},
+		{[]string{"LTRIM", "t", "1", "0"}, [][2]string{{"t", "ltrim"}, {"t", "del"}}},
+		{[]string{"EXPIRE", "s", "100"}, [][2]string{{"s", "expire"}}},
+		{[]string{"DEL", "s", "f"}, [][2]string{{"s", "del"}, {"f", "del"}}},
+	}
+
+	for _, tc := range cases {
+		mustExec(t, e, tc.args...)
+
+		var want []string
+		for _, ev := range tc.events {
+			want = append(want,
+				"__keyspace@0__:"+ev[0]+" "+ev[1],
+				"__keyevent@0__:"+ev[1]+" "+ev[0],
+			)
+		}
+		if got := drain(sub); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: events %q, want %q", tc.args, got, want)
+		}
+	}
+}
+
+// TestKeyspaceNotificationsBlocking: key yang baru dibuat selama BLPOP
+// menunggu tetap menghasilkan del saat elemen terakhirnya di-pop.
+func TestKeyspaceNotificationsBlocking(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "no")
+	cfg.Engine.NotifyKeyspaceEvents = "Kgl"
+	e := New(cfg)
+	t.Cleanup(e.Shutdown)
+
+	sub := pubsub.NewSubscriber(64, nil)
+	e.PubSub().PSubscribe(sub, "__keyspace@0__:*")
+
+	done := make(chan Reply)
+	go func() { done <- e.Execute(0, []string{"BLPOP", "b", "5"}) }()
+	time.Sleep(50 * time.Millisecond)
+
+	mustExec(t, e, "RPUSH", "b", "x")
+	if got, want := <-done, BulkArray([]string{"b", "x"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("BLPOP = %v, want %v", got, want)
+	}
+
+	want := []string{"__keyspace@0__:b rpush", "__keyspace@0__:b lpop", "__keyspace@0__:b del"}
+	if got := drain(sub); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ReplyKind int
+
+const (
+	ReplySimple ReplyKind = iota
+	ReplyError
+	ReplyInteger
+	ReplyBulk
+	ReplyNull
+	ReplyNullArray
+	ReplyArray
+	ReplyMap
+	ReplyMulti // beberapa reply top-level berurutan, lihat Multi
+)
+
+// Reply adalah hasil typed dari sebuah command. Layer protokol (RESP atau
+// inline) yang menentukan bagaimana Reply ditulis ke client.
+type Reply struct {
+	Kind  ReplyKind
+	Str   string
+	Int   int64
+	Elems []Reply // array, atau pasangan key/value untuk map
+}
+
+func OK() Reply {
+	return Simple("OK")
+}
+
+func Simple(s string) Reply {
+	return Reply{Kind: ReplySimple, Str: s}
+}
+
+func Error(msg string) Reply {
+	return Reply{Kind: ReplyError, Str: msg}
+}
+
+func Errorf(format string, args ...any) Reply {
+	return Error(fmt.Sprintf(format, args...))
+}
+
+func errorReply(err error) Reply {
+	return Error(err.Error())
+}
+
+func Integer(n int64) Reply {
+	return Reply{Kind: ReplyInteger, Int: n}
+}
+
+func Bulk(s string) Reply {
+	return Reply{Kind: ReplyBulk, Str: s}
+}
+
+func Null() Reply {
+	return Reply{Kind: ReplyNull}
+}
+
+// NullArray adalah "*-1", dipakai mis. saat BLPOP timeout.
+func NullArray() Reply {
+	return Reply{Kind: ReplyNullArray}
+}
+
+func Array(elems ...Reply) Reply {
+	if elems == nil {
+		elems = []Reply{}
+	}
+	return Reply{Kind: ReplyArray, Elems: elems}
+}
+
+// BulkArray membuat array of bulk string dari slice.
+func BulkArray(items []string) Reply {
+	elems := make([]Reply, len(items))
+	for i, s := range items {
+		elems[i] = Bulk(s)
+	}
+	return Array(elems...)
+}
+
+// Map menerima pasangan key, value, key, value, ...
+func Map(kv ...Reply) Reply {
+	if kv == nil {
+		kv = []Reply{}
+	}
+	return Reply{Kind: ReplyMap, Elems: kv}
+}
+
+// Multi membungkus beberapa reply yang dikirim berurutan sebagai reply
+// terpisah, mis. satu konfirmasi per channel untuk SUBSCRIBE a b c.
+func Multi(replies ...Reply) Reply {
+	return Reply{Kind: ReplyMulti, Elems: replies}
+}
+
+func (r Reply) IsError() bool {
+	return r.Kind == ReplyError
+}
+
+// String merender reply untuk mode inline (telnet), mirip redis-cli.
+func (r Reply) String() string {
+	var b strings.Builder
+	r.render(&b, "")
+	return b.String()
+}
+
+func (r Reply) render(b *strings.Builder, indent string) {
+	switch r.Kind {
+	case ReplySimple, ReplyError, ReplyBulk:
+		b.WriteString(r.Str)
+	case ReplyInteger:
+		b.WriteString(strconv.FormatInt(r.Int, 10))
+	case ReplyNull, ReplyNullArray:
+		b.WriteString("(nil)")
+	case ReplyMulti:
+		for i, el := range r.Elems {
+			if i > 0 {
+				b.WriteString("\n" + indent)
+			}
+			el.render(b, indent)
+		}
+	case ReplyArray, ReplyMap:
+		if len(r.Elems) == 0 {
+			b.WriteString("(empty array)")
+			return
+		}
+
+		for i, el := range r.Elems {
+			if i > 0 {
+				b.WriteString("\n" + indent)
+			}
+			prefix := strconv.Itoa(i+1) + ") "
+			b.WriteString(prefix)
+			el.render(b, indent+strings.Repeat(" ", len(prefix)))
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// dumpKeys membaca isi keys untuk membandingkan dataset sebelum dan
+// sesudah restart.
+func dumpKeys(t *testing.T, e *Engine, n int) []string {
+	t.Helper()
+
+	var out []string
+	for k := 0; k < n; k++ {
+		id := strconv.Itoa(k)
+		out = append(out,
+			mustExec(t, e, "GET", "n:"+id).String(),
+			mustExec(t, e, "LRANGE", "l:"+id, "0", "-1").String(),
+			// field hash adalah nomor worker; HGETALL tidak berurutan
+			mustExec(t, e, "HMGET", "h:"+id, "0", "1", "2", "3", "4", "5", "6", "7").String(),
+		)
+	}
+	return out
+}
+
+// TestRewriteConcurrentWrites menjalankan BGREWRITEAOF berulang kali
+// selagi client lain menulis. Command yang tidak idempotent (INCR, RPUSH,
+// HINCRBY) akan terhitung dua kali bila satu write masuk snapshot dan
+// rewrite buffer sekaligus, atau hilang bila tidak masuk keduanya.
+func TestRewriteConcurrentWrites(t *testing.T) {
+	const (
+		workers = 8
+		ops     = 2000
+		keys    = 32
+	)
+
+	for _, fsync := range []string{"everysec", "always"} {
+		t.Run(fsync, func(t *testing.T) {
+			cfg := testConfig(t.TempDir(), fsync)
+			e := New(cfg)
+
+			var done atomic.Bool
+			var rewrites sync.WaitGroup
+			rewrites.Add(1)
+			go func() {
+				defer rewrites.Done()
+				for !done.Load() {
+					if err := e.RewriteAOF(); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < ops; i++ {
+						id := strconv.Itoa((w*ops + i) % keys)
+						e.Execute(0, []string{"INCR", "n:" + id})
+						e.Execute(0, []string{"RPUSH", "l:" + id, fmt.Sprintf("%d:%d", w, i)})
+						e.Execute(0, []string{"HINCRBY", "h:" + id, strconv.Itoa(w), "1"})
+					}
+				}(w)
+			}
+			wg.Wait()
+			done.Store(true)
+			rewrites.Wait()
+
+			if n := e.aof.RewriteStats().Rewrites; n == 0 {
+				t.Fatal("no rewrite completed")
+			}
+
+			before := dumpKeys(t, e, keys)
+			after := dumpKeys(t, restart(t, e, cfg), keys)
+			if !reflect.DeepEqual(before, after) {
+				t.Fatal("dataset after restart differs from dataset before restart")
+			}
+		})
+	}
+}
+
+// TestRewriteCrashLeftover mensimulasikan crash di tengah rewrite: file
+// sementara yang tertinggal tidak boleh mempengaruhi startup, dan rewrite
+// berikutnya menimpanya.
+func TestRewriteCrashLeftover(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "everysec")
+	e := New(cfg)
+	mustExec(t, e, "RPUSH", "l:0", "a", "b")
+	mustExec(t, e, "INCR", "n:0")
+
+	tmp := cfg.AOFPath() + ".rewrite.tmp"
+	if err := os.WriteFile(tmp, []byte("*1\r\n$3\r\nSET"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := dumpKeys(t, e, 1)
+	e.Shutdown()
+	e = New(cfg)
+	if got := dumpKeys(t, e, 1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after crash leftover: %q, want %q", got, want)
+	}
+
+	if err := e.RewriteAOF(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("temp file still exists after rewrite: %v", err)
+	}
+	if got := dumpKeys(t, restart(t, e, cfg), 1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after rewrite: %q, want %q", got, want)
+	}
+}
+
+// TestRewriteFailureKeepsAOF memastikan rewrite yang gagal sebelum rename
+// tidak menyentuh AOF lama, dan write sesudahnya tetap masuk ke sana.
+func TestRewriteFailureKeepsAOF(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "always")
+	e := New(cfg)
+	mustExec(t, e, "INCR", "n:0")
+
+	// file sementara tidak bisa dibuat karena path-nya direktori
+	tmp := cfg.AOFPath() + ".rewrite.tmp"
+	if err := os.Mkdir(tmp, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := os.ReadFile(cfg.AOFPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RewriteAOF(); err == nil {
+		t.Fatal("rewrite succeeded, want error")
+	}
+	after, err := os.ReadFile(cfg.AOFPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Fatal("failed rewrite changed the AOF")
+	}
+	if st := e.aof.RewriteStats(); st.LastStatus != "err" || st.InProgress {
+		t.Fatalf("rewrite stats = %+v, want finished with status err", st)
+	}
+
+	mustExec(t, e, "INCR", "n:0")
+	if got := mustExec(t, restart(t, e, cfg), "GET", "n:0"); !reflect.DeepEqual(got, Bulk("2")) {
+		t.Fatalf("GET n:0 = %v, want 2", got)
+	}
+}
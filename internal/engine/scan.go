@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/glob"
+	"ferrodb/internal/storage"
+)
+
+// scanOptions adalah opsi bersama keluarga *SCAN.
+type scanOptions struct {
+	cursor uint64
+	match  string
+	count  int
+}
+
+// parseScanArgs membaca "cursor [MATCH pattern] [COUNT count]" mulai dari
+// args[start].
+func parseScanArgs(args []string, start int) (scanOptions, error) {
+	opts := scanOptions{count: 10}
+
+	cursor, err := strconv.ParseUint(args[start], 10, 64)
+	if err != nil {
+		return opts, errInvalidCursor
+	}
+	opts.cursor = cursor
+
+	for i := start + 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return opts, errSyntax
+		}
+
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			opts.match = args[i+1]
+		case "COUNT":
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return opts, storage.ErrNotInteger
+			}
+			if n < 1 {
+				return opts, errSyntax
+			}
+			opts.count = n
+		default:
+			return opts, errSyntax
+		}
+	}
+	return opts, nil
+}
+
+// parseKeyScanArgs membaca argumen SCAN: opsi *SCAN ditambah TYPE.
+func parseKeyScanArgs(args []string) (scanOptions, string, error) {
+	rest := args[:2:2]
+	typ := ""
+
+	for i := 2; i < len(args); i += 2 {
+		if i+1 < len(args) && strings.EqualFold(args[i], "TYPE") {
+			typ = strings.ToLower(args[i+1])
+			continue
+		}
+		rest = append(rest, args[i:min(i+2, len(args))]...)
+	}
+
+	opts, err := parseScanArgs(rest, 1)
+	return opts, typ, err
+}
+
+// matches memeriksa MATCH; pattern kosong berarti semua cocok.
+func (o scanOptions) matches(s string) bool {
+	if o.match == "" || o.match == "*" {
+		return true
+	}
+	return glob.Match(o.match, s)
+}
+
+func scanReply(next uint64, items []Reply) Reply {
+	return Array(
+		Bulk(strconv.FormatUint(next, 10)),
+		Array(items...),
+	)
+}
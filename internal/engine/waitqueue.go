@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+type waitKey struct {
+	db  int
+	key string
+}
+
+// waiter adalah satu client yang sedang parkir di command blocking.
+type waiter struct {
+	ch chan struct{}
+}
+
+// waitQueue mencatat client yang menunggu key tertentu terisi. Command
+// yang menambah data (LPUSH, LMOVE, ...) memanggil signal setelah write,
+// lalu waiter mencoba ulang operasinya.
+type waitQueue struct {
+	mu      sync.Mutex
+	waiters map[waitKey][]*waiter
+}
+
+func newWaitQueue() *waitQueue {
+	return &waitQueue{waiters: map[waitKey][]*waiter{}}
+}
+
+func (q *waitQueue) register(db int, keys []string) *waiter {
+	w := &waiter{ch: make(chan struct{}, 1)}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, k := range keys {
+		wk := waitKey{db, k}
+		q.waiters[wk] = append(q.waiters[wk], w)
+	}
+	return w
+}
+
+func (q *waitQueue) unregister(db int, keys []string, w *waiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, k := range keys {
+		wk := waitKey{db, k}
+		list := q.waiters[wk]
+		for i, other := range list {
+			if other == w {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+
+		if len(list) == 0 {
+			delete(q.waiters, wk)
+		} else {
+			q.waiters[wk] = list
+		}
+	}
+}
+
+// signal membangunkan semua waiter milik key sesuai urutan datang.
+func (q *waitQueue) signal(db int, key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, w := range q.waiters[waitKey{db, key}] {
+		select {
+		case w.ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// signalDB membangunkan semua waiter di db, mis. setelah SWAPDB.
+func (q *waitQueue) signalDB(db int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for wk, list := range q.waiters {
+		if wk.db != db {
+			continue
+		}
+		for _, w := range list {
+			select {
+			case w.ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// block menjalankan try sampai berhasil, timeout (0 = selamanya), atau
+// koneksi client putus. try dipanggil ulang setiap ada signal pada keys.
+func (ctx *Context) block(keys []string, timeout time.Duration, try func() (Reply, bool)) Reply {
+	if r, ok := try(); ok || ctx.noBlock {
+		if ok {
+			return r
+		}
+		return NullArray()
+	}
+
+	q := ctx.engine.waits
+	w := q.register(ctx.DB, keys)
+	defer q.unregister(ctx.DB, keys, w)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	for {
+		// dicoba lagi setelah register agar push yang terjadi di antara
+		// percobaan pertama dan register tidak terlewat
+		if r, ok := try(); ok {
+			return r
+		}
+
+		if !ctx.wait(w.ch, expired) {
+			return NullArray()
+		}
+		// key bisa dibuat client lain selama menunggu
+		ctx.noteExisting(keys)
+	}
+}
+
+// wait parkir sampai ada signal (true), atau timeout/client putus (false).
+// Semua lock di ctx.held dilepas selama menunggu supaya write lain dan
+// EXEC tidak tertahan client blocking, lalu diambil lagi dengan urutan
+// yang sama seperti saat pertama kali dikunci.
+func (ctx *Context) wait(ch <-chan struct{}, expired <-chan time.Time) bool {
+	for i := len(ctx.held) - 1; i >= 0; i-- {
+		ctx.held[i].Unlock()
+	}
+	defer func() {
+		for _, l := range ctx.held {
+			l.Lock()
+		}
+	}()
+
+	select {
+	case <-ch:
+		return true
+	case <-expired:
+		return false
+	case <-ctx.done:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+// Package glob mengimplementasikan pattern glob gaya Redis (stringmatch)
+// yang dipakai KEYS, SCAN MATCH, PSUBSCRIBE dan ACL key pattern.
+//
+//	?       tepat satu karakter
+//	*       nol atau lebih karakter
+//	[abc]   salah satu karakter; [^a] negasi; [a-z] range
+//	\x      karakter x apa adanya
+//
+// Pencocokan dilakukan per byte, sama seperti Redis.
+package glob
+
+// Match melaporkan apakah s cocok dengan pattern.
+func Match(pattern, s string) bool {
+	// backtracking hanya ke '*' terakhir: cukup karena wildcard lain
+	// selalu mengonsumsi tepat satu karakter, dan menghindari ledakan
+	// eksponensial pada pattern seperti "a*a*a*a*b"
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				for p < len(pattern) && pattern[p] == '*' {
+					p++
+				}
+				if p == len(pattern) {
+					return true
+				}
+				starP, starI = p, i
+				continue
+
+			case '?':
+				p++
+				i++
+				continue
+
+			case '[':
+				if next, ok := matchClass(pattern, p, s[i]); ok {
+					p = next
+					i++
+					continue
+				}
+
+			case '\\':
+				if p+1 < len(pattern) {
+					if pattern[p+1] == s[i] {
+						p += 2
+						i++
+						continue
+					}
+					break
+				}
+				// '\' di akhir pattern dicocokkan sebagai karakter biasa
+				fallthrough
+
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		if starP < 0 {
+			return false
+		}
+		starI++
+		p, i = starP, starI
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchClass mencocokkan c dengan class yang dimulai di pattern[start]
+// ('['). Mengembalikan posisi setelah ']' dan apakah c cocok. Class tanpa
+// ']' penutup dianggap berakhir di ujung pattern, seperti Redis.
+func matchClass(pattern string, start int, c byte) (int, bool) {
+	p := start + 1
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			p++
+			if pattern[p] == c {
+				matched = true
+			}
+			p++
+
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			lo, hi := pattern[p], pattern[p+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			p += 3
+
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+			p++
+		}
+	}
+
+	if p < len(pattern) {
+		p++ // lewati ']'
+	}
+	return p, matched != negate
+}
@@ -0,0 +1,73 @@
+package glob
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "hllo", true},
+		{"h*llo", "heeeello", true},
+		{"h*llo", "hello!", false},
+		{"*:*:x", "a:b:c:x", true},
+
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{"h[c-a]llo", "hbllo", true}, // range terbalik tetap berlaku
+		{"[^a-c]x", "dx", true},
+		{"[^a-c]x", "bx", false},
+		{"key[0-9]", "key7", true},
+		{"key[0-9]", "keyx", false},
+		{"[abc", "b", true}, // class tanpa ']' berakhir di ujung pattern
+
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`\?`, "?", true},
+		{`\?`, "a", false},
+		{`\[a]`, "[a]", true},
+		{`[\]]`, "]", true},
+		{`[\^a]`, "^", true},
+		{`[^\]]`, "]", false},
+		{`a\`, `a\`, true},
+
+		// per byte, bukan per rune
+		{"?", "é", false},
+		{"??", "é", true},
+	}
+
+	for _, tc := range cases {
+		if got := Match(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+// TestMatchManyStars memastikan pattern dengan banyak '*' tidak
+// backtracking secara eksponensial.
+func TestMatchManyStars(t *testing.T) {
+	pattern := strings.Repeat("a*", 30) + "b"
+	s := strings.Repeat("a", 10000)
+
+	start := time.Now()
+	if Match(pattern, s) {
+		t.Fatal("pattern matched a string without 'b'")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Match took %v", d)
+	}
+}
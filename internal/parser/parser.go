@@ -1,20 +1,127 @@
 package parser
 
-import "strings"
+import "errors"
 
-type Command struct {
-	Name string
-	Args []string
+var ErrUnbalancedQuotes = errors.New("unbalanced quotes in request")
+
+// SplitArgs memecah baris seperti redis-cli: argumen dipisah whitespace,
+// "..." mendukung escape (\n, \r, \t, \a, \b, \xHH, \", \\) dan '...'
+// hanya mendukung \'.
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	i := 0
+
+	for {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i >= len(line) {
+			return args, nil
+		}
+
+		var (
+			cur     []byte
+			inDQ    bool
+			inSQ    bool
+			started bool
+		)
+
+		for !started || inDQ || inSQ || (i < len(line) && !isSpace(line[i])) {
+			started = true
+
+			if i >= len(line) {
+				// quote belum ditutup
+				return nil, ErrUnbalancedQuotes
+			}
+
+			c := line[i]
+			switch {
+			case inDQ:
+				switch {
+				case c == '\\' && i+3 < len(line) && line[i+1] == 'x' &&
+					isHex(line[i+2]) && isHex(line[i+3]):
+					cur = append(cur, fromHex(line[i+2])<<4|fromHex(line[i+3]))
+					i += 3
+				case c == '\\' && i+1 < len(line):
+					i++
+					cur = append(cur, unescape(line[i]))
+				case c == '"':
+					// closing quote harus diikuti spasi atau akhir baris
+					if i+1 < len(line) && !isSpace(line[i+1]) {
+						return nil, ErrUnbalancedQuotes
+					}
+					inDQ = false
+				default:
+					cur = append(cur, c)
+				}
+
+			case inSQ:
+				switch {
+				case c == '\\' && i+1 < len(line) && line[i+1] == '\'':
+					i++
+					cur = append(cur, '\'')
+				case c == '\'':
+					if i+1 < len(line) && !isSpace(line[i+1]) {
+						return nil, ErrUnbalancedQuotes
+					}
+					inSQ = false
+				default:
+					cur = append(cur, c)
+				}
+
+			default:
+				switch c {
+				case '"':
+					inDQ = true
+				case '\'':
+					inSQ = true
+				default:
+					cur = append(cur, c)
+				}
+			}
+			i++
+		}
+
+		args = append(args, string(cur))
+	}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\n', '\r', '\t', '\v', '\f':
+		return true
+	}
+	return false
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
 }
 
-func Parse(input string) Command {
-	parts := strings.Fields(input)
-	if len(parts) == 0 {
-		return Command{}
+func fromHex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
 	}
+}
 
-	return Command{
-		Name: strings.ToUpper(parts[0]),
-		Args: parts[1:],
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return c
 	}
 }
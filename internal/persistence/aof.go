@@ -1,115 +1,333 @@
 package persistence
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
 	"ferrodb/internal/storage"
 )
 
 type AOF struct {
-	file *os.File
+	mu     sync.Mutex // melindungi file dan urutan write
+	syncMu sync.Mutex // satu fsync dalam satu waktu; diambil sebelum mu
+	path   string
+	file   *os.File
+	db     int    // DB yang terakhir di-SELECT di file, -1 = belum diketahui
+	buf    []byte // buffer encode untuk policy selain always
+
+	policy FsyncPolicy
+	stats  fsyncStats
+	stop   chan struct{}
+	done   chan struct{}
+
+	// group commit untuk policy always, lihat group.go
+	batch  *batch
+	wake   chan struct{}
+	closed bool
+
+	rw rewriteState // lihat rewrite.go
 }
 
-func OpenAOF(path string) (*AOF, error) {
+// OpenAOF membuka (atau membuat) file AOF. File format lama dimigrasi
+// lebih dulu, lihat migrateLegacy. Untuk policy everysec dan always,
+// goroutine fsync/flusher berjalan sampai Close.
+func OpenAOF(path string, policy FsyncPolicy) (*AOF, error) {
 	// pastikan directory ada
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return nil, err
 	}
 
+	if err := migrateLegacy(path); err != nil {
+		return nil, fmt.Errorf("aof migration failed: %w", err)
+	}
+
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AOF{file: file}, nil
+	// file baru langsung diberi header
+	if info, err := file.Stat(); err != nil || info.Size() == 0 {
+		if err == nil {
+			_, err = file.WriteString(header())
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	a := &AOF{
+		path:   path,
+		file:   file,
+		db:     -1,
+		policy: policy,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		batch:  newBatch(),
+		wake:   make(chan struct{}, 1),
+	}
+	a.stats.lastFsync.Store(time.Now().UnixNano())
+
+	switch policy {
+	case FsyncEverySec:
+		go a.fsyncLoop()
+	case FsyncAlways:
+		go a.flushLoop()
+	default:
+		close(a.done)
+	}
+	return a, nil
 }
 
-func (a *AOF) Write(command string) error {
-	_, err := a.file.WriteString(command + "\n")
-	return err
+// Pending adalah write yang sudah mendapat urutan di AOF tetapi, dengan
+// policy always, belum tentu sudah di-fsync.
+type Pending struct {
+	b   *batch
+	err error
 }
 
-func (a *AOF) Replay(apply func(string)) error {
-	file, err := os.Open(a.file.Name())
-	if err != nil {
-		return err
+// Wait menunggu sampai write durable sesuai policy dan mengembalikan
+// error-nya.
+func (p Pending) Wait() error {
+	if p.b == nil {
+		return p.err
 	}
-	defer file.Close()
+	<-p.b.done
+	return p.b.err
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-		apply(line)
-	}
-	return scanner.Err()
+// Write menulis satu command yang dijalankan di db ke AOF.
+func (a *AOF) Write(db int, args ...string) error {
+	return a.write([]Entry{{DB: db, Args: args}}, false).Wait()
 }
 
-func (a *AOF) Rewrite(snapshot map[int]map[string]storage.Item) error {
-	tmpPath := a.file.Name() + ".tmp"
+// WriteBatch menulis beberapa command sebagai satu unit atomic yang
+// dibungkus MULTI/EXEC dalam satu write. Replay hanya menerapkan unit
+// yang lengkap sehingga crash tidak pernah menyisakan setengah transaksi.
+func (a *AOF) WriteBatch(entries []Entry) error {
+	return a.write(entries, true).Wait()
+}
 
-	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
+// Append seperti Write/WriteBatch (lebih dari satu entry dibungkus
+// MULTI/EXEC) tetapi tidak menunggu fsync. Urutan di file sama dengan
+// urutan panggilan Append, sehingga pemanggil bisa menentukan urutan
+// selagi memegang lock-nya sendiri lalu memanggil Wait setelah lock
+// dilepas.
+func (a *AOF) Append(entries []Entry) Pending {
+	return a.write(entries, len(entries) > 1)
+}
+
+// write meng-encode entries lalu menambahkannya ke file. Dengan policy
+// always, data masuk batch group commit dan Pending baru selesai setelah
+// batch di-fsync sehingga reply tidak pernah mendahului disk.
+func (a *AOF) write(entries []Entry, multi bool) Pending {
+	if a.policy == FsyncAlways {
+		return a.enqueue(entries, multi)
 	}
 
-	writer := bufio.NewWriter(tmpFile)
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	for db, kv := range snapshot {
-		for key, item := range kv {
-			// SET
-			if _, err := writer.WriteString(
-				fmt.Sprintf("SET %d %s %s\n", db, key, item.Value),
-			); err != nil {
-				return err
-			}
+	if a.closed {
+		return Pending{err: errClosed}
+	}
 
-			// EXPIREAT (absolute)
-			if item.ExpireAt > 0 {
-				if _, err := writer.WriteString(
-					fmt.Sprintf("EXPIREAT %d %s %d\n", db, key, item.ExpireAt),
-				); err != nil {
-					return err
-				}
-			}
-		}
+	a.buf = appendEntries(a.buf[:0], &a.db, entries, multi)
+	a.captureRewrite(entries, multi)
+
+	n, err := a.file.Write(a.buf)
+	a.written(n)
+	if err != nil {
+		// SELECT yang baru di-encode belum tentu sampai ke file
+		a.db = -1
 	}
+	return Pending{err: err}
+}
+
+// Replay membaca seluruh AOF dan memanggil apply untuk setiap command
+// beserta DB-nya. SELECT hanya mengganti DB dan tidak diteruskan; command
+// yang gagal dicatat ke log beserta offset-nya.
+//
+// Ekor file yang terpotong (crash di tengah write) dipotong bila
+// loadTruncated, selain itu replay ditolak. Isi yang rusak selalu ditolak
+// dan harus diperbaiki dengan ferrodb-check-aof.
+func (a *AOF) Replay(loadTruncated bool, apply func(db int, args []string) error) error {
+	path := a.path
 
-	if err := writer.Flush(); err != nil {
+	file, err := os.Open(path)
+	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	if err := tmpFile.Sync(); err != nil {
+	valid, err := scan(file, func(e Entry, offset int64) {
+		if err := apply(e.DB, e.Args); err != nil {
+			log.Printf("aof: %s at offset %d failed: %v", e.Args[0], offset, err)
+		}
+	})
+
+	var ferr *FormatError
+	if !errors.As(err, &ferr) {
 		return err
 	}
+	if !ferr.Truncated || !loadTruncated {
+		return fmt.Errorf("%w (run ferrodb-check-aof --fix %s to truncate it at offset %d)", err, path, valid)
+	}
 
-	if err := tmpFile.Close(); err != nil {
+	info, err := a.file.Stat()
+	if err != nil {
 		return err
 	}
+	log.Printf("!!! %v: discarding last %d bytes, AOF truncated to %d bytes", ferr, info.Size()-valid, valid)
 
-	// atomic replace
-	oldPath := a.file.Name()
-	if err := a.file.Close(); err != nil {
+	// file dibuka O_APPEND sehingga write berikutnya mulai di akhir baru
+	if err := a.file.Truncate(valid); err != nil {
 		return err
 	}
+	return a.file.Sync()
+}
 
-	if err := os.Rename(tmpPath, oldPath); err != nil {
-		return err
+// rewriteBatch membatasi jumlah elemen per command agar satu baris AOF
+// tidak terlalu besar untuk koleksi berukuran besar.
+const rewriteBatch = 64
+
+// rewriteCommands menghasilkan command minimal (tanpa arg DB) yang
+// membangun ulang satu item.
+func rewriteCommands(key string, item storage.Item) [][]string {
+	switch item.Type {
+	case storage.TypeList:
+		return batchCommands("RPUSH", key, item.List.Values())
+
+	case storage.TypeSet:
+		members := make([]string, 0, len(item.Set))
+		for mem := range item.Set {
+			members = append(members, mem)
+		}
+		return batchCommands("SADD", key, members)
+
+	case storage.TypeStream:
+		return streamCommands(key, item.Stream)
+
+	case storage.TypeZSet:
+		var cmds [][]string
+		args := []string{"ZADD", key}
+		for _, m := range item.ZSet.Members() {
+			args = append(args, storage.FormatScore(m.Score), m.Member)
+			if len(args)-2 >= rewriteBatch*2 {
+				cmds = append(cmds, args)
+				args = []string{"ZADD", key}
+			}
+		}
+		if len(args) > 2 {
+			cmds = append(cmds, args)
+		}
+		return cmds
+
+	case storage.TypeHash:
+		var cmds [][]string
+		args := []string{"HSET", key}
+		for f, v := range item.Hash {
+			args = append(args, f, v)
+			if len(args)-2 >= rewriteBatch*2 {
+				cmds = append(cmds, args)
+				args = []string{"HSET", key}
+			}
+		}
+		if len(args) > 2 {
+			cmds = append(cmds, args)
+		}
+		return cmds
+
+	default:
+		return [][]string{{"SET", key, item.Value}}
 	}
+}
 
-	a.file, err = os.OpenFile(oldPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
-	return err
+// batchCommands memecah elemen koleksi menjadi beberapa "NAME key elem..."
+// berisi maksimal rewriteBatch elemen.
+func batchCommands(name, key string, elems []string) [][]string {
+	var cmds [][]string
+	for len(elems) > 0 {
+		n := min(len(elems), rewriteBatch)
+		cmds = append(cmds, append([]string{name, key}, elems[:n]...))
+		elems = elems[n:]
+	}
+	return cmds
 }
 
+// Sync memaksa fsync data yang belum di-fsync, apa pun policy-nya.
 func (a *AOF) Sync() error {
-	return a.file.Sync()
+	if a.policy == FsyncAlways {
+		return a.flush()
+	}
+	return a.fsync()
 }
 
+// Close menghentikan goroutine fsync lalu menutup file. Batch group commit
+// yang masih terbuka di-flush lebih dulu; untuk policy lain data yang
+// belum di-fsync tidak ikut di-fsync, panggil Sync lebih dulu bila perlu.
 func (a *AOF) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return errClosed
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	<-a.done
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	return a.file.Close()
 }
+
+// streamCommands membangun ulang stream: entry, ID terakhir, lalu state
+// consumer group termasuk PEL (via XCLAIM FORCE JUSTID).
+func streamCommands(key string, s *storage.Stream) [][]string {
+	var cmds [][]string
+
+	for _, e := range s.Entries() {
+		cmds = append(cmds, append([]string{"XADD", key, e.ID.String()}, e.Fields...))
+	}
+
+	// stream kosong tetap harus ada (mis. dibuat oleh MKSTREAM)
+	if s.Len() == 0 {
+		id := s.LastID()
+		if id.IsZero() {
+			id = storage.StreamID{Seq: 1}
+		}
+		cmds = append(cmds, []string{"XADD", key, "MAXLEN", "0", id.String(), "x", "y"})
+	}
+	cmds = append(cmds, []string{
+		"XSETID", key, s.LastID().String(),
+		"ENTRIESADDED", strconv.FormatInt(s.EntriesAdded(), 10),
+	})
+
+	for _, g := range s.Groups() {
+		cmds = append(cmds, []string{"XGROUP", "CREATE", key, g.Name, g.LastID.String()})
+
+		for _, name := range g.ConsumerNames() {
+			cmds = append(cmds, []string{"XGROUP", "CREATECONSUMER", key, g.Name, name})
+		}
+
+		for _, id := range g.PendingIDs() {
+			pe := g.Pending[id]
+			cmds = append(cmds, []string{
+				"XCLAIM", key, g.Name, pe.Consumer, "0", id.String(),
+				"TIME", strconv.FormatInt(pe.DeliveredAt, 10),
+				"RETRYCOUNT", strconv.FormatInt(pe.Deliveries, 10),
+				"FORCE", "JUSTID",
+			})
+		}
+	}
+	return cmds
+}
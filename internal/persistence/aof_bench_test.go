@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Benchmark ini membandingkan fsync per write dengan group commit pada
+// jumlah client bersamaan yang sama, mis.:
+//
+//	go test ./internal/persistence -run '^$' -bench Append
+//
+// Keduanya memberi durability setara appendfsync always: write baru
+// kembali setelah datanya ter-fsync.
+
+const benchClients = 64
+
+func benchPath(b *testing.B) string {
+	b.Helper()
+	return filepath.Join(b.TempDir(), "bench.aof")
+}
+
+// appendParallel menjalankan write dari benchClients goroutine sekaligus.
+func appendParallel(b *testing.B, write func(db int, args ...string) error) {
+	var seed atomic.Int64
+
+	b.ReportAllocs()
+	b.SetParallelism(benchClients)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		key := "key:" + strconv.FormatInt(seed.Add(1), 10)
+		for pb.Next() {
+			if err := write(0, "SET", key, "value"); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkAppendFsyncPerWrite adalah pendekatan naif: tiap write
+// langsung diikuti fsync di bawah satu lock.
+func BenchmarkAppendFsyncPerWrite(b *testing.B) {
+	file, err := os.OpenFile(benchPath(b), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { file.Close() })
+
+	var mu sync.Mutex
+	appendParallel(b, func(db int, args ...string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, err := file.Write(appendCommand(nil, args...)); err != nil {
+			return err
+		}
+		return file.Sync()
+	})
+}
+
+func BenchmarkAppendGroupCommit(b *testing.B) {
+	benchAOF(b, FsyncAlways)
+}
+
+// BenchmarkAppendEverySec sebagai pembanding tanpa menunggu fsync.
+func BenchmarkAppendEverySec(b *testing.B) {
+	benchAOF(b, FsyncEverySec)
+}
+
+func benchAOF(b *testing.B, policy FsyncPolicy) {
+	aof, err := OpenAOF(benchPath(b), policy)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { aof.Close() })
+
+	appendParallel(b, aof.Write)
+}
@@ -0,0 +1,61 @@
+package persistence
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// openTestAOF membuka AOF baru di directory sementara.
+func openTestAOF(t *testing.T, policy FsyncPolicy) (*AOF, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	aof, err := OpenAOF(path, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aof, path
+}
+
+// TestAOFRoundTrip menulis lewat Write, WriteBatch dan Append dengan setiap
+// policy lalu memastikan replay menghasilkan urutan dan DB yang sama.
+func TestAOFRoundTrip(t *testing.T) {
+	want := []Entry{
+		{0, []string{"SET", "k", "v"}},
+		{3, []string{"SET", "k", "line\r\nbreak"}},
+		{3, []string{"INCR", "n"}},
+		{3, []string{"DEL", "k"}},
+		{0, []string{"RPUSH", "l", "", "x"}},
+	}
+
+	for _, policy := range []FsyncPolicy{FsyncAlways, FsyncEverySec, FsyncNo} {
+		t.Run(policy.String(), func(t *testing.T) {
+			aof, path := openTestAOF(t, policy)
+
+			if err := aof.Write(want[0].DB, want[0].Args...); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.WriteBatch(want[1:3]); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.Append(want[3:4]).Wait(); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.Append(want[4:]).Wait(); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.Write(0, "SET", "late", "v"); err == nil {
+				t.Fatal("Write after Close succeeded")
+			}
+
+			got := replayAll(t, path)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("replayed %v, want %v", got, want)
+			}
+		})
+	}
+}
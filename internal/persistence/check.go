@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// scan membaca AOF v2 dari r dan memanggil fn untuk setiap command yang
+// lengkap beserta offset record-nya; isi blok MULTI baru diteruskan
+// setelah EXEC-nya terbaca. valid adalah panjang awalan file yang utuh,
+// yaitu titik potong bila ada masalah. err berisi *FormatError untuk isi
+// yang rusak atau terpotong, atau error I/O.
+func scan(r io.Reader, fn func(e Entry, offset int64)) (valid int64, err error) {
+	rr := newRecordReader(r)
+	if _, err := rr.readHeader(); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = &FormatError{Offset: 0, Reason: "truncated header"}
+		}
+		return 0, err
+	}
+
+	type queued struct {
+		entry  Entry
+		offset int64
+	}
+	var tx []queued
+	inTx := false
+	var txStart int64
+	db := 0
+
+	// cut mengembalikan titik potong: blok MULTI yang belum selesai ikut
+	// dibuang seluruhnya
+	cut := func(offset int64) int64 {
+		if inTx {
+			return txStart
+		}
+		return offset
+	}
+
+	for {
+		valid = cut(rr.offset)
+
+		args, start, err := rr.next()
+		switch {
+		case err == io.EOF && inTx:
+			return txStart, &FormatError{Offset: txStart, Reason: "MULTI without EXEC", Truncated: true}
+		case err == io.EOF:
+			return valid, nil
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return valid, &FormatError{Offset: start, Reason: "truncated record", Truncated: true}
+		case err != nil:
+			return valid, err
+		}
+
+		switch {
+		case strings.EqualFold(args[0], "SELECT"):
+			n := -1
+			if len(args) == 2 {
+				n, err = strconv.Atoi(args[1])
+			}
+			if err != nil || n < 0 {
+				return valid, &FormatError{Offset: start, Reason: "invalid SELECT"}
+			}
+			db = n
+		case strings.EqualFold(args[0], "MULTI"):
+			if inTx {
+				return valid, &FormatError{Offset: start, Reason: "nested MULTI"}
+			}
+			tx, inTx, txStart = nil, true, start
+		case strings.EqualFold(args[0], "EXEC"):
+			if !inTx {
+				return valid, &FormatError{Offset: start, Reason: "EXEC without MULTI"}
+			}
+			for _, q := range tx {
+				fn(q.entry, q.offset)
+			}
+			tx, inTx = nil, false
+		case inTx:
+			tx = append(tx, queued{Entry{DB: db, Args: args}, start})
+		default:
+			fn(Entry{DB: db, Args: args}, start)
+		}
+	}
+}
+
+// CheckResult adalah hasil pemeriksaan file AOF.
+type CheckResult struct {
+	Size     int64
+	Valid    int64 // panjang awalan yang utuh
+	Commands int64
+	Legacy   bool  // format v1, akan dimigrasi saat server start
+	Err      error // *FormatError pertama, nil bila file utuh
+}
+
+// Check memeriksa file AOF tanpa menjalankan isinya.
+func Check(path string) (CheckResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{Size: info.Size(), Valid: info.Size()}
+
+	if res.Legacy, err = isLegacy(path); err != nil || res.Legacy || res.Size == 0 {
+		return res, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return res, err
+	}
+	defer file.Close()
+
+	res.Valid, err = scan(file, func(Entry, int64) {
+		res.Commands++
+	})
+
+	var ferr *FormatError
+	if errors.As(err, &ferr) {
+		res.Err, err = ferr, nil
+	}
+	return res, err
+}
+
+// Fix memotong file AOF di res.Valid sehingga hanya bagian yang utuh
+// yang tersisa.
+func Fix(path string, res CheckResult) error {
+	if res.Err == nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := file.Truncate(res.Valid); err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+	return file.Sync()
+}
@@ -0,0 +1,184 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var checkEntries = []Entry{
+	{0, []string{"SET", "a", "1"}},
+	{1, []string{"SET", "b", "2"}},
+}
+
+// writeAOF menulis header, checkEntries lalu tail apa adanya, dan
+// mengembalikan path serta panjang bagian yang utuh.
+func writeAOF(t *testing.T, tail string) (string, int64) {
+	t.Helper()
+
+	db := -1
+	buf := []byte(header())
+	for _, e := range checkEntries {
+		buf = appendEntries(buf, &db, []Entry{e}, false)
+	}
+	valid := int64(len(buf))
+
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	if err := os.WriteFile(path, append(buf, tail...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, valid
+}
+
+// tails adalah akhir file yang terpotong: record setengah jalan dan blok
+// MULTI tanpa EXEC, yang harus dibuang seluruhnya.
+var tails = map[string]string{
+	"record": "*3\r\n$3\r\nSET\r\n$1\r\nc\r\n$5\r\nab",
+	"bulk":   "*2\r\n$3\r\nDEL\r\n$1\r\nc\r",
+	"multi":  "*1\r\n$5\r\nMULTI\r\n*3\r\n$3\r\nSET\r\n$1\r\nc\r\n$1\r\n3\r\n",
+}
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name      string
+		tail      string
+		kept      int // awalan tail yang masih utuh
+		commands  int64
+		reason    string
+		truncated bool
+	}{
+		{"ok", "", 0, 2, "", false},
+		{"truncated record", tails["record"], 0, 2, "truncated record", true},
+		{"truncated multi", tails["multi"], 0, 2, "MULTI without EXEC", true},
+		{"corrupt", "*1\r\n$4\r\nPING\r\nxx\r\n", 14, 3, `invalid array header "xx"`, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, valid := writeAOF(t, tc.tail)
+			valid += int64(tc.kept)
+
+			res, err := Check(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Valid != valid || res.Commands != tc.commands || res.Legacy {
+				t.Fatalf("Check = %+v, want valid %d, %d commands", res, valid, tc.commands)
+			}
+			if tc.reason == "" {
+				if res.Err != nil || res.Size != valid {
+					t.Fatalf("Check = %+v, want OK", res)
+				}
+				return
+			}
+
+			var ferr *FormatError
+			if !errors.As(res.Err, &ferr) || ferr.Reason != tc.reason || ferr.Truncated != tc.truncated {
+				t.Fatalf("Check error = %v, want %q (truncated %v)", res.Err, tc.reason, tc.truncated)
+			}
+
+			// --fix memotong di titik yang sama, setelah itu file utuh
+			if err := Fix(path, res); err != nil {
+				t.Fatal(err)
+			}
+			if res, err = Check(path); err != nil || res.Err != nil || res.Size != valid {
+				t.Fatalf("Check after Fix = %+v, %v", res, err)
+			}
+			if got := replayAll(t, path); len(got) != int(tc.commands) {
+				t.Fatalf("replayed %v after Fix", got)
+			}
+		})
+	}
+}
+
+func TestCheckLegacy(t *testing.T) {
+	path := writeLegacy(t, "SET 0 k v")
+
+	res, err := Check(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Legacy || res.Err != nil {
+		t.Fatalf("Check = %+v, want legacy", res)
+	}
+	if _, err := Check(filepath.Join(t.TempDir(), "missing.aof")); err == nil {
+		t.Fatal("Check on a missing file succeeded")
+	}
+}
+
+// TestReplayTruncated: dengan loadTruncated ekor yang terpotong dibuang
+// dan write berikutnya tersambung rapi; tanpa itu replay gagal dan file
+// tidak diubah.
+func TestReplayTruncated(t *testing.T) {
+	for name, tail := range tails {
+		t.Run(name, func(t *testing.T) {
+			path, valid := writeAOF(t, tail)
+			before, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			aof, err := OpenAOF(path, FsyncNo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = aof.Replay(false, func(int, []string) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), "ferrodb-check-aof --fix") {
+				t.Fatalf("Replay(false) error = %v, want a hint to ferrodb-check-aof", err)
+			}
+			if after, _ := os.ReadFile(path); string(after) != string(before) {
+				t.Fatal("Replay(false) modified the file")
+			}
+
+			var got []Entry
+			err = aof.Replay(true, func(db int, args []string) error {
+				got = append(got, Entry{DB: db, Args: args})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, checkEntries) {
+				t.Fatalf("replayed %v, want %v", got, checkEntries)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != valid {
+				t.Fatalf("size after Replay(true) = %d, want %d", info.Size(), valid)
+			}
+
+			if err := aof.Write(1, "SET", "c", "3"); err != nil {
+				t.Fatal(err)
+			}
+			if err := aof.Close(); err != nil {
+				t.Fatal(err)
+			}
+			want := append(append([]Entry(nil), checkEntries...), Entry{1, []string{"SET", "c", "3"}})
+			if got := replayAll(t, path); !reflect.DeepEqual(got, want) {
+				t.Fatalf("replayed %v after write, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestReplayCorrupt: isi yang rusak tidak pernah dipotong otomatis.
+func TestReplayCorrupt(t *testing.T) {
+	path, _ := writeAOF(t, "*1\r\n$4\r\nEXEC\r\n")
+
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aof.Close()
+
+	err = aof.Replay(true, func(int, []string) error { return nil })
+	var ferr *FormatError
+	if !errors.As(err, &ferr) || ferr.Truncated {
+		t.Fatalf("Replay(true) error = %v, want a corrupt FormatError", err)
+	}
+}
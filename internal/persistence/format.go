@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format AOF v2: satu baris header lalu command sebagai array RESP,
+// sama seperti yang dikirim client. DB tidak ditulis per command; SELECT
+// hanya muncul saat DB berganti sehingga value apa pun (spasi, newline,
+// byte biner) tersimpan apa adanya.
+//
+//	FERRODB-AOF 2\r\n
+//	*2\r\n$6\r\nSELECT\r\n$1\r\n0\r\n
+//	*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n
+const (
+	aofMagic   = "FERRODB-AOF"
+	aofVersion = 2
+	maxBulkLen = 512 << 20 // proto-max-bulk-len Redis
+)
+
+func header() string {
+	return aofMagic + " " + strconv.Itoa(aofVersion) + "\r\n"
+}
+
+// Entry adalah satu command AOF beserta DB tempat command dijalankan.
+type Entry struct {
+	DB   int
+	Args []string
+}
+
+// FormatError menunjukkan isi AOF yang tidak bisa dibaca beserta offset
+// byte awal record-nya. Truncated berarti file berakhir di tengah record
+// atau blok MULTI (mis. crash saat write), bukan isi yang rusak.
+type FormatError struct {
+	Offset    int64
+	Reason    string
+	Truncated bool
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("aof: %s at offset %d", e.Reason, e.Offset)
+}
+
+// appendCommand meng-encode args sebagai array RESP.
+func appendCommand(buf []byte, args ...string) []byte {
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	return buf
+}
+
+// appendEntries meng-encode entries, menyisipkan SELECT bila DB berbeda
+// dari *db (DB terakhir di file, -1 = belum diketahui). multi membungkus
+// entries dengan MULTI/EXEC agar di-replay sebagai satu unit.
+func appendEntries(buf []byte, db *int, entries []Entry, multi bool) []byte {
+	if multi {
+		buf = appendCommand(buf, "MULTI")
+	}
+	for _, e := range entries {
+		if e.DB != *db {
+			buf = appendCommand(buf, "SELECT", strconv.Itoa(e.DB))
+			*db = e.DB
+		}
+		buf = appendCommand(buf, e.Args...)
+	}
+	if multi {
+		buf = appendCommand(buf, "EXEC")
+	}
+	return buf
+}
+
+var errBadLine = errors.New("line not terminated by CRLF")
+
+// recordReader membaca record RESP dari AOF sambil melacak offset byte.
+// io.EOF hanya dikembalikan di batas record; record yang terpotong
+// menghasilkan io.ErrUnexpectedEOF.
+type recordReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{r: bufio.NewReader(r)}
+}
+
+// readHeader membaca baris header dan mengembalikan versinya.
+func (rr *recordReader) readHeader() (int, error) {
+	line, err := rr.readLine()
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if err != nil && err != errBadLine {
+		return 0, err
+	}
+
+	version, ok := strings.CutPrefix(line, aofMagic+" ")
+	n, err := strconv.Atoi(version)
+	if !ok || err != nil {
+		return 0, &FormatError{Offset: 0, Reason: "missing header"}
+	}
+	if n > aofVersion {
+		return 0, &FormatError{Offset: 0, Reason: fmt.Sprintf("unsupported format version %d", n)}
+	}
+	return n, nil
+}
+
+// next membaca satu command beserta offset awalnya.
+func (rr *recordReader) next() ([]string, int64, error) {
+	start := rr.offset
+
+	line, err := rr.readLine()
+	if err == errBadLine {
+		return nil, start, &FormatError{Offset: start, Reason: "line not terminated by CRLF"}
+	}
+	if err != nil {
+		return nil, start, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if line[0] != '*' || err != nil || count <= 0 {
+		return nil, start, &FormatError{Offset: start, Reason: fmt.Sprintf("invalid array header %q", line)}
+	}
+
+	args := make([]string, 0, min(count, 1024))
+	for range count {
+		line, err := rr.readLine()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == errBadLine {
+			return nil, start, &FormatError{Offset: start, Reason: "line not terminated by CRLF"}
+		}
+		if err != nil {
+			return nil, start, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if line[0] != '$' || err != nil || size < 0 || size > maxBulkLen {
+			return nil, start, &FormatError{Offset: start, Reason: fmt.Sprintf("invalid bulk header %q", line)}
+		}
+
+		buf := make([]byte, size+2)
+		n, err := io.ReadFull(rr.r, buf)
+		rr.offset += int64(n)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, start, err
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, start, &FormatError{Offset: start, Reason: "bulk string not terminated by CRLF"}
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, start, nil
+}
+
+// readLine membaca satu baris yang diakhiri CRLF tanpa CRLF-nya.
+func (rr *recordReader) readLine() (string, error) {
+	line, err := rr.r.ReadString('\n')
+	rr.offset += int64(len(line))
+	if err == io.EOF && line != "" {
+		return "", io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line, ok := strings.CutSuffix(line, "\r\n")
+	if !ok || line == "" {
+		return "", errBadLine
+	}
+	return line, nil
+}
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppendEntries(t *testing.T) {
+	db := -1
+	buf := appendEntries(nil, &db, []Entry{{DB: 0, Args: []string{"SET", "k", "v"}}}, false)
+	buf = appendEntries(buf, &db, []Entry{
+		{DB: 0, Args: []string{"DEL", "k"}},
+		{DB: 2, Args: []string{"INCR", "n"}},
+	}, true)
+
+	want := "*2\r\n$6\r\nSELECT\r\n$1\r\n0\r\n" +
+		"*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n" +
+		"*1\r\n$5\r\nMULTI\r\n" +
+		"*2\r\n$3\r\nDEL\r\n$1\r\nk\r\n" +
+		"*2\r\n$6\r\nSELECT\r\n$1\r\n2\r\n" +
+		"*2\r\n$4\r\nINCR\r\n$1\r\nn\r\n" +
+		"*1\r\n$4\r\nEXEC\r\n"
+	if string(buf) != want {
+		t.Fatalf("encoded %q, want %q", buf, want)
+	}
+	if db != 2 {
+		t.Fatalf("db = %d, want 2", db)
+	}
+}
+
+func TestScanBinarySafe(t *testing.T) {
+	want := []Entry{
+		{DB: 0, Args: []string{"SET", "k", "a\x00b\r\n*1\r\n$4\r\n"}},
+		{DB: 0, Args: []string{"SET", "", ""}},
+		{DB: 5, Args: []string{"HSET", "h", "f v", "\xff\xfe"}},
+	}
+
+	db := -1
+	buf := []byte(header())
+	for _, e := range want {
+		buf = appendEntries(buf, &db, []Entry{e}, false)
+	}
+
+	var got []Entry
+	valid, err := scan(strings.NewReader(string(buf)), func(e Entry, _ int64) {
+		got = append(got, e)
+	})
+	if err != nil || valid != int64(len(buf)) {
+		t.Fatalf("scan = %d, %v; want %d, nil", valid, err, len(buf))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scanned %q, want %q", got, want)
+	}
+}
+
+// TestScanCorrupt: isi yang rusak (bukan terpotong) dilaporkan sebagai
+// FormatError dengan offset record yang rusak dan Truncated false.
+func TestScanCorrupt(t *testing.T) {
+	h := header()
+	set := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n"
+
+	cases := []struct {
+		name   string
+		data   string
+		reason string
+		offset int
+	}{
+		{"no header", set, "missing header", 0},
+		{"legacy header", "SET 0 k v\n", "missing header", 0},
+		{"newer version", aofMagic + " 3\r\n" + set, "unsupported format version 3", 0},
+		{"array header", h + set + "+OK\r\n", `invalid array header "+OK"`, len(h + set)},
+		{"bulk header", h + "*1\r\n:1\r\n", `invalid bulk header ":1"`, len(h)},
+		{"bulk length", h + "*1\r\n$1\r\nab\r\n", "bulk string not terminated by CRLF", len(h)},
+		{"bare LF", h + "*1\n$4\nPING\n", "line not terminated by CRLF", len(h)},
+		{"select", h + "*2\r\n$6\r\nSELECT\r\n$1\r\nx\r\n", "invalid SELECT", len(h)},
+		{"exec", h + "*1\r\n$4\r\nEXEC\r\n", "EXEC without MULTI", len(h)},
+		{"nested multi", h + "*1\r\n$5\r\nMULTI\r\n*1\r\n$5\r\nMULTI\r\n", "nested MULTI", len(h) + 15},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := scan(strings.NewReader(tc.data), func(Entry, int64) {})
+
+			var ferr *FormatError
+			if !errors.As(err, &ferr) {
+				t.Fatalf("scan error = %v, want *FormatError", err)
+			}
+			if ferr.Reason != tc.reason || ferr.Offset != int64(tc.offset) || ferr.Truncated {
+				t.Fatalf("error = %+v, want reason %q at offset %d", ferr, tc.reason, tc.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package persistence
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FsyncPolicy adalah appendfsync gaya Redis.
+type FsyncPolicy int
+
+const (
+	FsyncEverySec FsyncPolicy = iota // fsync di background tiap detik
+	FsyncAlways                      // group commit: reply menunggu fsync
+	FsyncNo                          // serahkan flush ke OS
+)
+
+func (p FsyncPolicy) String() string {
+	switch p {
+	case FsyncAlways:
+		return "always"
+	case FsyncNo:
+		return "no"
+	default:
+		return "everysec"
+	}
+}
+
+// ParseFsyncPolicy menerima always, everysec atau no (case-insensitive);
+// kosong berarti everysec.
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch strings.ToLower(s) {
+	case "", "everysec":
+		return FsyncEverySec, nil
+	case "always":
+		return FsyncAlways, nil
+	case "no":
+		return FsyncNo, nil
+	}
+	return 0, fmt.Errorf("invalid appendfsync policy %q", s)
+}
+
+// fsyncDelayed adalah batas durasi fsync everysec sebelum dihitung
+// tertunda (aof_delayed_fsync di Redis).
+const fsyncDelayed = time.Second
+
+// FsyncStats adalah statistik fsync AOF untuk INFO.
+type FsyncStats struct {
+	Policy       FsyncPolicy
+	LastFsync    time.Time     // fsync sukses terakhir
+	Lag          time.Duration // umur data tertua yang belum di-fsync
+	PendingBytes int64         // byte yang sudah ditulis tetapi belum di-fsync
+	LastLatency  time.Duration // durasi fsync terakhir
+	MaxLatency   time.Duration
+	Delayed      int64 // fsync everysec yang lebih lama dari satu detik
+	Errors       int64
+}
+
+type fsyncStats struct {
+	pending     atomic.Int64
+	dirtySince  atomic.Int64 // unix nanosecond write pertama sejak fsync, 0 = bersih
+	lastFsync   atomic.Int64 // unix nanosecond
+	lastLatency atomic.Int64 // nanosecond
+	maxLatency  atomic.Int64
+	delayed     atomic.Int64
+	errors      atomic.Int64
+}
+
+// Stats mengembalikan statistik fsync saat ini.
+func (a *AOF) Stats() FsyncStats {
+	var lag time.Duration
+	if since := a.stats.dirtySince.Load(); since > 0 {
+		lag = time.Since(time.Unix(0, since))
+	}
+
+	return FsyncStats{
+		Policy:       a.policy,
+		LastFsync:    time.Unix(0, a.stats.lastFsync.Load()),
+		Lag:          lag,
+		PendingBytes: a.stats.pending.Load(),
+		LastLatency:  time.Duration(a.stats.lastLatency.Load()),
+		MaxLatency:   time.Duration(a.stats.maxLatency.Load()),
+		Delayed:      a.stats.delayed.Load(),
+		Errors:       a.stats.errors.Load(),
+	}
+}
+
+// written mencatat n byte baru yang belum di-fsync.
+// Pemanggil wajib memegang a.mu.
+func (a *AOF) written(n int) {
+	if a.stats.pending.Add(int64(n)) == int64(n) {
+		a.stats.dirtySince.Store(time.Now().UnixNano())
+	}
+}
+
+// fsync memanggil fsync file AOF bila ada data yang belum di-fsync dan
+// mencatat latency-nya. Write tetap bisa berjalan selama fsync karena
+// yang dipegang hanya syncMu.
+func (a *AOF) fsync() error {
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	// byte yang ditulis setelah titik ini ikut ter-fsync atau tetap
+	// tercatat pending untuk putaran berikutnya
+	a.mu.Lock()
+	file := a.file
+	pending := a.stats.pending.Swap(0)
+	since := a.stats.dirtySince.Swap(0)
+	a.mu.Unlock()
+
+	if pending == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := file.Sync()
+	latency := time.Since(start)
+
+	if err != nil {
+		a.mu.Lock()
+		a.stats.pending.Add(pending)
+		a.stats.dirtySince.CompareAndSwap(0, since)
+		a.mu.Unlock()
+		a.stats.errors.Add(1)
+		return err
+	}
+
+	a.synced(latency)
+	return nil
+}
+
+// synced mencatat satu fsync yang berhasil.
+// Pemanggil wajib memegang syncMu.
+func (a *AOF) synced(latency time.Duration) {
+	a.stats.lastFsync.Store(time.Now().UnixNano())
+	a.stats.lastLatency.Store(int64(latency))
+	if latency > time.Duration(a.stats.maxLatency.Load()) {
+		a.stats.maxLatency.Store(int64(latency))
+	}
+}
+
+// fsyncLoop menjalankan fsync tiap detik untuk policy everysec.
+func (a *AOF) fsyncLoop() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			if err := a.fsync(); err != nil {
+				log.Println("aof fsync error:", err)
+			}
+			if time.Since(start) > fsyncDelayed {
+				a.stats.delayed.Add(1)
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
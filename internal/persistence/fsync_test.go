@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFsyncPolicy(t *testing.T) {
+	cases := map[string]FsyncPolicy{
+		"":         FsyncEverySec,
+		"everysec": FsyncEverySec,
+		"ALWAYS":   FsyncAlways,
+		"no":       FsyncNo,
+	}
+	for in, want := range cases {
+		if got, err := ParseFsyncPolicy(in); err != nil || got != want {
+			t.Errorf("ParseFsyncPolicy(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseFsyncPolicy("sometimes"); err == nil {
+		t.Error("ParseFsyncPolicy accepted an unknown policy")
+	}
+}
+
+// TestFsyncPolicyStats memeriksa kapan data dianggap durable untuk setiap
+// policy lewat PendingBytes dan Lag.
+func TestFsyncPolicyStats(t *testing.T) {
+	t.Run("always", func(t *testing.T) {
+		aof, _ := openTestAOF(t, FsyncAlways)
+		defer aof.Close()
+
+		// reply baru kembali setelah fsync
+		if err := aof.Write(0, "SET", "k", "v"); err != nil {
+			t.Fatal(err)
+		}
+		st := aof.Stats()
+		if st.PendingBytes != 0 || st.Lag != 0 || st.LastLatency <= 0 {
+			t.Fatalf("stats after Write = %+v", st)
+		}
+	})
+
+	t.Run("no", func(t *testing.T) {
+		aof, _ := openTestAOF(t, FsyncNo)
+		defer aof.Close()
+
+		if err := aof.Write(0, "SET", "k", "v"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		st := aof.Stats()
+		if st.PendingBytes == 0 || st.Lag < 10*time.Millisecond {
+			t.Fatalf("stats after Write = %+v, want pending data", st)
+		}
+
+		before := st.LastFsync
+		if err := aof.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		st = aof.Stats()
+		if st.PendingBytes != 0 || st.Lag != 0 || !st.LastFsync.After(before) {
+			t.Fatalf("stats after Sync = %+v", st)
+		}
+	})
+
+	t.Run("everysec", func(t *testing.T) {
+		aof, _ := openTestAOF(t, FsyncEverySec)
+		defer aof.Close()
+
+		if err := aof.Write(0, "SET", "k", "v"); err != nil {
+			t.Fatal(err)
+		}
+		if st := aof.Stats(); st.PendingBytes == 0 {
+			t.Fatalf("stats right after Write = %+v, want pending data", st)
+		}
+
+		// fsync background berjalan tiap detik
+		deadline := time.Now().Add(3 * time.Second)
+		for aof.Stats().PendingBytes != 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("data still pending after 3s: %+v", aof.Stats())
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		if st := aof.Stats(); st.Lag != 0 || st.Errors != 0 {
+			t.Fatalf("stats after background fsync = %+v", st)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package persistence
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchDone melaporkan apakah batch milik p sudah selesai tanpa menunggu.
+func batchDone(p Pending) bool {
+	select {
+	case <-p.b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestGroupCommitBatch: writer yang datang selama flusher tertahan masuk
+// batch yang sama dan baru selesai setelah batch itu di-fsync.
+func TestGroupCommitBatch(t *testing.T) {
+	aof, path := openTestAOF(t, FsyncAlways)
+
+	// flusher tertahan di syncMu seperti saat fsync sebelumnya masih jalan
+	aof.syncMu.Lock()
+	var pending []Pending
+	for i := 0; i < 3; i++ {
+		pending = append(pending, aof.Append([]Entry{{DB: 0, Args: []string{"SET", "k", strconv.Itoa(i)}}}))
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for i, p := range pending {
+		if p.b != pending[0].b {
+			t.Fatalf("write %d landed in a different batch", i)
+		}
+		if batchDone(p) {
+			t.Fatalf("write %d completed before its batch was fsynced", i)
+		}
+	}
+	aof.syncMu.Unlock()
+
+	for _, p := range pending {
+		if err := p.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st := aof.Stats(); st.PendingBytes != 0 {
+		t.Fatalf("PendingBytes after batch = %d", st.PendingBytes)
+	}
+
+	// write berikutnya memakai batch baru
+	next := aof.Append([]Entry{{DB: 0, Args: []string{"SET", "k", "3"}}})
+	if next.b == pending[0].b {
+		t.Fatal("write after flush reused the flushed batch")
+	}
+	if err := next.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aof.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := replayAll(t, path)
+	for i, e := range got {
+		if e.Args[2] != strconv.Itoa(i) {
+			t.Fatalf("replayed %v out of order", got)
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("replayed %d entries, want 4", len(got))
+	}
+}
+
+// TestGroupCommitConcurrent menulis dari banyak goroutine sekaligus; setiap
+// write harus durable dan urutan per goroutine terjaga.
+func TestGroupCommitConcurrent(t *testing.T) {
+	aof, path := openTestAOF(t, FsyncAlways)
+
+	const writers, writes = 32, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			key := "k" + strconv.Itoa(w)
+			for i := 0; i < writes; i++ {
+				if err := aof.Write(w%4, "SET", key, strconv.Itoa(i)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := aof.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := replayAll(t, path)
+	if len(got) != writers*writes {
+		t.Fatalf("replayed %d entries, want %d", len(got), writers*writes)
+	}
+
+	next := map[string]int{}
+	for _, e := range got {
+		key := e.Args[1]
+		w, _ := strconv.Atoi(key[1:])
+		if e.DB != w%4 || e.Args[2] != strconv.Itoa(next[key]) {
+			t.Fatalf("entry %v in db %d out of order, want value %d in db %d", e.Args, e.DB, next[key], w%4)
+		}
+		next[key]++
+	}
+}
+
+// TestGroupCommitClose: batch yang masih terbuka di-flush saat Close, dan
+// write sesudahnya ditolak.
+func TestGroupCommitClose(t *testing.T) {
+	aof, path := openTestAOF(t, FsyncAlways)
+
+	aof.syncMu.Lock()
+	p := aof.Append([]Entry{{DB: 0, Args: []string{"SET", "k", "v"}}})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		aof.syncMu.Unlock()
+	}()
+	if err := aof.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatalf("pending write after Close = %v", err)
+	}
+	if err := aof.Append([]Entry{{DB: 0, Args: []string{"DEL", "k"}}}).Wait(); err != errClosed {
+		t.Fatalf("Append after Close = %v, want %v", err, errClosed)
+	}
+
+	got := replayAll(t, path)
+	if len(got) != 1 {
+		t.Fatalf("replayed %v, want the SET only", got)
+	}
+}
+
+// TestGroupCommitError: error write atau fsync diteruskan ke semua writer
+// di batch dan dihitung di statistik.
+func TestGroupCommitError(t *testing.T) {
+	aof, _ := openTestAOF(t, FsyncAlways)
+	defer aof.Close()
+
+	aof.file.Close()
+	if err := aof.Write(1, "SET", "k", "v"); err == nil {
+		t.Fatal("Write to a closed file succeeded")
+	}
+	if st := aof.Stats(); st.Errors != 1 {
+		t.Fatalf("Errors = %d, want 1", st.Errors)
+	}
+	// SELECT harus ditulis ulang pada write berikutnya
+	if aof.db != -1 {
+		t.Fatalf("db = %d after failed batch, want -1", aof.db)
+	}
+}
@@ -0,0 +1,171 @@
+package persistence
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"ferrodb/internal/parser"
+)
+
+// migrateLegacy mengubah AOF format teks lama (v1: satu command per baris,
+// "NAME db arg...", argumen di-quote) menjadi format v2 sekali saja. File
+// lama tetap disimpan sebagai <path>.v1.bak.
+func migrateLegacy(path string) error {
+	legacy, err := isLegacy(path)
+	if err != nil || !legacy {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // no-op setelah rename berhasil
+
+	writer := bufio.NewWriter(tmp)
+	writer.WriteString(header())
+
+	commands := 0
+	db := -1
+	var buf []byte
+	err = readLegacy(src, func(entries []Entry, multi bool) error {
+		commands += len(entries)
+		buf = appendEntries(buf[:0], &db, entries, multi)
+		_, err := writer.Write(buf)
+		return err
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	// file lama di-link dulu agar path tidak pernah hilang bila crash
+	// di tengah migrasi; rename tmp ke path bersifat atomic
+	backup := path + ".v1.bak"
+	os.Remove(backup)
+	if err := os.Link(path, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	log.Printf("aof migrated to format v%d (%d commands), old file kept as %s", aofVersion, commands, backup)
+	return nil
+}
+
+// isLegacy melaporkan apakah path berisi AOF tanpa header v2. File yang
+// tidak ada atau kosong bukan legacy.
+func isLegacy(path string) (bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	prefix := make([]byte, len(aofMagic))
+	n, err := io.ReadFull(file, prefix)
+	if n == 0 {
+		return false, nil
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return string(prefix[:n]) != aofMagic, nil
+}
+
+// readLegacy membaca AOF v1 dan memanggil emit per command atau per blok
+// MULTI/EXEC yang lengkap. Baris di-split dengan aturan quote SplitArgs dan
+// jatuh ke strings.Fields seperti replay v1 bila quote-nya tidak seimbang.
+// Baris yang tetap tidak valid menggagalkan migrasi; blok MULTI yang tidak
+// lengkap di akhir file dibuang.
+func readLegacy(r io.Reader, emit func(entries []Entry, multi bool) error) error {
+	reader := bufio.NewReader(r)
+
+	var tx []Entry
+	inTx := false
+
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadString('\n')
+
+		if line = strings.TrimRight(line, "\r\n"); strings.TrimSpace(line) != "" {
+			args := legacyArgs(line)
+			switch {
+			case strings.EqualFold(args[0], "MULTI"):
+				tx, inTx = nil, true
+			case strings.EqualFold(args[0], "EXEC") && inTx:
+				if len(tx) > 0 {
+					if err := emit(tx, true); err != nil {
+						return err
+					}
+				}
+				tx, inTx = nil, false
+			default:
+				entry, ok := legacyEntry(args)
+				switch {
+				case !ok:
+					return fmt.Errorf("aof v1 line %d: invalid command %q", lineNo, line)
+				case inTx:
+					tx = append(tx, entry)
+				default:
+					if err := emit([]Entry{entry}, false); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// legacyArgs memecah satu baris v1. Baseline menulis nilai apa adanya
+// (mis. JSON), jadi baris yang ditolak SplitArgs dipecah per spasi.
+func legacyArgs(line string) []string {
+	if args, err := parser.SplitArgs(line); err == nil && len(args) > 0 {
+		return args
+	}
+	return strings.Fields(line)
+}
+
+// legacyEntry mengubah "NAME db arg..." menjadi Entry.
+func legacyEntry(args []string) (Entry, bool) {
+	if len(args) < 2 {
+		return Entry{}, false
+	}
+
+	db, err := strconv.Atoi(args[1])
+	if err != nil || db < 0 {
+		return Entry{}, false
+	}
+	return Entry{DB: db, Args: append([]string{args[0]}, args[2:]...)}, true
+}
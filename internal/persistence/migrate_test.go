@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeLegacy(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func replayAll(t *testing.T, path string) []Entry {
+	t.Helper()
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aof.Close()
+
+	var got []Entry
+	err = aof.Replay(false, func(db int, args []string) error {
+		got = append(got, Entry{DB: db, Args: args})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	path := writeLegacy(t,
+		`SET 0 j {"a":1}`,
+		`SET 0 q "a b"`,
+		`SET 1 esc "tab\there \"x\" \\"`,
+		`SET 0 bs a\b`,
+		`SET 0 half 'x`,
+		``,
+		`MULTI`,
+		`INCR 2 n`,
+		`SET 2 m it's`,
+		`EXEC`,
+		`MULTI`,
+		`SET 0 lost 1`,
+	)
+
+	want := []Entry{
+		{DB: 0, Args: []string{"SET", "j", `{"a":1}`}},
+		{DB: 0, Args: []string{"SET", "q", "a b"}},
+		{DB: 1, Args: []string{"SET", "esc", "tab\there \"x\" \\"}},
+		{DB: 0, Args: []string{"SET", "bs", `a\b`}},
+		{DB: 0, Args: []string{"SET", "half", "'x"}},
+		{DB: 2, Args: []string{"INCR", "n"}},
+		{DB: 2, Args: []string{"SET", "m", "it's"}},
+	}
+	if got := replayAll(t, path); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replay after migration:\n got %q\nwant %q", got, want)
+	}
+
+	if _, err := os.Stat(path + ".v1.bak"); err != nil {
+		t.Fatalf("backup missing: %v", err)
+	}
+}
+
+func TestMigrateLegacyInvalidLine(t *testing.T) {
+	path := writeLegacy(t,
+		`SET 0 a 1`,
+		`SET x b 2`,
+	)
+	before, _ := os.ReadFile(path)
+
+	_, err := OpenAOF(path, FsyncNo)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err = %v, want error naming line 2", err)
+	}
+
+	// file lama tidak boleh disentuh bila migrasi gagal
+	after, _ := os.ReadFile(path)
+	if string(after) != string(before) {
+		t.Fatalf("legacy file changed: %q", after)
+	}
+}
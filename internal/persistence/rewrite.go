@@ -0,0 +1,275 @@
+package persistence
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"ferrodb/internal/storage"
+)
+
+var (
+	ErrRewriteInProgress = errors.New("ERR Background append only file rewriting already in progress")
+	errRewriteBufferFull = errors.New("aof rewrite buffer exceeded limit, rewrite aborted")
+)
+
+// rewriteDrainLimit: rewrite buffer disalin ke file sementara tanpa
+// menahan write lain sampai sisanya lebih kecil dari batas ini; sisa
+// terakhir disalin saat file ditukar.
+const rewriteDrainLimit = 64 << 10
+
+// rewriteBufferLimit membatasi rewrite buffer. Bila write yang masuk
+// selama rewrite melewati batas ini, rewrite dibatalkan (status err) dan
+// buffer dilepas, alih-alih menahan memori tanpa batas; AOF lama tetap
+// dipakai. Variabel agar bisa diperkecil di test.
+var rewriteBufferLimit = 64 << 20
+
+// rewriteState adalah state rewrite yang sedang berjalan dan hasil
+// rewrite terakhir. Seluruhnya dilindungi a.mu.
+type rewriteState struct {
+	active  bool
+	aborted bool // buffer melewati rewriteBufferLimit
+	started time.Time
+	buf     []byte // write yang masuk selama rewrite, format AOF
+	db      int    // DB terakhir di buf
+
+	count        int64
+	lastStatus   string
+	lastDuration time.Duration
+}
+
+// RewriteStats adalah status rewrite untuk INFO.
+type RewriteStats struct {
+	InProgress   bool
+	Current      time.Duration // lama rewrite yang sedang berjalan
+	BufferBytes  int64
+	Rewrites     int64
+	LastStatus   string        // "ok", "err", atau kosong bila belum pernah
+	LastDuration time.Duration // -1 bila belum pernah
+}
+
+func (a *AOF) RewriteStats() RewriteStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := RewriteStats{
+		InProgress:   a.rw.active,
+		BufferBytes:  int64(len(a.rw.buf)),
+		Rewrites:     a.rw.count,
+		LastStatus:   a.rw.lastStatus,
+		LastDuration: a.rw.lastDuration,
+	}
+	if a.rw.active {
+		st.Current = time.Since(a.rw.started)
+	}
+	if a.rw.count == 0 {
+		st.LastDuration = -1
+	}
+	return st
+}
+
+// BeginRewrite mulai menampung setiap write baru di rewrite buffer.
+// Pemanggil wajib memastikan tidak ada write yang sedang berjalan dan
+// menandai snapshot dataset sebelum write berikutnya (lihat
+// storage.MemoryStore.BeginSnapshot), sehingga setiap write tercatat tepat
+// sekali: di snapshot atau di rewrite buffer.
+func (a *AOF) BeginRewrite() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.rw.active {
+		return ErrRewriteInProgress
+	}
+	if a.closed {
+		return errClosed
+	}
+
+	a.rw.active = true
+	a.rw.aborted = false
+	a.rw.started = time.Now()
+	a.rw.buf = nil
+	a.rw.db = -1
+	return nil
+}
+
+// captureRewrite menyalin entries ke rewrite buffer bila rewrite sedang
+// berjalan. Pemanggil wajib memegang a.mu.
+func (a *AOF) captureRewrite(entries []Entry, multi bool) {
+	if !a.rw.active || a.rw.aborted {
+		return
+	}
+
+	a.rw.buf = appendEntries(a.rw.buf, &a.rw.db, entries, multi)
+	if len(a.rw.buf) > rewriteBufferLimit {
+		log.Printf("aof rewrite buffer exceeded %d bytes, aborting rewrite", rewriteBufferLimit)
+		a.rw.aborted = true
+		a.rw.buf = nil
+	}
+}
+
+// Rewrite menulis AOF baru dari snapshot yang diambil bersamaan dengan
+// BeginRewrite, menambahkan rewrite buffer, lalu menukarnya dengan file
+// lama secara atomic. Write lain tetap berjalan ke file lama selama
+// rewrite; bila rewrite gagal atau dibatalkan, file lama tidak berubah.
+func (a *AOF) Rewrite(snapshot *storage.Snapshot) (err error) {
+	a.mu.Lock()
+	start := a.rw.started
+	a.mu.Unlock()
+
+	path := a.path
+	tmpPath := path + ".rewrite.tmp"
+	defer func() {
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+		a.endRewrite(start, err)
+	}()
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+
+	writer := bufio.NewWriter(tmp)
+	if err := writeSnapshot(writer, snapshot); err != nil {
+		return err
+	}
+
+	// salin rewrite buffer selagi client lain tetap menulis
+	for {
+		a.mu.Lock()
+		chunk, aborted := a.rw.buf, a.rw.aborted
+		a.rw.buf = nil
+		a.mu.Unlock()
+
+		if aborted {
+			return errRewriteBufferFull
+		}
+		if _, err := writer.Write(chunk); err != nil {
+			return err
+		}
+		if len(chunk) < rewriteDrainLimit {
+			break
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	// penukaran: fsync dan write ditahan sampai file baru terpasang
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return errClosed
+	}
+	if a.rw.aborted {
+		return errRewriteBufferFull
+	}
+	if len(a.rw.buf) > 0 {
+		if _, err := tmp.Write(a.rw.buf); err != nil {
+			return err
+		}
+		if err := tmp.Sync(); err != nil {
+			return err
+		}
+	}
+
+	// handle baru dibuka sebelum rename agar tidak ada titik di mana
+	// write bisa jatuh ke file yang sudah tidak punya nama
+	file, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		file.Close()
+		return err
+	}
+	syncDir(filepath.Dir(path))
+
+	a.file.Close()
+	a.file = file
+	a.db = -1
+
+	// isi batch group commit yang terbuka sudah ada di rewrite buffer dan
+	// sudah di-fsync bersama file baru
+	b := a.batch
+	a.batch = newBatch()
+	close(b.done)
+
+	a.stats.pending.Store(0)
+	a.stats.dirtySince.Store(0)
+	a.stats.lastFsync.Store(time.Now().UnixNano())
+	return nil
+}
+
+// endRewrite menutup rewrite dan mencatat hasilnya.
+func (a *AOF) endRewrite(start time.Time, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rw.active = false
+	a.rw.aborted = false
+	a.rw.buf = nil
+	a.rw.count++
+	a.rw.lastDuration = time.Since(start)
+	a.rw.lastStatus = "ok"
+	if err != nil {
+		a.rw.lastStatus = "err"
+	}
+}
+
+// writeSnapshot menulis header dan command minimal yang membangun ulang
+// snapshot, satu shard dalam satu waktu.
+func writeSnapshot(w *bufio.Writer, snapshot *storage.Snapshot) error {
+	if _, err := w.WriteString(header()); err != nil {
+		return err
+	}
+
+	var buf []byte
+	last := -1
+	for {
+		db, kv, ok := snapshot.Next()
+		if !ok {
+			return nil
+		}
+		if db != last {
+			buf = appendCommand(buf[:0], "SELECT", strconv.Itoa(db))
+			last = db
+		}
+
+		for key, item := range kv {
+			for _, args := range rewriteCommands(key, item) {
+				buf = appendCommand(buf, args...)
+			}
+
+			// PEXPIREAT (absolute, milidetik)
+			if item.ExpireAt > 0 {
+				buf = appendCommand(buf, "PEXPIREAT", key, strconv.FormatInt(item.ExpireAt, 10))
+			}
+
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+}
+
+// syncDir men-fsync direktori agar rename ikut durable.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ferrodb/internal/storage"
+)
+
+// TestRewriteBufferLimit memastikan rewrite dibatalkan bila write selama
+// rewrite melewati rewriteBufferLimit, tanpa mengubah AOF lama.
+func TestRewriteBufferLimit(t *testing.T) {
+	defer func(limit int) { rewriteBufferLimit = limit }(rewriteBufferLimit)
+	rewriteBufferLimit = 1 << 10
+
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := storage.NewMemoryStore(1, 1)
+	defer store.Close()
+
+	if err := aof.Write(0, "SET", "k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	store.Set(0, "k", "v")
+
+	snapshot, err := store.BeginSnapshot(aof.BeginRewrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+
+	value := strings.Repeat("x", 256)
+	for i := 0; i < 8; i++ {
+		if err := aof.Write(0, "APPEND", "k", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aof.Rewrite(snapshot); !errors.Is(err, errRewriteBufferFull) {
+		t.Fatalf("Rewrite() = %v, want %v", err, errRewriteBufferFull)
+	}
+	if st := aof.RewriteStats(); st.LastStatus != "err" || st.InProgress {
+		t.Fatalf("rewrite stats = %+v, want finished with status err", st)
+	}
+	if _, err := os.Stat(path + ".rewrite.tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file still exists after aborted rewrite: %v", err)
+	}
+
+	// write sesudah rewrite batal tetap masuk ke AOF lama
+	if err := aof.Write(0, "DEL", "k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aof.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := replayAll(t, path)
+	if len(got) != 10 {
+		t.Fatalf("replayed %d commands, want 10", len(got))
+	}
+	if want := (Entry{DB: 0, Args: []string{"DEL", "k"}}); !reflect.DeepEqual(got[9], want) {
+		t.Fatalf("last command = %v, want %v", got[9], want)
+	}
+}
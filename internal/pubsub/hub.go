@@ -0,0 +1,258 @@
+// Package pubsub berisi hub publish/subscribe yang dipakai bersama oleh
+// semua koneksi.
+package pubsub
+
+import (
+	"sort"
+	"sync"
+
+	"ferrodb/internal/glob"
+)
+
+// Message adalah satu pesan yang dikirim ke subscriber. Pattern terisi
+// bila pesan diterima lewat PSUBSCRIBE.
+type Message struct {
+	Pattern string
+	Channel string
+	Payload string
+}
+
+// Subscriber adalah satu koneksi yang berlangganan. Pesan dikirim lewat
+// C; bila buffer C penuh (subscriber terlalu lambat membaca), subscriber
+// dikeluarkan dari hub dan onDrop dipanggil sehingga publisher tidak
+// pernah ikut tertahan.
+type Subscriber struct {
+	C chan Message
+
+	onDrop   func()
+	channels map[string]struct{}
+	patterns map[string]struct{}
+	dropped  bool
+}
+
+func NewSubscriber(buffer int, onDrop func()) *Subscriber {
+	return &Subscriber{
+		C:        make(chan Message, buffer),
+		onDrop:   onDrop,
+		channels: map[string]struct{}{},
+		patterns: map[string]struct{}{},
+	}
+}
+
+type Hub struct {
+	mu       sync.RWMutex
+	channels map[string]map[*Subscriber]struct{}
+	patterns map[string]map[*Subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		channels: map[string]map[*Subscriber]struct{}{},
+		patterns: map[string]map[*Subscriber]struct{}{},
+	}
+}
+
+// Count mengembalikan jumlah channel dan pattern yang di-subscribe.
+func (h *Hub) Count(sub *Subscriber) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(sub.channels) + len(sub.patterns)
+}
+
+// Subscribe mendaftarkan sub ke channel lalu mengembalikan jumlah
+// subscription milik sub.
+func (h *Hub) Subscribe(sub *Subscriber, channel string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !sub.dropped {
+		add(h.channels, sub.channels, sub, channel)
+	}
+	return len(sub.channels) + len(sub.patterns)
+}
+
+func (h *Hub) PSubscribe(sub *Subscriber, pattern string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !sub.dropped {
+		add(h.patterns, sub.patterns, sub, pattern)
+	}
+	return len(sub.channels) + len(sub.patterns)
+}
+
+func (h *Hub) Unsubscribe(sub *Subscriber, channel string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	remove(h.channels, sub.channels, sub, channel)
+	return len(sub.channels) + len(sub.patterns)
+}
+
+func (h *Hub) PUnsubscribe(sub *Subscriber, pattern string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	remove(h.patterns, sub.patterns, sub, pattern)
+	return len(sub.channels) + len(sub.patterns)
+}
+
+// Channels mengembalikan channel milik sub, terurut.
+func (h *Hub) Channels(sub *Subscriber) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return sortedKeys(sub.channels)
+}
+
+// Patterns mengembalikan pattern milik sub, terurut.
+func (h *Hub) Patterns(sub *Subscriber) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return sortedKeys(sub.patterns)
+}
+
+// Remove mencabut semua subscription milik sub, mis. saat koneksi putus.
+func (h *Hub) Remove(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeAll(sub)
+}
+
+// Publish mengirim payload ke subscriber channel dan pattern yang cocok,
+// lalu mengembalikan jumlah penerima. Pengiriman tidak pernah blocking.
+func (h *Hub) Publish(channel, payload string) int {
+	h.mu.RLock()
+	var slow []*Subscriber
+	receivers := 0
+
+	for sub := range h.channels[channel] {
+		if deliver(sub, Message{Channel: channel, Payload: payload}) {
+			receivers++
+		} else {
+			slow = append(slow, sub)
+		}
+	}
+
+	for pattern, subs := range h.patterns {
+		if !glob.Match(pattern, channel) {
+			continue
+		}
+		for sub := range subs {
+			msg := Message{Pattern: pattern, Channel: channel, Payload: payload}
+			if deliver(sub, msg) {
+				receivers++
+			} else {
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		h.drop(sub)
+	}
+	return receivers
+}
+
+// ActiveChannels mengembalikan channel yang punya minimal satu subscriber,
+// difilter dengan pattern bila tidak kosong (PUBSUB CHANNELS).
+func (h *Hub) ActiveChannels(pattern string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var out []string
+	for channel := range h.channels {
+		if pattern != "" {
+			if !glob.Match(pattern, channel) {
+				continue
+			}
+		}
+		out = append(out, channel)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NumSub mengembalikan jumlah subscriber channel (PUBSUB NUMSUB).
+func (h *Hub) NumSub(channel string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.channels[channel])
+}
+
+// NumPat mengembalikan jumlah pattern unik yang di-subscribe.
+func (h *Hub) NumPat() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.patterns)
+}
+
+func deliver(sub *Subscriber, msg Message) bool {
+	select {
+	case sub.C <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// drop mengeluarkan subscriber lambat. Bisa dipanggil beberapa publisher
+// sekaligus; onDrop hanya dijalankan sekali.
+func (h *Hub) drop(sub *Subscriber) {
+	h.mu.Lock()
+	if sub.dropped {
+		h.mu.Unlock()
+		return
+	}
+	sub.dropped = true
+	h.removeAll(sub)
+	h.mu.Unlock()
+
+	if sub.onDrop != nil {
+		sub.onDrop()
+	}
+}
+
+func (h *Hub) removeAll(sub *Subscriber) {
+	for channel := range sub.channels {
+		remove(h.channels, sub.channels, sub, channel)
+	}
+	for pattern := range sub.patterns {
+		remove(h.patterns, sub.patterns, sub, pattern)
+	}
+}
+
+func add(index map[string]map[*Subscriber]struct{}, own map[string]struct{}, sub *Subscriber, name string) {
+	subs, ok := index[name]
+	if !ok {
+		subs = map[*Subscriber]struct{}{}
+		index[name] = subs
+	}
+	subs[sub] = struct{}{}
+	own[name] = struct{}{}
+}
+
+func remove(index map[string]map[*Subscriber]struct{}, own map[string]struct{}, sub *Subscriber, name string) {
+	delete(own, name)
+
+	subs := index[name]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(index, name)
+	}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
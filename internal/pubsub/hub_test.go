@@ -0,0 +1,93 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	h := NewHub()
+	a := NewSubscriber(8, nil)
+	b := NewSubscriber(8, nil)
+
+	if n := h.Subscribe(a, "news"); n != 1 {
+		t.Fatalf("Subscribe = %d, want 1", n)
+	}
+	if n := h.PSubscribe(a, "n*"); n != 2 {
+		t.Fatalf("PSubscribe = %d, want 2", n)
+	}
+	h.PSubscribe(b, "news.[a-c]")
+
+	// a menerima dua kali: lewat channel dan lewat pattern
+	if n := h.Publish("news", "hi"); n != 2 {
+		t.Fatalf("Publish(news) = %d, want 2", n)
+	}
+	if n := h.Publish("news.b", "x"); n != 2 {
+		t.Fatalf("Publish(news.b) = %d, want 2", n)
+	}
+	if n := h.Publish("other", "x"); n != 0 {
+		t.Fatalf("Publish(other) = %d, want 0", n)
+	}
+
+	want := []Message{
+		{Channel: "news", Payload: "hi"},
+		{Pattern: "n*", Channel: "news", Payload: "hi"},
+		{Pattern: "n*", Channel: "news.b", Payload: "x"},
+	}
+	for _, w := range want {
+		if got := <-a.C; !reflect.DeepEqual(got, w) {
+			t.Fatalf("a received %+v, want %+v", got, w)
+		}
+	}
+	if got := <-b.C; got.Pattern != "news.[a-c]" || got.Channel != "news.b" {
+		t.Fatalf("b received %+v", got)
+	}
+
+	if n := h.Unsubscribe(a, "news"); n != 1 {
+		t.Fatalf("Unsubscribe = %d, want 1", n)
+	}
+	if n := h.Unsubscribe(a, "news"); n != 1 {
+		t.Fatalf("second Unsubscribe = %d, want 1", n)
+	}
+	if got := h.NumSub("news"); got != 0 {
+		t.Fatalf("NumSub(news) = %d, want 0", got)
+	}
+	if got := h.NumPat(); got != 2 {
+		t.Fatalf("NumPat = %d, want 2", got)
+	}
+	if got := h.Patterns(a); !reflect.DeepEqual(got, []string{"n*"}) {
+		t.Fatalf("Patterns(a) = %v", got)
+	}
+}
+
+// TestSlowSubscriber: subscriber dengan buffer penuh dikeluarkan dan
+// onDrop dipanggil sekali, tanpa menahan publisher.
+func TestSlowSubscriber(t *testing.T) {
+	h := NewHub()
+	drops := 0
+	slow := NewSubscriber(1, func() { drops++ })
+	fast := NewSubscriber(8, nil)
+	h.Subscribe(slow, "c")
+	h.PSubscribe(slow, "*")
+	h.Subscribe(fast, "c")
+
+	if n := h.Publish("c", "1"); n != 2 {
+		t.Fatalf("first Publish = %d, want 2", n)
+	}
+	if n := h.Publish("c", "2"); n != 1 {
+		t.Fatalf("second Publish = %d, want 1", n)
+	}
+	if n := h.Publish("c", "3"); n != 1 {
+		t.Fatalf("third Publish = %d, want 1", n)
+	}
+
+	if drops != 1 {
+		t.Fatalf("onDrop called %d times, want 1", drops)
+	}
+	if got := h.Count(slow); got != 0 {
+		t.Fatalf("dropped subscriber still has %d subscriptions", got)
+	}
+	if got := h.NumSub("c"); got != 1 {
+		t.Fatalf("NumSub(c) = %d, want 1", got)
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// watchDisconnect mengembalikan context yang dibatalkan bila client menutup
+// koneksi selama connection goroutine parkir di command blocking. Tanpa
+// ini, BLPOP dengan timeout 0 dari client yang sudah pergi akan tetap
+// mengambil elemen saat ada push dan elemen itu hilang.
+//
+// stop wajib dipanggil sebelum reader dipakai lagi oleh handleConnection.
+func watchDisconnect(conn net.Conn, reader *bufio.Reader) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// Peek tidak mengonsumsi data, command pipelined tetap di buffer
+		_, err := reader.Peek(1)
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			cancel()
+		}
+	}()
+
+	stop := func() {
+		// bangunkan Peek yang masih menunggu, lalu kembalikan deadline
+		conn.SetReadDeadline(time.Now())
+		<-done
+		conn.SetReadDeadline(time.Time{})
+		cancel()
+	}
+	return ctx, stop
+}
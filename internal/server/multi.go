@@ -0,0 +1,86 @@
+package server
+
+import (
+	"ferrodb/internal/engine"
+)
+
+// transaction adalah antrian command milik client antara MULTI dan EXEC.
+type transaction struct {
+	queue   [][]string
+	aborted bool // ada error saat antri, EXEC akan EXECABORT
+}
+
+// runsInMulti menandai command yang tetap dieksekusi langsung (tidak
+// di-antri) ketika client berada di dalam MULTI.
+func runsInMulti(cmd *engine.Command) bool {
+	switch cmd.Name {
+	case "MULTI", "EXEC", "DISCARD", "WATCH", "QUIT", "EXIT":
+		return true
+	}
+	return false
+}
+
+func (s *TCPServer) multi(client *Client) engine.Reply {
+	if client.tx != nil {
+		return engine.Error("ERR MULTI calls can not be nested")
+	}
+	client.tx = &transaction{}
+	return engine.OK()
+}
+
+// exec menjalankan antrian secara atomic. Reply nil array bila ada key
+// WATCH yang berubah sejak di-watch.
+func (s *TCPServer) exec(client *Client) engine.Reply {
+	tx := client.tx
+	if tx == nil {
+		return engine.Error("ERR EXEC without MULTI")
+	}
+	defer s.resetTransaction(client)
+
+	if tx.aborted {
+		return engine.Error("EXECABORT Transaction discarded because of previous errors.")
+	}
+
+	replies := make([]engine.Reply, 0, len(tx.queue))
+	ok := s.engine.Exec(client.watched, func(etx *engine.Tx) {
+		for _, args := range tx.queue {
+			cmd, _ := engine.LookupCommand(args[0])
+			if cmd.Has(engine.FlagConnection) {
+				replies = append(replies, s.executeConnection(client, cmd, args))
+				continue
+			}
+			replies = append(replies, etx.Execute(client.db, args))
+		}
+	})
+	if !ok {
+		return engine.NullArray()
+	}
+	return engine.Array(replies...)
+}
+
+func (s *TCPServer) discard(client *Client) engine.Reply {
+	if client.tx == nil {
+		return engine.Error("ERR DISCARD without MULTI")
+	}
+	s.resetTransaction(client)
+	return engine.OK()
+}
+
+func (s *TCPServer) watch(client *Client, keys []string) engine.Reply {
+	if client.tx != nil {
+		return engine.Error("ERR WATCH inside MULTI is not allowed")
+	}
+	client.watched = append(client.watched, s.engine.Watch(client.db, keys)...)
+	return engine.OK()
+}
+
+func (s *TCPServer) unwatch(client *Client) {
+	s.engine.Unwatch(client.watched)
+	client.watched = nil
+}
+
+// resetTransaction membuang antrian MULTI dan semua WATCH milik client.
+func (s *TCPServer) resetTransaction(client *Client) {
+	client.tx = nil
+	s.unwatch(client)
+}
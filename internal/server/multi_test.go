@@ -0,0 +1,132 @@
+package server
+
+import "testing"
+
+func TestMultiExec(t *testing.T) {
+	conn, r := dial(t, newTestServer(t))
+	login(t, conn, r)
+
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "-ERR MULTI calls can not be nested")
+
+	send(t, conn, command("SET", "a", "1"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("INCR", "a"))
+	expectLine(t, r, "+QUEUED")
+	// error saat runtime tidak membatalkan command lain
+	send(t, conn, command("LPUSH", "a", "x"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("GET", "a"))
+	expectLine(t, r, "+QUEUED")
+
+	send(t, conn, command("EXEC"))
+	expectLines(t, r,
+		"*4",
+		"+OK",
+		":2",
+		"-WRONGTYPE Operation against a key holding the wrong kind of value",
+		"$1", "2",
+	)
+
+	send(t, conn, command("EXEC"))
+	expectLine(t, r, "-ERR EXEC without MULTI")
+}
+
+func TestMultiAbort(t *testing.T) {
+	conn, r := dial(t, newTestServer(t))
+	login(t, conn, r)
+
+	// error saat antri membuat EXEC gagal tanpa menjalankan apa pun
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("SET", "a", "1"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("NOSUCHCMD"))
+	expectLine(t, r, "-ERR unknown command 'NOSUCHCMD'")
+	send(t, conn, command("GET"))
+	expectLine(t, r, "-ERR wrong number of arguments for 'get' command")
+	send(t, conn, command("EXEC"))
+	expectLine(t, r, "-EXECABORT Transaction discarded because of previous errors.")
+
+	send(t, conn, command("EXISTS", "a"))
+	expectLine(t, r, ":0")
+
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("SET", "a", "1"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("DISCARD"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("EXISTS", "a"))
+	expectLine(t, r, ":0")
+
+	send(t, conn, command("DISCARD"))
+	expectLine(t, r, "-ERR DISCARD without MULTI")
+}
+
+func TestWatch(t *testing.T) {
+	s := newTestServer(t)
+	conn, r := dial(t, s)
+	login(t, conn, r)
+	other, or := dial(t, s)
+	login(t, other, or)
+
+	send(t, conn, command("SET", "k", "v"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("WATCH", "k", "missing"))
+	expectLine(t, r, "+OK")
+
+	// write dari client lain setelah WATCH membatalkan EXEC
+	send(t, other, command("SET", "k", "other"))
+	expectLine(t, or, "+OK")
+
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("WATCH", "k"))
+	expectLine(t, r, "-ERR WATCH inside MULTI is not allowed")
+	send(t, conn, command("SET", "k", "mine"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("EXEC"))
+	expectLine(t, r, "*-1")
+
+	send(t, conn, command("GET", "k"))
+	expectLines(t, r, "$5", "other")
+
+	// EXEC melepas semua WATCH, transaksi berikutnya berjalan
+	send(t, other, command("SET", "k", "again"))
+	expectLine(t, or, "+OK")
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("SET", "k", "mine"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("EXEC"))
+	expectLines(t, r, "*1", "+OK")
+
+	// key watched yang belum ada lalu dibuat juga membatalkan EXEC
+	send(t, conn, command("WATCH", "missing"))
+	expectLine(t, r, "+OK")
+	send(t, other, command("SET", "missing", "x"))
+	expectLine(t, or, "+OK")
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("DEL", "missing"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("EXEC"))
+	expectLine(t, r, "*-1")
+
+	// UNWATCH melepas key sehingga write berikutnya tidak berpengaruh
+	send(t, conn, command("WATCH", "k"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("UNWATCH"))
+	expectLine(t, r, "+OK")
+	send(t, other, command("SET", "k", "late"))
+	expectLine(t, or, "+OK")
+	send(t, conn, command("MULTI"))
+	expectLine(t, r, "+OK")
+	send(t, conn, command("GET", "k"))
+	expectLine(t, r, "+QUEUED")
+	send(t, conn, command("EXEC"))
+	expectLines(t, r, "*1", "$4", "late")
+}
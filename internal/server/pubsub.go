@@ -0,0 +1,195 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"ferrodb/internal/engine"
+	"ferrodb/internal/pubsub"
+)
+
+// subscriberBuffer adalah jumlah pesan yang boleh antri per subscriber.
+// Subscriber yang tertinggal lebih jauh dari ini diputus agar PUBLISH
+// tidak pernah menunggu client yang macet.
+const subscriberBuffer = 1024
+
+// allowedWhileSubscribed adalah command yang boleh dipakai selama koneksi
+// masih punya subscription (RESP2).
+func allowedWhileSubscribed(cmd *engine.Command) bool {
+	switch cmd.Name {
+	case "SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE",
+		"PING", "QUIT", "EXIT":
+		return true
+	}
+	return false
+}
+
+func (s *TCPServer) subscribed(client *Client) bool {
+	return client.sub != nil && s.pubsub.Count(client.sub) > 0
+}
+
+// subscriber membuat subscriber milik client saat SUBSCRIBE pertama dan
+// menjalankan goroutine yang meneruskan pesan ke koneksi.
+func (s *TCPServer) subscriber(client *Client) *pubsub.Subscriber {
+	if client.sub != nil {
+		return client.sub
+	}
+
+	conn := client.conn
+	client.sub = pubsub.NewSubscriber(subscriberBuffer, func() {
+		log.Println("pubsub: disconnecting slow subscriber", conn.RemoteAddr())
+		conn.Close()
+	})
+	client.subStop = make(chan struct{})
+
+	go s.forwardMessages(client, client.sub, client.subStop)
+	return client.sub
+}
+
+func (s *TCPServer) forwardMessages(client *Client, sub *pubsub.Subscriber, stop <-chan struct{}) {
+	for {
+		select {
+		case msg := <-sub.C:
+			client.wmu.Lock()
+			writeClientReply(client, messageReply(msg))
+			client.wmu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// closeSubscriber mencabut semua subscription saat koneksi ditutup.
+func (s *TCPServer) closeSubscriber(client *Client) {
+	if client.sub == nil {
+		return
+	}
+	s.pubsub.Remove(client.sub)
+	close(client.subStop)
+	client.sub = nil
+}
+
+func messageReply(msg pubsub.Message) engine.Reply {
+	if msg.Pattern != "" {
+		return engine.Array(
+			engine.Bulk("pmessage"),
+			engine.Bulk(msg.Pattern),
+			engine.Bulk(msg.Channel),
+			engine.Bulk(msg.Payload),
+		)
+	}
+	return engine.Array(
+		engine.Bulk("message"),
+		engine.Bulk(msg.Channel),
+		engine.Bulk(msg.Payload),
+	)
+}
+
+func subscriptionReply(kind string, name engine.Reply, count int) engine.Reply {
+	return engine.Array(engine.Bulk(kind), name, engine.Integer(int64(count)))
+}
+
+func (s *TCPServer) subscribe(client *Client, cmd string, names []string) engine.Reply {
+	sub := s.subscriber(client)
+	kind := strings.ToLower(cmd)
+
+	replies := make([]engine.Reply, 0, len(names))
+	for _, name := range names {
+		var count int
+		if cmd == "PSUBSCRIBE" {
+			count = s.pubsub.PSubscribe(sub, name)
+		} else {
+			count = s.pubsub.Subscribe(sub, name)
+		}
+		replies = append(replies, subscriptionReply(kind, engine.Bulk(name), count))
+	}
+	return engine.Multi(replies...)
+}
+
+// unsubscribe tanpa argumen mencabut semua channel (atau pattern).
+func (s *TCPServer) unsubscribe(client *Client, cmd string, names []string) engine.Reply {
+	kind := strings.ToLower(cmd)
+	pattern := cmd == "PUNSUBSCRIBE"
+
+	if client.sub == nil {
+		if len(names) == 0 {
+			return subscriptionReply(kind, engine.Null(), 0)
+		}
+		replies := make([]engine.Reply, 0, len(names))
+		for _, name := range names {
+			replies = append(replies, subscriptionReply(kind, engine.Bulk(name), 0))
+		}
+		return engine.Multi(replies...)
+	}
+
+	if len(names) == 0 {
+		if pattern {
+			names = s.pubsub.Patterns(client.sub)
+		} else {
+			names = s.pubsub.Channels(client.sub)
+		}
+		if len(names) == 0 {
+			return subscriptionReply(kind, engine.Null(), s.pubsub.Count(client.sub))
+		}
+	}
+
+	replies := make([]engine.Reply, 0, len(names))
+	for _, name := range names {
+		var count int
+		if pattern {
+			count = s.pubsub.PUnsubscribe(client.sub, name)
+		} else {
+			count = s.pubsub.Unsubscribe(client.sub, name)
+		}
+		replies = append(replies, subscriptionReply(kind, engine.Bulk(name), count))
+	}
+	return engine.Multi(replies...)
+}
+
+func (s *TCPServer) pubsubCommand(args []string) engine.Reply {
+	switch strings.ToUpper(args[1]) {
+	case "CHANNELS":
+		if len(args) > 3 {
+			return engine.Error("ERR wrong number of arguments for 'pubsub|channels' command")
+		}
+		pattern := ""
+		if len(args) == 3 {
+			pattern = args[2]
+		}
+		return engine.BulkArray(s.pubsub.ActiveChannels(pattern))
+
+	case "NUMSUB":
+		out := make([]engine.Reply, 0, 2*(len(args)-2))
+		for _, channel := range args[2:] {
+			out = append(out,
+				engine.Bulk(channel),
+				engine.Integer(int64(s.pubsub.NumSub(channel))),
+			)
+		}
+		return engine.Array(out...)
+
+	case "NUMPAT":
+		if len(args) != 2 {
+			return engine.Error("ERR wrong number of arguments for 'pubsub|numpat' command")
+		}
+		return engine.Integer(int64(s.pubsub.NumPat()))
+	}
+
+	return engine.Errorf("ERR unknown subcommand '%s'. Try PUBSUB HELP.", args[1])
+}
+
+// subscribedPing adalah PING di mode subscribe: ["pong", message].
+func subscribedPing(args []string) engine.Reply {
+	msg := ""
+	if len(args) > 1 {
+		msg = args[1]
+	}
+	return engine.Array(engine.Bulk("pong"), engine.Bulk(msg))
+}
+
+func subscribedError(cmd *engine.Command) engine.Reply {
+	return engine.Errorf(
+		"ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context",
+		strings.ToLower(cmd.Name),
+	)
+}
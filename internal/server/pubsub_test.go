@@ -0,0 +1,62 @@
+package server
+
+import "testing"
+
+func TestSubscribeReplies(t *testing.T) {
+	s := newTestServer(t)
+	conn, r := dial(t, s)
+	login(t, conn, r)
+	pub, pr := dial(t, s)
+	login(t, pub, pr)
+
+	send(t, conn, command("SUBSCRIBE", "a", "b"))
+	expectLines(t, r,
+		"*3", "$9", "subscribe", "$1", "a", ":1",
+		"*3", "$9", "subscribe", "$1", "b", ":2",
+	)
+	send(t, conn, command("PSUBSCRIBE", "news.*"))
+	expectLines(t, r, "*3", "$10", "psubscribe", "$6", "news.*", ":3")
+
+	// hanya command pub/sub dan PING yang boleh selama subscribe
+	send(t, conn, command("GET", "k"))
+	expectLine(t, r, "-ERR Can't execute 'get': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context")
+	send(t, conn, command("PING"))
+	expectLines(t, r, "*2", "$4", "pong", "$0", "")
+
+	send(t, pub, command("PUBLISH", "a", "hello"))
+	expectLine(t, pr, ":1")
+	expectLines(t, r, "*3", "$7", "message", "$1", "a", "$5", "hello")
+
+	send(t, pub, command("PUBLISH", "news.x", "hi"))
+	expectLine(t, pr, ":1")
+	expectLines(t, r, "*4", "$8", "pmessage", "$6", "news.*", "$6", "news.x", "$2", "hi")
+
+	send(t, pub, command("PUBSUB", "NUMSUB", "a", "c"))
+	expectLines(t, pr, "*4", "$1", "a", ":1", "$1", "c", ":0")
+
+	// UNSUBSCRIBE tanpa argumen mencabut semua channel, pattern tetap ada
+	send(t, conn, command("UNSUBSCRIBE"))
+	expectLines(t, r,
+		"*3", "$11", "unsubscribe", "$1", "a", ":2",
+		"*3", "$11", "unsubscribe", "$1", "b", ":1",
+	)
+	send(t, conn, command("UNSUBSCRIBE"))
+	expectLines(t, r, "*3", "$11", "unsubscribe", "$-1", ":1")
+
+	send(t, conn, command("PUNSUBSCRIBE", "news.*"))
+	expectLines(t, r, "*3", "$12", "punsubscribe", "$6", "news.*", ":0")
+
+	// tanpa subscription command biasa kembali diizinkan
+	send(t, conn, command("GET", "k"))
+	expectLine(t, r, "$-1")
+}
+
+func TestUnsubscribeWithoutSubscriptions(t *testing.T) {
+	conn, r := dial(t, newTestServer(t))
+	login(t, conn, r)
+
+	send(t, conn, command("PUNSUBSCRIBE"))
+	expectLines(t, r, "*3", "$12", "punsubscribe", "$-1", ":0")
+	send(t, conn, command("UNSUBSCRIBE", "a"))
+	expectLines(t, r, "*3", "$11", "unsubscribe", "$1", "a", ":0")
+}
@@ -1,60 +1,56 @@
 package server
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"strconv"
-	"strings"
-)
-
-func readRESP(reader *bufio.Reader) ([]string, error) {
-	line, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, err
-	}
-
-	line = strings.TrimSpace(line)
-	if !strings.HasPrefix(line, "*") {
-		return nil, fmt.Errorf("not RESP")
-	}
-
-	n, _ := strconv.Atoi(line[1:])
-	args := make([]string, 0, n)
-
-	for i := 0; i < n; i++ {
-		// read $len
-		if _, err := reader.ReadString('\n'); err != nil {
-			return nil, err
-		}
-
-		// read data
-		data, err := reader.ReadString('\n')
-		if err != nil {
-			return nil, err
-		}
-		args = append(args, strings.TrimSpace(data))
-	}
-
-	return args, nil
-}
 
-func writeSimpleString(w io.Writer, s string) {
-	fmt.Fprintf(w, "+%s\r\n", s)
-}
+	"ferrodb/internal/engine"
+)
 
 func writeError(w io.Writer, s string) {
 	fmt.Fprintf(w, "-%s\r\n", s)
 }
 
-func writeInteger(w io.Writer, n int64) {
-	fmt.Fprintf(w, ":%d\r\n", n)
+// writeReply meng-encode Reply sebagai RESP2 dan menulisnya sekaligus.
+func writeReply(w io.Writer, r engine.Reply) {
+	w.Write(appendReply(nil, r))
 }
 
-func writeBulkString(w io.Writer, s string) {
-	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
-}
-
-func writeNull(w io.Writer) {
-	fmt.Fprint(w, "$-1\r\n")
+func appendReply(buf []byte, r engine.Reply) []byte {
+	switch r.Kind {
+	case engine.ReplySimple:
+		buf = append(buf, '+')
+		buf = append(buf, r.Str...)
+	case engine.ReplyError:
+		buf = append(buf, '-')
+		buf = append(buf, r.Str...)
+	case engine.ReplyInteger:
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, r.Int, 10)
+	case engine.ReplyBulk:
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(r.Str)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, r.Str...)
+	case engine.ReplyNull:
+		buf = append(buf, "$-1"...)
+	case engine.ReplyNullArray:
+		buf = append(buf, "*-1"...)
+	case engine.ReplyArray, engine.ReplyMap:
+		// RESP2 tidak punya map, dikirim sebagai array key/value datar
+		buf = append(buf, '*')
+		buf = strconv.AppendInt(buf, int64(len(r.Elems)), 10)
+		buf = append(buf, "\r\n"...)
+		for _, el := range r.Elems {
+			buf = appendReply(buf, el)
+		}
+		return buf
+	case engine.ReplyMulti:
+		for _, el := range r.Elems {
+			buf = appendReply(buf, el)
+		}
+		return buf
+	}
+	return append(buf, "\r\n"...)
 }
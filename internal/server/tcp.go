@@ -2,6 +2,7 @@ package server
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -9,47 +10,81 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"ferrodb/internal/config"
 	"ferrodb/internal/engine"
+	"ferrodb/internal/glob"
+	"ferrodb/internal/parser"
+	"ferrodb/internal/pubsub"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Batas ukuran request RESP sebelum buffer dialokasikan, agar header
+// seperti "*2147483647" atau "$4000000000" tidak menghabiskan memori.
+const (
+	maxMultiBulkLen = 1024 * 1024
+	maxBulkLen      = 512 << 20
+
+	// bulk lebih besar dari ini dibaca bertahap sesuai data yang benar
+	// benar datang, bukan dialokasikan sekaligus dari header
+	bulkPreallocLimit = 64 << 10
+)
+
 type TCPServer struct {
 	addr     string
 	engine   *engine.Engine
 	listener net.Listener
 	users    []config.User
 	dbCount  int
+	pubsub   *pubsub.Hub
 }
 
 type Client struct {
 	conn          net.Conn
+	reader        *bufio.Reader
 	authenticated bool
 	user          *config.User
 	db            int
 	resp          bool
+	closing       bool
+
+	tx      *transaction        // non-nil setelah MULTI
+	watched []engine.WatchedKey // key dari WATCH
+
+	// wmu menyerialkan write ke conn antara reply command dan pesan
+	// pub/sub yang diteruskan goroutine lain
+	wmu     sync.Mutex
+	sub     *pubsub.Subscriber
+	subStop chan struct{}
 }
 
-var rolePermissions = map[string]map[string]bool{
+// roleRules memetakan role ke kategori ACL command table (lihat
+// engine.Command.ACLCategories). Rule dievaluasi berurutan; "-@x" menang
+// atas "+@y" yang muncul sebelumnya. Rule "~pattern" membatasi key yang
+// boleh disentuh (glob Redis).
+var roleRules = map[string][]string{
 	"admin": {
-		"*": true,
+		"+@all",
+		"~*",
 	},
 	"writer": {
-		"SET":     true,
-		"DEL":     true,
-		"EXPIRE":  true,
-		"PERSIST": true,
-		"SELECT":  true,
-		"INFO":    true,
-		"HELP":    true,
+		"+@read",
+		"+@write",
+		"+@connection",
+		"+@transaction",
+		"+@pubsub",
+		"-@dangerous",
+		"~*",
 	},
 	"reader": {
-		"GET":  true,
-		"TTL":  true,
-		"INFO": true,
-		"HELP": true,
+		"+@read",
+		"+@connection",
+		"+@transaction",
+		"+@pubsub",
+		"-@dangerous",
+		"~*",
 	},
 }
 
@@ -64,6 +99,7 @@ func NewTCPServer(
 		users:   users,
 		dbCount: dbCount,
 		engine:  engine,
+		pubsub:  engine.PubSub(),
 	}
 }
 
@@ -93,11 +129,15 @@ func (s *TCPServer) Start() error {
 func (s *TCPServer) handleConnection(conn net.Conn) {
 	defer conn.Close()
 
+	reader := bufio.NewReader(conn)
+
 	client := &Client{
-		db: 0,
+		conn:   conn,
+		reader: reader,
+		db:     0,
 	}
-
-	reader := bufio.NewReader(conn)
+	defer s.resetTransaction(client)
+	defer s.closeSubscriber(client)
 
 	for {
 		line, err := reader.ReadString('\n')
@@ -115,8 +155,10 @@ func (s *TCPServer) handleConnection(conn net.Conn) {
 			client.resp = true
 			args, err := readRESPFromLine(reader, line)
 			if err != nil {
-				writeError(conn, "ERR invalid RESP")
-				continue
+				// sisa stream tidak bisa disinkronkan lagi, koneksi ditutup
+				// seperti Redis
+				writeError(conn, "ERR Protocol error: "+err.Error())
+				return
 			}
 			s.handleRESP(conn, client, args)
 			continue
@@ -133,15 +175,6 @@ func (s *TCPServer) Shutdown() {
 	}
 }
 
-func isPublicCommand(cmd string) bool {
-	switch cmd {
-	case "HELP", "INFO", "PING", "ECHO", "COMMAND", "HELLO", "EXIT":
-		return true
-	default:
-		return false
-	}
-}
-
 func writePrompt(conn net.Conn, db int) {
 	fmt.Fprintf(conn, "%d> ", db)
 }
@@ -163,267 +196,304 @@ func (s *TCPServer) findUser(username, password string) *config.User {
 	return nil
 }
 
-func hasPermission(role, command string) bool {
-	switch command {
-	case "AUTH", "HELP", "INFO", "ACL", "LOGOUT":
+func hasPermission(role string, cmd *engine.Command) bool {
+	// command publik (PING, HELP, INFO, ...) selalu boleh
+	if cmd.Has(engine.FlagNoAuth) {
 		return true
 	}
 
-	perms := rolePermissions[role]
-	if perms["*"] {
-		return true
+	allowed := false
+	for _, rule := range roleRules[role] {
+		if len(rule) < 3 || rule[1] != '@' {
+			continue
+		}
+
+		cat := rule[2:]
+		if cat != "all" && !cmd.InCategory(cat) {
+			continue
+		}
+		allowed = rule[0] == '+'
 	}
-	return perms[command]
+	return allowed
 }
 
-// func (s *TCPServer) handleCommandInline(
-// 	conn net.Conn,
-// 	client *Client,
-// 	line string,
-// ) {
-// 	result := s.execute(client, strings.Fields(line))
-// 	fmt.Fprintln(conn, result)
-// 	writePrompt(conn, client.db)
-// }
-
-// func (s *TCPServer) handleCommandRESP(
-// 	conn net.Conn,
-// 	client *Client,
-// 	args []string,
-// ) {
-// 	result, kind := s.executeRESP(client, args)
-
-// 	switch kind {
-// 	case "ok":
-// 		writeSimpleString(conn, result)
-// 	case "err":
-// 		writeError(conn, result)
-// 	case "int":
-// 		n, _ := strconv.ParseInt(result, 10, 64)
-// 		writeInteger(conn, n)
-// 	case "bulk":
-// 		if result == "" {
-// 			writeNull(conn)
-// 		} else {
-// 			writeBulkString(conn, result)
-// 		}
-// 	}
-// }
-
-// func (s *TCPServer) executeRESP(client *Client, args []string) (string, string) {
-// 	cmd := strings.ToUpper(args[0])
-
-// 	result := s.engine.Execute(client.db, strings.Join(args, " "))
-
-// 	switch cmd {
-// 	case "GET":
-// 		if result == "(nil)" {
-// 			return "", "bulk"
-// 		}
-// 		return result, "bulk"
-
-// 	case "TTL":
-// 		return result, "int"
-
-// 	default:
-// 		if strings.HasPrefix(result, "ERR") {
-// 			return result, "err"
-// 		}
-// 		return result, "ok"
-// 	}
-// }
-
-func (s *TCPServer) execute(
-	client *Client,
-	args []string,
-) (string, string) {
-
-	cmd := strings.ToUpper(args[0])
+// keyPermission memeriksa setiap key terhadap rule "~pattern" milik role.
+func keyPermission(role string, keys []string) bool {
+	for _, key := range keys {
+		allowed := false
+		for _, rule := range roleRules[role] {
+			if strings.HasPrefix(rule, "~") && glob.Match(rule[1:], key) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
 
-	// ===== RESP CORE =====
+func (s *TCPServer) execute(client *Client, args []string) engine.Reply {
+	if len(args) == 0 {
+		return engine.Error("ERR empty command")
+	}
 
-	if cmd == "PING" {
-		if len(args) == 1 {
-			return "PONG", "ok"
+	cmd, errReply := s.check(client, args)
+	if cmd == nil {
+		// error saat antri membatalkan seluruh transaksi (EXECABORT)
+		if client.tx != nil {
+			client.tx.aborted = true
 		}
-		return args[1], "bulk"
+		return errReply
 	}
 
-	if cmd == "ECHO" {
-		if len(args) < 2 {
-			return "", "bulk"
+	// ===== SUBSCRIBED =====
+	if s.subscribed(client) {
+		if !allowedWhileSubscribed(cmd) {
+			return subscribedError(cmd)
+		}
+		if cmd.Name == "PING" {
+			return subscribedPing(args)
 		}
-		return args[1], "bulk"
 	}
 
-	if cmd == "COMMAND" {
-		if len(args) > 1 {
-			return "*0\r\n", "raw"
-		}
+	// ===== MULTI =====
+	if client.tx != nil && !runsInMulti(cmd) {
+		client.tx.queue = append(client.tx.queue, args)
+		return engine.Simple("QUEUED")
+	}
+
+	return s.dispatch(client, cmd, args)
+}
 
-		return "*1\r\n" +
-				"*6\r\n" +
-				"$4\r\nping\r\n" +
-				":-1\r\n" +
-				"*1\r\n$4\r\nfast\r\n" +
-				":0\r\n" +
-				":0\r\n" +
-				":0\r\n",
-			"raw"
+// check memvalidasi command, arity, AUTH dan permission.
+func (s *TCPServer) check(client *Client, args []string) (*engine.Command, engine.Reply) {
+	cmd, ok := engine.LookupCommand(args[0])
+	if !ok {
+		return nil, engine.Errorf("ERR unknown command '%s'", args[0])
 	}
 
-	if cmd == "HELLO" {
-		return "*2\r\n$6\r\nserver\r\n$7\r\nferrodb\r\n", "raw"
+	if !cmd.CheckArity(len(args)) {
+		return nil, engine.Errorf(
+			"ERR wrong number of arguments for '%s' command",
+			strings.ToLower(cmd.Name),
+		)
+	}
+
+	// ===== PUBLIC =====
+	if !client.authenticated && !cmd.Has(engine.FlagNoAuth) {
+		return nil, engine.Error("NOAUTH Authentication required")
 	}
 
+	// ===== PERMISSION =====
+	if client.authenticated && !hasPermission(client.user.Role, cmd) {
+		return nil, engine.Error("NOPERM permission denied")
+	}
+
+	if client.authenticated && !keyPermission(client.user.Role, cmd.Keys(args)) {
+		return nil, engine.Error("NOPERM No permissions to access a key")
+	}
+
+	return cmd, engine.Reply{}
+}
+
+func (s *TCPServer) dispatch(client *Client, cmd *engine.Command, args []string) engine.Reply {
+	if cmd.Has(engine.FlagConnection) {
+		return s.executeConnection(client, cmd, args)
+	}
+
+	// ===== BLOCKING =====
+	// connection goroutine parkir di wait queue engine sampai ada data,
+	// timeout, atau client putus
+	if cmd.Has(engine.FlagBlocking) {
+		ctx, stop := watchDisconnect(client.conn, client.reader)
+		defer stop()
+		return s.engine.ExecuteContext(ctx, client.db, args)
+	}
+
+	// ===== ENGINE =====
+	return s.engine.Execute(client.db, args)
+}
+
+// executeConnection menangani command yang butuh state koneksi.
+func (s *TCPServer) executeConnection(
+	client *Client,
+	cmd *engine.Command,
+	args []string,
+) engine.Reply {
+
+	switch cmd.Name {
+
 	// ===== AUTH =====
-	if cmd == "AUTH" {
+	case "AUTH":
 		if client.authenticated {
-			return "ERR already authenticated (use LOGOUT)", "err"
-		}
-		if len(args) < 3 {
-			return "ERR AUTH username password", "err"
+			return engine.Error("ERR already authenticated (use LOGOUT)")
 		}
 		user := s.findUser(args[1], args[2])
 		if user == nil {
-			return "ERR invalid credentials", "err"
+			return engine.Error("ERR invalid credentials")
 		}
 		client.authenticated = true
 		client.user = user
-		return "OK", "ok"
-	}
-
-	// ===== PUBLIC =====
-	if !client.authenticated && !isPublicCommand(cmd) {
-		return "NOAUTH Authentication required", "err"
-	}
-
-	// ===== PERMISSION =====
-	if client.authenticated && !hasPermission(client.user.Role, cmd) {
-		return "NOPERM permission denied", "err"
-	}
+		return engine.OK()
 
 	// ===== LOGOUT =====
-	if cmd == "LOGOUT" {
+	case "LOGOUT":
 		client.authenticated = false
 		client.user = nil
 		client.db = 0
-		return "OK logged out", "ok"
-	}
+		return engine.Simple("OK logged out")
 
 	// ===== QUIT / EXIT =====
-	if cmd == "EXIT" && !client.resp {
-		return "Bye 👋", "close"
-	}
+	case "QUIT", "EXIT":
+		client.closing = true
+		if client.resp {
+			return engine.OK()
+		}
+		return engine.Simple("Bye 👋")
 
 	// ===== SELECT =====
-	if cmd == "SELECT" {
-		if len(args) < 2 {
-			return "ERR SELECT index", "err"
-		}
+	case "SELECT":
 		db, err := strconv.Atoi(args[1])
 		if err != nil || db < 0 || db >= s.dbCount {
-			return "ERR invalid DB index", "err"
+			return engine.Error("ERR invalid DB index")
 		}
 		client.db = db
-		return "OK", "ok"
-	}
+		return engine.OK()
 
-	// ===== ACL =====
-	if cmd == "ACL" {
-		if len(args) < 2 {
-			return "ERR ACL subcommand required", "err"
+	// ===== HELLO =====
+	case "HELLO":
+		if len(args) > 1 && args[1] != "2" {
+			return engine.Error("NOPROTO unsupported protocol version")
 		}
-		sub := strings.ToUpper(args[1])
-
-		switch sub {
-		case "WHOAMI":
-			return fmt.Sprintf(
-				"user=%s role=%s",
-				client.user.Username,
-				client.user.Role,
-			), "bulk"
-
-		case "CAT":
-			perms := rolePermissions[client.user.Role]
-			var out []string
-			for p := range perms {
-				out = append(out, p)
-			}
-			return strings.Join(out, " "), "bulk"
+		return engine.Map(
+			engine.Bulk("server"), engine.Bulk("ferrodb"),
+			engine.Bulk("version"), engine.Bulk(engine.Version),
+			engine.Bulk("proto"), engine.Integer(2),
+			engine.Bulk("mode"), engine.Bulk("standalone"),
+		)
 
-		default:
-			return "ERR unknown ACL subcommand", "err"
-		}
+	// ===== ACL =====
+	case "ACL":
+		return s.aclCommand(client, args)
+
+	// ===== TRANSACTION =====
+	case "MULTI":
+		return s.multi(client)
+	case "EXEC":
+		return s.exec(client)
+	case "DISCARD":
+		return s.discard(client)
+	case "WATCH":
+		return s.watch(client, args[1:])
+	case "UNWATCH":
+		s.unwatch(client)
+		return engine.OK()
+
+	// ===== PUB/SUB =====
+	case "SUBSCRIBE", "PSUBSCRIBE":
+		return s.subscribe(client, cmd.Name, args[1:])
+	case "UNSUBSCRIBE", "PUNSUBSCRIBE":
+		return s.unsubscribe(client, cmd.Name, args[1:])
+	case "PUBLISH":
+		return engine.Integer(int64(s.pubsub.Publish(args[1], args[2])))
+	case "PUBSUB":
+		return s.pubsubCommand(args)
 	}
 
-	// ===== ENGINE =====
-	res := s.engine.Execute(client.db, strings.Join(args, " "))
+	return engine.Errorf("ERR unknown command '%s'", args[0])
+}
 
-	if strings.HasPrefix(res, "ERR") {
-		return res, "err"
-	}
+func (s *TCPServer) aclCommand(client *Client, args []string) engine.Reply {
+	switch strings.ToUpper(args[1]) {
+	case "WHOAMI":
+		return engine.Bulk(fmt.Sprintf(
+			"user=%s role=%s",
+			client.user.Username,
+			client.user.Role,
+		))
+
+	case "LIST":
+		var out []string
+		for _, u := range s.users {
+			out = append(out, fmt.Sprintf(
+				"user %s role=%s %s",
+				u.Username,
+				u.Role,
+				strings.Join(roleRules[u.Role], " "),
+			))
+		}
+		return engine.BulkArray(out)
 
-	// RESP-aware type
-	switch cmd {
-	case "GET":
-		if res == "(nil)" {
-			return "", "null"
+	case "CAT":
+		if len(args) == 2 {
+			return engine.BulkArray(engine.ACLCategoryNames())
 		}
-		return res, "bulk"
-	case "TTL":
-		return res, "int"
+
+		cat := strings.TrimPrefix(strings.ToLower(args[2]), "@")
+		var out []string
+		for _, c := range engine.Commands() {
+			if c.InCategory(cat) {
+				out = append(out, strings.ToLower(c.Name))
+			}
+		}
+		if out == nil {
+			return engine.Errorf("ERR Unknown category '%s'", args[2])
+		}
+		return engine.BulkArray(out)
+
 	default:
-		return res, "ok"
+		return engine.Error("ERR unknown ACL subcommand")
 	}
 }
 
 func (s *TCPServer) handleRESP(conn net.Conn, client *Client, args []string) {
-	result, kind := s.execute(client, args)
-
-	switch kind {
-	case "raw":
-		fmt.Fprint(conn, result)
-	case "ok":
-		writeSimpleString(conn, result)
-	case "err":
-		writeError(conn, result)
-	case "int":
-		n, _ := strconv.ParseInt(result, 10, 64)
-		writeInteger(conn, n)
-	case "bulk":
-		if result == "" {
-			writeNull(conn)
-		} else {
-			writeBulkString(conn, result)
-		}
-	case "null":
-		writeNull(conn)
-	case "close":
-		writeSimpleString(conn, result)
+	client.wmu.Lock()
+	writeReply(conn, s.execute(client, args))
+	client.wmu.Unlock()
+
+	if client.closing {
 		conn.Close()
 	}
 }
 
 func (s *TCPServer) handleInline(conn net.Conn, client *Client, line string) {
-	args := strings.Fields(line)
+	args, err := parser.SplitArgs(line)
+	if err != nil {
+		fmt.Fprintln(conn, "ERR Protocol error: unbalanced quotes in request")
+		maybePrompt(conn, client)
+		return
+	}
 	if len(args) == 0 {
 		maybePrompt(conn, client)
 		return
 	}
 
-	result, kind := s.execute(client, args)
+	client.wmu.Lock()
+	defer client.wmu.Unlock()
 
-	if kind == "close" {
-		fmt.Fprintln(conn, result)
+	result := s.execute(client, args)
+	fmt.Fprintln(conn, result.String())
+
+	if client.closing {
 		conn.Close()
 		return
 	}
 
-	fmt.Fprintln(conn, result)
 	maybePrompt(conn, client)
 }
 
+// writeClientReply menulis reply sesuai protokol client. Pemanggil wajib
+// memegang client.wmu.
+func writeClientReply(client *Client, r engine.Reply) {
+	if client.resp {
+		writeReply(client.conn, r)
+		return
+	}
+	fmt.Fprintln(client.conn, r.String())
+}
+
 func readRESPFromLine(
 	r *bufio.Reader,
 	firstLine string,
@@ -435,11 +505,11 @@ func readRESPFromLine(
 	}
 
 	count, err := strconv.Atoi(firstLine[1:])
-	if err != nil || count < 0 {
-		return nil, fmt.Errorf("invalid RESP array length")
+	if err != nil || count < 0 || count > maxMultiBulkLen {
+		return nil, fmt.Errorf("invalid multibulk length")
 	}
 
-	args := make([]string, 0, count)
+	args := make([]string, 0, min(count, 64))
 
 	for i := 0; i < count; i++ {
 		// expect: $<len>
@@ -454,23 +524,40 @@ func readRESPFromLine(
 		}
 
 		size, err := strconv.Atoi(line[1:])
-		if err != nil || size < 0 {
-			return nil, fmt.Errorf("invalid bulk size")
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, fmt.Errorf("invalid bulk length")
 		}
 
-		// read exact <size> bytes + \r\n
-		buf := make([]byte, size+2)
-		if _, err := io.ReadFull(r, buf); err != nil {
+		arg, err := readBulk(r, size)
+		if err != nil {
 			return nil, err
 		}
-
-		arg := string(buf[:size])
 		args = append(args, arg)
 	}
 
 	return args, nil
 }
 
+// readBulk membaca tepat size byte diikuti \r\n.
+func readBulk(r *bufio.Reader, size int) (string, error) {
+	if size <= bulkPreallocLimit {
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:size]), nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(size)+2); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(buf.Bytes()[:size]), nil
+}
+
 func maybePrompt(conn net.Conn, client *Client) {
 	if !client.resp {
 		writePrompt(conn, client.db)
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ferrodb/internal/config"
+	"ferrodb/internal/engine"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "secret"
+
+func newTestServer(t *testing.T) *TCPServer {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Users = []config.User{{Username: "admin", Password: string(hash), Role: "admin"}}
+	cfg.Data.Dir = t.TempDir()
+	cfg.Data.AOFFile = "test.aof"
+	cfg.Data.AppendFsync = "no"
+	cfg.Engine.DBCount = 16
+	cfg.Engine.CleanupIntervalSec = 1
+	cfg.Memory.MaxMemoryPolicy = "noeviction"
+	cfg.Memory.MaxMemorySamples = 5
+
+	e := engine.New(cfg)
+	t.Cleanup(e.Shutdown)
+	return NewTCPServer("", cfg.Users, cfg.Engine.DBCount, e)
+}
+
+// dial menjalankan handleConnection di satu sisi net.Pipe dan
+// mengembalikan sisi client.
+func dial(t *testing.T, s *TCPServer) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	client, conn := net.Pipe()
+	go s.handleConnection(conn)
+	t.Cleanup(func() { client.Close() })
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	return client, bufio.NewReader(client)
+}
+
+// login melakukan AUTH sebagai admin pada koneksi dari dial.
+func login(t *testing.T, conn net.Conn, r *bufio.Reader) {
+	t.Helper()
+
+	send(t, conn, command("AUTH", "admin", testPassword))
+	expectLine(t, r, "+OK")
+}
+
+// command meng-encode args sebagai array RESP.
+func command(args ...string) string {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+	}
+	return b.String()
+}
+
+func send(t *testing.T, conn net.Conn, raw string) {
+	t.Helper()
+	go conn.Write([]byte(raw))
+}
+
+func expectLine(t *testing.T, r *bufio.Reader, want string) {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	if line != want+"\r\n" {
+		t.Fatalf("reply = %q, want %q", line, want+"\r\n")
+	}
+}
+
+// expectLines membaca satu baris reply untuk setiap want secara berurutan.
+func expectLines(t *testing.T, r *bufio.Reader, want ...string) {
+	t.Helper()
+
+	for _, w := range want {
+		expectLine(t, r, w)
+	}
+}
+
+func TestEmptyMultiBulk(t *testing.T) {
+	conn, r := dial(t, newTestServer(t))
+
+	send(t, conn, "*0\r\n*1\r\n$4\r\nPING\r\n")
+	expectLine(t, r, "-ERR empty command")
+	expectLine(t, r, "+PONG")
+}
+
+func TestOversizedRequest(t *testing.T) {
+	cases := []struct {
+		name  string
+		raw   string
+		reply string
+	}{
+		{"multibulk", "*2147483647\r\n", "-ERR Protocol error: invalid multibulk length"},
+		{"bulk", "*1\r\n$4000000000\r\n", "-ERR Protocol error: invalid bulk length"},
+	}
+
+	s := newTestServer(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, r := dial(t, s)
+
+			send(t, conn, tc.raw)
+			expectLine(t, r, tc.reply)
+
+			// koneksi ditutup setelah protocol error
+			if _, err := r.ReadByte(); err != io.EOF {
+				t.Fatalf("connection still open after protocol error (err=%v)", err)
+			}
+		})
+	}
+}
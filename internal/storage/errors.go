@@ -0,0 +1,16 @@
+package storage
+
+import "errors"
+
+// Pesan error mengikuti Redis agar bisa langsung dikirim ke client.
+var (
+	ErrWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	ErrNotInteger = errors.New("ERR value is not an integer or out of range")
+	ErrNotFloat   = errors.New("ERR value is not a valid float")
+	ErrOverflow   = errors.New("ERR increment or decrement would overflow")
+	ErrNaN        = errors.New("ERR increment would produce NaN or Infinity")
+	ErrTooLarge   = errors.New("ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+
+	ErrHashNotInteger = errors.New("ERR hash value is not an integer")
+	ErrHashNotFloat   = errors.New("ERR hash value is not a float")
+)
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrOOM = errors.New("OOM command not allowed when used memory > 'maxmemory'.")
+
+// EvictionPolicy adalah maxmemory-policy gaya Redis.
+type EvictionPolicy int
+
+const (
+	NoEviction EvictionPolicy = iota
+	AllKeysLRU
+	AllKeysLFU
+	AllKeysRandom
+	VolatileLRU
+	VolatileLFU
+	VolatileRandom
+	VolatileTTL
+)
+
+var policyNames = map[EvictionPolicy]string{
+	NoEviction:     "noeviction",
+	AllKeysLRU:     "allkeys-lru",
+	AllKeysLFU:     "allkeys-lfu",
+	AllKeysRandom:  "allkeys-random",
+	VolatileLRU:    "volatile-lru",
+	VolatileLFU:    "volatile-lfu",
+	VolatileRandom: "volatile-random",
+	VolatileTTL:    "volatile-ttl",
+}
+
+func (p EvictionPolicy) String() string {
+	return policyNames[p]
+}
+
+// ParseEvictionPolicy menerima nama policy (case-insensitive); kosong
+// berarti noeviction.
+func ParseEvictionPolicy(s string) (EvictionPolicy, error) {
+	if s == "" {
+		return NoEviction, nil
+	}
+	for p, name := range policyNames {
+		if strings.EqualFold(s, name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid maxmemory policy %q", s)
+}
+
+// volatileOnly: hanya key ber-TTL yang boleh di-evict.
+func (p EvictionPolicy) volatileOnly() bool {
+	return p >= VolatileLRU
+}
+
+// SetMaxMemory mengatur batas memori (0 = tanpa batas) dan policy
+// eviction. Dipanggil sebelum store dipakai client.
+func (m *MemoryStore) SetMaxMemory(limit int64, policy EvictionPolicy, samples int) {
+	if samples <= 0 {
+		samples = defaultSamples
+	}
+	m.maxmemory = limit
+	m.policy = policy
+	m.samples = samples
+}
+
+// UsedMemory mengembalikan perkiraan memori seluruh key (byte).
+func (m *MemoryStore) UsedMemory() int64 {
+	return m.used.Load()
+}
+
+// MemoryStats adalah ringkasan maxmemory untuk INFO.
+type MemoryStats struct {
+	Used        int64
+	MaxMemory   int64
+	Policy      EvictionPolicy
+	EvictedKeys int64
+}
+
+func (m *MemoryStore) MemoryStats() MemoryStats {
+	return MemoryStats{
+		Used:        m.used.Load(),
+		MaxMemory:   m.maxmemory,
+		Policy:      m.policy,
+		EvictedKeys: m.evicted.Load(),
+	}
+}
+
+// evictPoolSize adalah jumlah kandidat terbaik yang diingat antar
+// putaran sampling, sama dengan EVPOOL_SIZE di Redis.
+const evictPoolSize = 16
+
+// evictCandidate adalah satu key hasil sampling beserta skornya; skor
+// terbesar di-evict lebih dulu.
+type evictCandidate struct {
+	shard *shard
+	key   string
+	score int64
+}
+
+// FreeMemory meng-evict key sampai pemakaian memori kembali di bawah
+// maxmemory. ErrOOM dikembalikan bila policy noeviction atau tidak ada
+// lagi key yang boleh di-evict. Setiap key yang di-evict dilaporkan ke
+// hook SetEvictHook.
+func (m *MemoryStore) FreeMemory() error {
+	if m.maxmemory <= 0 || m.used.Load() <= m.maxmemory {
+		return nil
+	}
+	if m.policy == NoEviction {
+		return ErrOOM
+	}
+
+	// satu eviction dalam satu waktu agar writer yang bersamaan tidak
+	// meng-evict berlebihan
+	m.evictMu.Lock()
+	defer m.evictMu.Unlock()
+
+	shards := m.evictableShards()
+	for m.used.Load() > m.maxmemory {
+		if len(shards) == 0 {
+			return ErrOOM
+		}
+
+		m.sampleCandidates(shards)
+		if len(m.pool) == 0 {
+			// shard yang disampling sudah kosong, hitung ulang
+			shards = m.evictableShards()
+			continue
+		}
+
+		best := m.pool[len(m.pool)-1]
+		m.pool = m.pool[:len(m.pool)-1]
+		m.evict(best)
+	}
+	return nil
+}
+
+// evictableShards mengembalikan shard yang masih punya kandidat eviction.
+func (m *MemoryStore) evictableShards() []*shard {
+	var out []*shard
+	for _, s := range m.all {
+		unlock := m.rlockShard(s)
+		n := len(s.data)
+		if m.policy.volatileOnly() {
+			n = len(s.volatile.keys)
+		}
+		unlock()
+
+		if n > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// sampleCandidates mengambil maxmemory_samples key acak dari shards lalu
+// memasukkannya ke eviction pool (approximated LRU/LFU seperti Redis).
+// Pool terurut naik berdasarkan skor sehingga kandidat terbaik ada di akhir.
+// Pemanggil wajib memegang evictMu.
+func (m *MemoryStore) sampleCandidates(shards []*shard) {
+	now := time.Now().UnixMilli()
+
+	for range m.samples {
+		s := shards[rand.Intn(len(shards))]
+
+		unlock := m.rlockShard(s)
+		c, ok := m.sampleShard(s, now)
+		unlock()
+
+		if !ok || slices.ContainsFunc(m.pool, func(p evictCandidate) bool {
+			return p.shard == c.shard && p.key == c.key
+		}) {
+			continue
+		}
+
+		i, _ := slices.BinarySearchFunc(m.pool, c.score, func(p evictCandidate, score int64) int {
+			return cmp.Compare(p.score, score)
+		})
+		if len(m.pool) == evictPoolSize {
+			if i == 0 {
+				continue // lebih buruk dari semua isi pool
+			}
+			m.pool = m.pool[1:]
+			i--
+		}
+		m.pool = slices.Insert(m.pool, i, c)
+	}
+}
+
+// sampleShard memilih satu key acak di shard dan menilainya.
+// Pemanggil wajib memegang read lock shard.
+func (m *MemoryStore) sampleShard(s *shard, now int64) (evictCandidate, bool) {
+	var key string
+	switch {
+	case m.policy.volatileOnly():
+		if len(s.volatile.keys) == 0 {
+			return evictCandidate{}, false
+		}
+		key = s.volatile.random()
+	default:
+		if s.index.length == 0 {
+			return evictCandidate{}, false
+		}
+		key = s.index.byRank(rand.Intn(s.index.length) + 1).member
+	}
+
+	item := s.data[key]
+	c := evictCandidate{shard: s, key: key}
+
+	switch m.policy {
+	case AllKeysLRU, VolatileLRU:
+		c.score = now - item.meta.access.Load() // paling lama idle
+	case AllKeysLFU, VolatileLFU:
+		c.score = 255 - int64(item.meta.decayedFreq(now)) // paling jarang dipakai
+	case VolatileTTL:
+		c.score = -item.ExpireAt // paling cepat expire
+	default:
+		c.score = 0 // random: sampel pertama sudah acak
+	}
+	return c, true
+}
+
+// evict menghapus kandidat bila masih ada.
+func (m *MemoryStore) evict(c evictCandidate) {
+	unlock := m.lockShard(c.shard)
+	defer unlock()
+
+	if _, ok := c.shard.data[c.key]; !ok {
+		return
+	}
+
+	m.remove(c.shard.db, c.key)
+	m.touch(c.shard.db, c.key)
+	m.evicted.Add(1)
+
+	if fn := m.onEvict.Load(); fn != nil {
+		(*fn)(c.shard.db, c.key)
+	}
+}
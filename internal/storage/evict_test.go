@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseEvictionPolicy(t *testing.T) {
+	for p, name := range policyNames {
+		got, err := ParseEvictionPolicy(name)
+		if err != nil || got != p {
+			t.Errorf("ParseEvictionPolicy(%q) = %v, %v", name, got, err)
+		}
+	}
+	if got, err := ParseEvictionPolicy("AllKeys-LRU"); err != nil || got != AllKeysLRU {
+		t.Errorf("ParseEvictionPolicy is case sensitive: %v, %v", got, err)
+	}
+	if got, err := ParseEvictionPolicy(""); err != nil || got != NoEviction {
+		t.Errorf("ParseEvictionPolicy(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseEvictionPolicy("lru"); err == nil {
+		t.Error("ParseEvictionPolicy(lru) accepted an unknown policy")
+	}
+}
+
+// TestEvictionPolicy mengisi sepuluh key berukuran sama, menurunkan
+// maxmemory agar tepat satu key harus keluar, lalu memeriksa key mana yang
+// dipilih policy. Sampel dibuat besar agar pilihan approximated tetap
+// deterministik.
+func TestEvictionPolicy(t *testing.T) {
+	const victim = "k3"
+
+	cases := []struct {
+		policy  EvictionPolicy
+		prepare func(m *MemoryStore, now int64)
+		want    string // "" berarti key mana pun
+	}{
+		{AllKeysLRU, func(m *MemoryStore, now int64) {
+			m.shard(0, victim).data[victim].meta.access.Store(now - 3_600_000)
+		}, victim},
+		{AllKeysLFU, func(m *MemoryStore, now int64) {
+			for i := 0; i < 10; i++ {
+				if key := "k" + strconv.Itoa(i); key != victim {
+					m.shard(0, key).data[key].meta.freq.Store(100)
+				}
+			}
+		}, victim},
+		{VolatileTTL, func(m *MemoryStore, now int64) {
+			for i := 0; i < 10; i++ {
+				m.ExpireAt(0, "k"+strconv.Itoa(i), now+int64(i+10)*60_000, ExpireAlways)
+			}
+			m.ExpireAt(0, victim, now+60_000, ExpireAlways)
+		}, victim},
+		{VolatileLRU, func(m *MemoryStore, now int64) {
+			// key tanpa TTL tidak boleh dipilih walau paling lama idle
+			m.shard(0, "k0").data["k0"].meta.access.Store(now - 7_200_000)
+			m.ExpireAt(0, victim, now+60_000, ExpireAlways)
+		}, victim},
+		{AllKeysRandom, nil, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			m := NewMemoryStore(1, 3600)
+			defer m.Close()
+
+			for i := 0; i < 10; i++ {
+				m.Set(0, "k"+strconv.Itoa(i), "value")
+			}
+			if tc.prepare != nil {
+				tc.prepare(m, time.Now().UnixMilli())
+			}
+
+			var evicted []string
+			m.SetEvictHook(func(db int, key string) { evicted = append(evicted, key) })
+			m.SetMaxMemory(m.UsedMemory()-1, tc.policy, 200)
+
+			if err := m.FreeMemory(); err != nil {
+				t.Fatalf("FreeMemory: %v", err)
+			}
+			if len(evicted) != 1 || (tc.want != "" && evicted[0] != tc.want) {
+				t.Fatalf("evicted %v, want [%s]", evicted, tc.want)
+			}
+			if m.DBSize(0) != 9 || m.MemoryStats().EvictedKeys != 1 {
+				t.Fatalf("DBSize %d, stats %+v", m.DBSize(0), m.MemoryStats())
+			}
+		})
+	}
+}
+
+func TestEvictionOOM(t *testing.T) {
+	cases := []EvictionPolicy{NoEviction, VolatileLRU, VolatileLFU, VolatileRandom, VolatileTTL}
+	for _, policy := range cases {
+		t.Run(policy.String(), func(t *testing.T) {
+			m := NewMemoryStore(1, 3600)
+			defer m.Close()
+
+			// tanpa key ber-TTL policy volatile-* tidak punya kandidat
+			m.Set(0, "a", "v")
+			m.Set(0, "b", "v")
+			m.SetMaxMemory(1, policy, 5)
+
+			if err := m.FreeMemory(); !errors.Is(err, ErrOOM) {
+				t.Fatalf("FreeMemory = %v, want ErrOOM", err)
+			}
+			if m.DBSize(0) != 2 {
+				t.Fatalf("DBSize = %d, want 2", m.DBSize(0))
+			}
+		})
+	}
+
+	// tanpa batas FreeMemory tidak melakukan apa pun
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+	m.Set(0, "a", "v")
+	m.SetMaxMemory(0, NoEviction, 5)
+	if err := m.FreeMemory(); err != nil {
+		t.Fatalf("FreeMemory without maxmemory = %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Parameter active expire, mengikuti activeExpireCycle di Redis.
+const (
+	expireSampleSize   = 20 // key volatile yang dicek per putaran
+	expireStalePercent = 25 // ulangi selama > 25% sampel ternyata expired
+	expireBudgetPerc   = 25 // porsi maksimal interval yang boleh dipakai satu siklus
+)
+
+// volatileSet adalah himpunan key yang punya TTL dalam satu DB. Key disimpan
+// di slice agar sampling acak O(1); pos menunjuk posisinya di slice.
+type volatileSet struct {
+	keys []string
+	pos  map[string]int
+}
+
+func newVolatileSet() *volatileSet {
+	return &volatileSet{pos: make(map[string]int)}
+}
+
+func (s *volatileSet) add(key string) {
+	if _, ok := s.pos[key]; ok {
+		return
+	}
+	s.pos[key] = len(s.keys)
+	s.keys = append(s.keys, key)
+}
+
+// remove menukar key dengan elemen terakhir lalu memotong slice.
+func (s *volatileSet) remove(key string) {
+	i, ok := s.pos[key]
+	if !ok {
+		return
+	}
+
+	last := len(s.keys) - 1
+	s.keys[i] = s.keys[last]
+	s.pos[s.keys[i]] = i
+	s.keys[last] = ""
+	s.keys = s.keys[:last]
+	delete(s.pos, key)
+}
+
+func (s *volatileSet) random() string {
+	return s.keys[rand.Intn(len(s.keys))]
+}
+
+// ExpireStats adalah statistik expiry untuk INFO.
+type ExpireStats struct {
+	ExpiredKeys    int64         // total key yang dihapus karena TTL (lazy + active)
+	StalePercent   float64       // perkiraan persentase key volatile yang sudah expired
+	TimeCapReached int64         // jumlah siklus yang berhenti karena budget waktu habis
+	CycleTime      time.Duration // total waktu yang dipakai siklus active expire
+	LastCycleTime  time.Duration // durasi siklus terakhir
+	VolatileKeys   int           // jumlah key yang punya TTL
+}
+
+type expireStats struct {
+	expired   atomic.Int64
+	stale     atomic.Int64 // StalePercent * 100
+	capped    atomic.Int64
+	cycleTime atomic.Int64 // nanosecond
+	lastCycle atomic.Int64 // nanosecond
+}
+
+// ExpireStats mengembalikan statistik expiry saat ini.
+func (m *MemoryStore) ExpireStats() ExpireStats {
+	volatile := 0
+	for _, s := range m.all {
+		unlock := m.rlockShard(s)
+		volatile += len(s.volatile.keys)
+		unlock()
+	}
+
+	return ExpireStats{
+		ExpiredKeys:    m.stats.expired.Load(),
+		StalePercent:   float64(m.stats.stale.Load()) / 100,
+		TimeCapReached: m.stats.capped.Load(),
+		CycleTime:      time.Duration(m.stats.cycleTime.Load()),
+		LastCycleTime:  time.Duration(m.stats.lastCycle.Load()),
+		VolatileKeys:   volatile,
+	}
+}
+
+func (m *MemoryStore) activeExpire(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	budget := interval * expireBudgetPerc / 100
+	for {
+		select {
+		case <-ticker.C:
+			m.activeExpireCycle(budget)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Close menghentikan active expire. Store tetap bisa dibaca/ditulis,
+// key expired hanya dihapus secara lazy.
+func (m *MemoryStore) Close() {
+	close(m.stop)
+}
+
+// activeExpireCycle mengambil sampel acak dari key volatile tiap shard dan
+// menghapus yang expired. Satu shard diulang selama banyak sampel yang
+// expired, dan siklus berhenti begitu budget habis; siklus berikutnya
+// melanjutkan dari shard terakhir. Lock hanya dipegang per putaran sampel
+// sehingga client lain tidak tertahan selama siklus berjalan.
+func (m *MemoryStore) activeExpireCycle(budget time.Duration) {
+	start := time.Now()
+	sampled, expired := 0, 0
+	capped := false
+
+	for range m.all {
+		s := m.all[m.expireNext]
+		m.expireNext = (m.expireNext + 1) % len(m.all)
+
+		// rasio dihitung kumulatif per shard agar satu sampel yang kebetulan
+		// bersih tidak menghentikan pembersihan terlalu awal
+		shardSampled, shardExpired := 0, 0
+		for {
+			n, x := m.expireSample(s)
+			shardSampled += n
+			shardExpired += x
+
+			if time.Since(start) > budget {
+				capped = true
+				break
+			}
+			if n == 0 || shardExpired*100 <= shardSampled*expireStalePercent {
+				break
+			}
+		}
+		sampled += shardSampled
+		expired += shardExpired
+
+		if capped {
+			break
+		}
+	}
+
+	elapsed := time.Since(start)
+	m.stats.cycleTime.Add(int64(elapsed))
+	m.stats.lastCycle.Store(int64(elapsed))
+	if capped {
+		m.stats.capped.Add(1)
+	}
+
+	// rata-rata bergerak seperti stat_expired_stale_perc di Redis
+	if sampled > 0 {
+		perc := float64(expired) * 100 / float64(sampled)
+		stale := float64(m.stats.stale.Load())/100*0.95 + perc*0.05
+		m.stats.stale.Store(int64(stale * 100))
+	}
+}
+
+// expireSample mengecek satu putaran sampel di shard dan mengembalikan
+// jumlah key yang dicek dan yang dihapus.
+func (m *MemoryStore) expireSample(s *shard) (sampled, expired int) {
+	// lock langsung tanpa lockShard: shard baru disalin untuk snapshot bila
+	// memang ada key yang dihapus
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := s.volatile
+	now := time.Now().UnixMilli()
+
+	check := func(key string) {
+		sampled++
+		if m.isExpired(s.data[key], now) {
+			s.copyOnWrite()
+			m.expire(s.db, key)
+			expired++
+		}
+	}
+
+	// shard kecil dicek seluruhnya; mundur karena remove menukar dengan
+	// elemen terakhir
+	if len(set.keys) <= expireSampleSize {
+		for i := len(set.keys) - 1; i >= 0; i-- {
+			check(set.keys[i])
+		}
+		return sampled, expired
+	}
+
+	for range expireSampleSize {
+		check(set.random())
+	}
+	return sampled, expired
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVolatileSet(t *testing.T) {
+	s := newVolatileSet()
+	for _, key := range []string{"a", "b", "c", "a"} {
+		s.add(key)
+	}
+	s.remove("a")
+	s.remove("missing")
+
+	if len(s.keys) != 2 || len(s.pos) != 2 {
+		t.Fatalf("keys = %v, pos = %v", s.keys, s.pos)
+	}
+	for i, key := range s.keys {
+		if s.pos[key] != i {
+			t.Fatalf("pos[%q] = %d, want %d", key, s.pos[key], i)
+		}
+	}
+}
+
+// fillVolatile mengisi db 0 dengan n key yang sudah expired dan live key
+// yang TTL-nya masih panjang.
+func fillVolatile(m *MemoryStore, n, live int) {
+	future := time.Now().Add(time.Hour).UnixMilli()
+	for i := 0; i < n; i++ {
+		key := "dead" + strconv.Itoa(i)
+		m.Set(0, key, "v")
+		m.ExpireAt(0, key, 1, ExpireAlways)
+	}
+	for i := 0; i < live; i++ {
+		key := "live" + strconv.Itoa(i)
+		m.Set(0, key, "v")
+		m.ExpireAt(0, key, future, ExpireAlways)
+	}
+}
+
+// TestActiveExpireCycle: satu siklus tanpa batas waktu membersihkan shard
+// sampai sisa sampel yang expired di bawah ambang, tanpa akses ke key.
+func TestActiveExpireCycle(t *testing.T) {
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+
+	fillVolatile(m, 5000, 100)
+	if got := m.ExpireStats().VolatileKeys; got != 5100 {
+		t.Fatalf("VolatileKeys = %d, want 5100", got)
+	}
+
+	m.activeExpireCycle(time.Hour)
+
+	stats := m.ExpireStats()
+	// shard berhenti diulang begitu sampel <= 25% expired, jadi sisa key
+	// expired yang belum tersampel harus kecil dibanding key live
+	if size := m.DBSize(0); size < 100 || size > 200 {
+		t.Fatalf("DBSize after cycle = %d, want close to 100 live keys", size)
+	}
+	if stats.ExpiredKeys < 4900 {
+		t.Fatalf("ExpiredKeys = %d, want most of 5000", stats.ExpiredKeys)
+	}
+	if stats.TimeCapReached != 0 {
+		t.Fatalf("TimeCapReached = %d, want 0", stats.TimeCapReached)
+	}
+	for i := 0; i < 100; i++ {
+		if _, ok, _ := m.Get(0, "live"+strconv.Itoa(i)); !ok {
+			t.Fatalf("live key %d was removed", i)
+		}
+	}
+}
+
+// TestActiveExpireBudget: siklus yang kehabisan budget berhenti lebih awal,
+// dicatat di TimeCapReached, dan siklus berikutnya melanjutkan shard lain.
+func TestActiveExpireBudget(t *testing.T) {
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+
+	fillVolatile(m, 5000, 0)
+
+	m.activeExpireCycle(0)
+	stats := m.ExpireStats()
+	if stats.TimeCapReached != 1 {
+		t.Fatalf("TimeCapReached = %d, want 1", stats.TimeCapReached)
+	}
+	if stats.ExpiredKeys == 0 || stats.ExpiredKeys > expireSampleSize {
+		t.Fatalf("capped cycle expired %d keys, want one sample round", stats.ExpiredKeys)
+	}
+
+	for i := 0; i < 100000 && m.DBSize(0) > 0; i++ {
+		m.activeExpireCycle(0)
+	}
+	if size := m.DBSize(0); size != 0 {
+		t.Fatalf("DBSize after capped cycles = %d, want 0", size)
+	}
+	if stats := m.ExpireStats(); stats.VolatileKeys != 0 || stats.StalePercent <= 0 {
+		t.Fatalf("stats after cleanup = %+v", stats)
+	}
+}
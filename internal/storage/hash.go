@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"math"
+	"strconv"
+)
+
+// writableHash mengembalikan item hash milik key, atau hash kosong baru.
+// Pemanggil wajib memegang write lock.
+func (m *MemoryStore) writableHash(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{Type: TypeHash, Hash: map[string]string{}}, nil
+	}
+	if item.Type != TypeHash {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) lookupHash(db int, key string) (map[string]string, error) {
+	item, err := m.hashItem(db, key)
+	return item.Hash, err
+}
+
+// hashItem seperti lookupHash tetapi mengembalikan item-nya; Hash nil bila
+// key tidak ada.
+func (m *MemoryStore) hashItem(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{}, nil
+	}
+	if item.Type != TypeHash {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+// HSet menulis pasangan field/value dan mengembalikan jumlah field baru.
+// Bila nx true, field yang sudah ada tidak ditimpa.
+func (m *MemoryStore) HSet(db int, key string, pairs []string, nx bool) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.writableHash(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if _, exists := item.Hash[pairs[i]]; exists && nx {
+			continue
+		}
+		if item.hashSet(pairs[i], pairs[i+1]) {
+			added++
+		}
+	}
+
+	if len(item.Hash) > 0 {
+		m.put(db, key, item)
+	}
+	return added, nil
+}
+
+func (m *MemoryStore) HGet(db int, key, field string) (string, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	h, err := m.lookupHash(db, key)
+	if err != nil {
+		return "", false, err
+	}
+
+	val, ok := h[field]
+	return val, ok, nil
+}
+
+func (m *MemoryStore) HMGet(db int, key string, fields []string) ([]string, []bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	h, err := m.lookupHash(db, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]string, len(fields))
+	found := make([]bool, len(fields))
+	for i, f := range fields {
+		values[i], found[i] = h[f]
+	}
+	return values, found, nil
+}
+
+// HGetAll mengembalikan salinan hash, aman dipakai di luar lock.
+func (m *MemoryStore) HGetAll(db int, key string) (map[string]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	h, err := m.lookupHash(db, key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(h))
+	for f, v := range h {
+		out[f] = v
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) HLen(db int, key string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	h, err := m.lookupHash(db, key)
+	return len(h), err
+}
+
+// HDel menghapus field; key ikut dihapus bila hash menjadi kosong.
+func (m *MemoryStore) HDel(db int, key string, fields []string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.hashItem(db, key)
+	if err != nil || item.Hash == nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, f := range fields {
+		if item.hashDel(f) {
+			deleted++
+		}
+	}
+
+	if len(item.Hash) == 0 {
+		m.remove(db, key)
+	}
+	return deleted, nil
+}
+
+func (m *MemoryStore) HIncrBy(db int, key, field string, delta int64) (int64, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.writableHash(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	var cur int64
+	if v, ok := item.Hash[field]; ok {
+		cur, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, ErrHashNotInteger
+		}
+	}
+
+	if (delta > 0 && cur > math.MaxInt64-delta) ||
+		(delta < 0 && cur < math.MinInt64-delta) {
+		return 0, ErrOverflow
+	}
+
+	cur += delta
+	item.hashSet(field, strconv.FormatInt(cur, 10))
+	m.put(db, key, item)
+	return cur, nil
+}
+
+func (m *MemoryStore) HIncrByFloat(db int, key, field string, delta float64) (string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.writableHash(db, key)
+	if err != nil {
+		return "", err
+	}
+
+	var cur float64
+	if v, ok := item.Hash[field]; ok {
+		cur, err = strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(cur) {
+			return "", ErrHashNotFloat
+		}
+	}
+
+	cur += delta
+	if math.IsNaN(cur) || math.IsInf(cur, 0) {
+		return "", ErrNaN
+	}
+
+	val := FormatFloat(cur)
+	item.hashSet(field, val)
+	m.put(db, key, item)
+	return val, nil
+}
+
+// HScan mengembalikan satu batch pasangan field/value mulai dari cursor.
+func (m *MemoryStore) HScan(db int, key string, cursor uint64, count int) (uint64, []string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.hashItem(db, key)
+	if err != nil || item.Hash == nil {
+		return 0, nil, err
+	}
+
+	next, batch := scanBatch(m.scanIndex(db, key, item), cursor, count)
+
+	pairs := make([]string, 0, len(batch)*2)
+	for _, f := range batch {
+		pairs = append(pairs, f, item.Hash[f])
+	}
+	return next, pairs, nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+)
+
+var ErrSameObject = errors.New("ERR source and destination objects are the same")
+
+// Exists menghitung key yang ada; key yang disebut dua kali dihitung dua kali.
+func (m *MemoryStore) Exists(db int, keys ...string) int {
+	unlock := m.lock(db, keys...)
+	defer unlock()
+
+	n := 0
+	for _, key := range keys {
+		if _, ok := m.lookup(db, key); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Rename memindahkan value (beserta TTL) dari src ke dst. Dengan nx,
+// rename batal (false) bila dst sudah ada.
+func (m *MemoryStore) Rename(db int, src, dst string, nx bool) (bool, error) {
+	unlock := m.lock(db, src, dst)
+	defer unlock()
+
+	item, ok := m.lookup(db, src)
+	if !ok {
+		return false, ErrNoSuchKey
+	}
+	if src == dst {
+		return !nx, nil
+	}
+	if _, exists := m.lookup(db, dst); exists && nx {
+		return false, nil
+	}
+
+	m.remove(db, src)
+	m.remove(db, dst)
+	m.put(db, dst, item)
+	m.touch(db, dst)
+	return true, nil
+}
+
+// Copy menyalin src ke dstDB/dst termasuk TTL. Tanpa replace, copy batal
+// (false) bila dst sudah ada.
+func (m *MemoryStore) Copy(db int, src string, dstDB int, dst string, replace bool) (bool, error) {
+	unlock := m.lockShards([]*shard{m.shard(db, src), m.shard(dstDB, dst)})
+	defer unlock()
+
+	if db == dstDB && src == dst {
+		return false, ErrSameObject
+	}
+
+	item, ok := m.lookup(db, src)
+	if !ok {
+		return false, nil
+	}
+	if _, exists := m.lookup(dstDB, dst); exists {
+		if !replace {
+			return false, nil
+		}
+		m.remove(dstDB, dst)
+	}
+
+	m.put(dstDB, dst, item.clone())
+	m.touch(dstDB, dst)
+	return true, nil
+}
+
+// Move memindahkan key ke DB lain. Gagal (false) bila key tidak ada atau
+// sudah ada di DB tujuan.
+func (m *MemoryStore) Move(db int, key string, dstDB int) (bool, error) {
+	unlock := m.lockShards([]*shard{m.shard(db, key), m.shard(dstDB, key)})
+	defer unlock()
+
+	if db == dstDB {
+		return false, ErrSameObject
+	}
+
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return false, nil
+	}
+	if _, exists := m.lookup(dstDB, key); exists {
+		return false, nil
+	}
+
+	m.remove(db, key)
+	m.put(dstDB, key, item)
+	m.touch(dstDB, key)
+	return true, nil
+}
+
+// DBSize mengembalikan jumlah key di DB, termasuk key expired yang belum
+// sempat dibersihkan (sama seperti Redis).
+func (m *MemoryStore) DBSize(db int) int {
+	n := 0
+	for _, s := range m.shards[db] {
+		unlock := m.rlockShard(s)
+		n += len(s.data)
+		unlock()
+	}
+	return n
+}
+
+// Flush mengosongkan DB. Map lama cukup dilepas untuk GC sehingga biayanya
+// O(1) per shard di bawah lock, baik untuk mode SYNC maupun ASYNC.
+func (m *MemoryStore) Flush(db int) {
+	unlock := m.lockShards(slices.Clone(m.shards[db]))
+	defer unlock()
+
+	m.flush(db)
+}
+
+func (m *MemoryStore) FlushAll() {
+	unlock := m.lockShards(slices.Clone(m.all))
+	defer unlock()
+
+	for db := range m.shards {
+		m.flush(db)
+	}
+}
+
+// flush mengosongkan semua shard db.
+// Pemanggil wajib memegang write lock semua shard db.
+func (m *MemoryStore) flush(db int) {
+	for _, s := range m.shards[db] {
+		m.used.Add(-s.used)
+		s.reset()
+	}
+	m.touchAll(db)
+}
+
+// SwapDB menukar isi dua DB. Shard ke-i kedua DB mencakup rentang hash yang
+// sama sehingga isinya bisa ditukar per shard. Versi WATCH tidak ikut
+// ditukar; semua key yang di-WATCH di kedua DB dianggap berubah.
+func (m *MemoryStore) SwapDB(a, b int) {
+	unlock := m.lockShards(append(slices.Clone(m.shards[a]), m.shards[b]...))
+	defer unlock()
+
+	for i, x := range m.shards[a] {
+		y := m.shards[b][i]
+		x.data, y.data = y.data, x.data
+		x.index, y.index = y.index, x.index
+		x.volatile, y.volatile = y.volatile, x.volatile
+		x.used, y.used = y.used, x.used
+	}
+	m.touchAll(a)
+	m.touchAll(b)
+}
+
+// RandomKey memilih key acak secara merata lewat rank di index SCAN
+// gabungan semua shard.
+func (m *MemoryStore) RandomKey(db int) (string, bool) {
+	unlock := m.lockShards(slices.Clone(m.shards[db]))
+	defer unlock()
+
+	// key expired yang terpilih dihapus lalu dicoba lagi
+	for {
+		total := 0
+		for _, s := range m.shards[db] {
+			total += s.index.length
+		}
+		if total == 0 {
+			return "", false
+		}
+
+		rank := rand.Intn(total)
+		for _, s := range m.shards[db] {
+			if rank >= s.index.length {
+				rank -= s.index.length
+				continue
+			}
+
+			x := s.index.byRank(rank + 1)
+			if _, ok := m.lookup(db, x.member); ok {
+				return x.member, true
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package storage
+
+import "errors"
+
+var (
+	ErrNoSuchKey     = errors.New("ERR no such key")
+	ErrIndexOutRange = errors.New("ERR index out of range")
+)
+
+// List adalah deque berbasis ring buffer: push/pop di kedua ujung O(1)
+// dan akses index O(1) untuk LINDEX/LRANGE/LSET.
+type List struct {
+	buf  []string
+	head int
+	size int
+}
+
+func NewList() *List {
+	return &List{}
+}
+
+func (l *List) Len() int {
+	return l.size
+}
+
+func (l *List) grow() {
+	n := len(l.buf) * 2
+	if n == 0 {
+		n = 8
+	}
+
+	buf := make([]string, n)
+	for i := 0; i < l.size; i++ {
+		buf[i] = l.At(i)
+	}
+	l.buf = buf
+	l.head = 0
+}
+
+func (l *List) PushFront(v string) {
+	if l.size == len(l.buf) {
+		l.grow()
+	}
+	l.head = (l.head - 1 + len(l.buf)) % len(l.buf)
+	l.buf[l.head] = v
+	l.size++
+}
+
+func (l *List) PushBack(v string) {
+	if l.size == len(l.buf) {
+		l.grow()
+	}
+	l.buf[(l.head+l.size)%len(l.buf)] = v
+	l.size++
+}
+
+func (l *List) PopFront() string {
+	v := l.buf[l.head]
+	l.buf[l.head] = ""
+	l.head = (l.head + 1) % len(l.buf)
+	l.size--
+	return v
+}
+
+func (l *List) PopBack() string {
+	i := (l.head + l.size - 1) % len(l.buf)
+	v := l.buf[i]
+	l.buf[i] = ""
+	l.size--
+	return v
+}
+
+// At mengembalikan elemen ke-i (0 = paling kiri), i harus valid.
+func (l *List) At(i int) string {
+	return l.buf[(l.head+i)%len(l.buf)]
+}
+
+func (l *List) Set(i int, v string) {
+	l.buf[(l.head+i)%len(l.buf)] = v
+}
+
+// Slice menyalin elemen [start, stop] (inklusif, index sudah dinormalisasi).
+func (l *List) Slice(start, stop int) []string {
+	if start > stop {
+		return []string{}
+	}
+
+	out := make([]string, 0, stop-start+1)
+	for i := start; i <= stop; i++ {
+		out = append(out, l.At(i))
+	}
+	return out
+}
+
+func (l *List) Values() []string {
+	return l.Slice(0, l.size-1)
+}
+
+// replace membangun ulang isi list, dipakai operasi O(n) seperti LREM.
+func (l *List) replace(values []string) {
+	l.buf = values
+	l.head = 0
+	l.size = len(values)
+}
+
+func (l *List) clone() *List {
+	c := &List{}
+	c.replace(l.Values())
+	return c
+}
+
+// normalizeRange mengubah start/stop ala Redis (boleh negatif) menjadi
+// index valid. ok false bila range kosong.
+func normalizeRange(start, stop, n int) (int, int, bool) {
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
+
+// writableList mengembalikan item list milik key, atau list kosong baru.
+// Pemanggil wajib memegang write lock.
+func (m *MemoryStore) writableList(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{Type: TypeList, List: NewList()}, nil
+	}
+	if item.Type != TypeList {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) lookupList(db int, key string) (*List, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return nil, nil
+	}
+	if item.Type != TypeList {
+		return nil, ErrWrongType
+	}
+	return item.List, nil
+}
+
+// Push menambah elemen ke kiri/kanan list dan mengembalikan panjang baru.
+// Bila onlyExisting true (LPUSHX/RPUSHX), key yang belum ada diabaikan.
+func (m *MemoryStore) Push(db int, key string, values []string, left, onlyExisting bool) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	if onlyExisting {
+		l, err := m.lookupList(db, key)
+		if err != nil || l == nil {
+			return 0, err
+		}
+	}
+
+	item, err := m.writableList(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range values {
+		if left {
+			item.List.PushFront(v)
+		} else {
+			item.List.PushBack(v)
+		}
+	}
+
+	m.put(db, key, item)
+	return item.List.Len(), nil
+}
+
+// Pop mengambil sampai count elemen dari kiri/kanan. Key dihapus bila
+// list menjadi kosong.
+func (m *MemoryStore) Pop(db int, key string, left bool, count int) ([]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return nil, err
+	}
+
+	// list yang ada selalu menghasilkan slice non-nil, termasuk count 0,
+	// agar reply-nya array kosong dan bukan null
+	out := make([]string, 0, min(count, l.Len()))
+	for i := 0; i < count && l.Len() > 0; i++ {
+		if left {
+			out = append(out, l.PopFront())
+		} else {
+			out = append(out, l.PopBack())
+		}
+	}
+
+	if l.Len() == 0 {
+		m.remove(db, key)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) LLen(db int, key string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return 0, err
+	}
+	return l.Len(), nil
+}
+
+func (m *MemoryStore) LRange(db int, key string, start, stop int) ([]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return []string{}, err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, l.Len())
+	if !ok {
+		return []string{}, nil
+	}
+	return l.Slice(start, stop), nil
+}
+
+func (m *MemoryStore) LIndex(db int, key string, index int) (string, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return "", false, err
+	}
+
+	if index < 0 {
+		index += l.Len()
+	}
+	if index < 0 || index >= l.Len() {
+		return "", false, nil
+	}
+	return l.At(index), true, nil
+}
+
+func (m *MemoryStore) LSet(db int, key string, index int, value string) error {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		return ErrNoSuchKey
+	}
+
+	if index < 0 {
+		index += l.Len()
+	}
+	if index < 0 || index >= l.Len() {
+		return ErrIndexOutRange
+	}
+
+	l.Set(index, value)
+	return nil
+}
+
+// LRem menghapus elemen bernilai value: count > 0 dari kiri, count < 0
+// dari kanan, count = 0 semuanya.
+func (m *MemoryStore) LRem(db int, key string, count int, value string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return 0, err
+	}
+
+	values := l.Values()
+	keep := make([]bool, len(values))
+	removed := 0
+
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+
+	for n := 0; n < len(values); n++ {
+		i := n
+		if count < 0 {
+			i = len(values) - 1 - n
+		}
+
+		if values[i] == value && (limit == 0 || removed < limit) {
+			removed++
+			continue
+		}
+		keep[i] = true
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	rest := make([]string, 0, len(values)-removed)
+	for i, v := range values {
+		if keep[i] {
+			rest = append(rest, v)
+		}
+	}
+
+	if len(rest) == 0 {
+		m.remove(db, key)
+	} else {
+		l.replace(rest)
+	}
+	return removed, nil
+}
+
+func (m *MemoryStore) LTrim(db int, key string, start, stop int) error {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, l.Len())
+	if !ok {
+		m.remove(db, key)
+		return nil
+	}
+
+	l.replace(l.Slice(start, stop))
+	return nil
+}
+
+// LInsert menyisipkan value sebelum/sesudah pivot pertama. Mengembalikan
+// panjang baru, -1 bila pivot tidak ditemukan, 0 bila key tidak ada.
+func (m *MemoryStore) LInsert(db int, key string, before bool, pivot, value string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	l, err := m.lookupList(db, key)
+	if err != nil || l == nil {
+		return 0, err
+	}
+
+	values := l.Values()
+	for i, v := range values {
+		if v != pivot {
+			continue
+		}
+
+		at := i
+		if !before {
+			at = i + 1
+		}
+
+		rest := make([]string, 0, len(values)+1)
+		rest = append(rest, values[:at]...)
+		rest = append(rest, value)
+		rest = append(rest, values[at:]...)
+		l.replace(rest)
+		return l.Len(), nil
+	}
+	return -1, nil
+}
+
+// LMove memindahkan satu elemen dari ujung src ke ujung dst secara atomik.
+func (m *MemoryStore) LMove(db int, src, dst string, fromLeft, toLeft bool) (string, bool, error) {
+	unlock := m.lock(db, src, dst)
+	defer unlock()
+
+	from, err := m.lookupList(db, src)
+	if err != nil || from == nil {
+		return "", false, err
+	}
+
+	// cek tipe tujuan sebelum mengubah apa pun
+	target, err := m.writableList(db, dst)
+	if err != nil {
+		return "", false, err
+	}
+
+	var v string
+	if fromLeft {
+		v = from.PopFront()
+	} else {
+		v = from.PopBack()
+	}
+	if from.Len() == 0 {
+		m.remove(db, src)
+	}
+
+	// bila src == dst, target.List adalah pointer yang sama dengan from
+	if toLeft {
+		target.List.PushFront(v)
+	} else {
+		target.List.PushBack(v)
+	}
+	m.put(db, dst, target)
+	return v, true, nil
+}
@@ -2,89 +2,376 @@ package storage
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+type ValueType int
+
+const (
+	TypeString ValueType = iota
+	TypeHash
+	TypeList
+	TypeSet
+	TypeZSet
+	TypeStream
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeHash:
+		return "hash"
+	case TypeList:
+		return "list"
+	case TypeSet:
+		return "set"
+	case TypeZSet:
+		return "zset"
+	case TypeStream:
+		return "stream"
+	default:
+		return "none"
+	}
+}
+
+// Item menyimpan satu key. Field yang terisi bergantung pada Type.
 type Item struct {
+	Type     ValueType
 	Value    string
-	ExpireAt int64 // unix timestamp (seconds), 0 = no TTL
+	Hash     map[string]string
+	List     *List
+	Set      map[string]struct{}
+	ZSet     *ZSet
+	Stream   *Stream
+	ExpireAt int64 // unix timestamp (milliseconds), 0 = no TTL
+
+	size int64     // perkiraan memori saat terakhir ditulis, lihat memUsage
+	meta *keyMeta  // statistik akses untuk LRU/LFU
+	scan *skiplist // index HSCAN/SSCAN, nil sampai di-scan; lihat scan.go
+}
+
+// clone menyalin struktur data di dalam item agar snapshot tidak berbagi
+// map dengan store yang masih menerima write.
+func (it Item) clone() Item {
+	it.meta = nil
+	it.scan = nil
+	if it.Hash != nil {
+		h := make(map[string]string, len(it.Hash))
+		for f, v := range it.Hash {
+			h[f] = v
+		}
+		it.Hash = h
+	}
+	if it.List != nil {
+		it.List = it.List.clone()
+	}
+	if it.Set != nil {
+		s := make(map[string]struct{}, len(it.Set))
+		for mem := range it.Set {
+			s[mem] = struct{}{}
+		}
+		it.Set = s
+	}
+	if it.ZSet != nil {
+		it.ZSet = it.ZSet.clone()
+	}
+	if it.Stream != nil {
+		it.Stream = it.Stream.clone()
+	}
+	return it
 }
 
+// MemoryStore adalah keyspace seluruh DB. Nilai yang dikembalikan
+// LockKeys/LockAll adalah view atas keyspace yang sama yang sudah memegang
+// lock sebagian shard; lihat shard.go.
 type MemoryStore struct {
-	data []map[string]Item
-	mu   sync.RWMutex
+	*core
+
+	held    []*shard // shard yang dikunci view ini, terurut menurut id
+	heldAll bool
+}
+
+// core adalah state bersama milik MemoryStore dan semua view-nya.
+type core struct {
+	shards [][]*shard // [db][shard]
+	all    []*shard   // semua shard terurut menurut id
+
+	stats      expireStats
+	expireNext int // shard berikutnya untuk active expire
+	stop       chan struct{}
+
+	used      atomic.Int64 // perkiraan memori seluruh key, lihat memUsage
+	evicted   atomic.Int64
+	maxmemory int64
+	policy    EvictionPolicy
+	samples   int
+	evictMu   sync.Mutex
+	pool      []evictCandidate // eviction pool, lihat sampleCandidates
+
+	watching int64 // jumlah watcher aktif (atomic)
+
+	onExpire atomic.Pointer[func(db int, key string)]
+	onEvict  atomic.Pointer[func(db int, key string)]
+	loading  atomic.Bool // expiry dimatikan selama AOF dimuat
 }
 
 func NewMemoryStore(dbCount int, cleanupIntervalSec int) *MemoryStore {
-	data := make([]map[string]Item, dbCount)
-	for i := range data {
-		data[i] = make(map[string]Item)
+	store := &MemoryStore{core: &core{shards: make([][]*shard, dbCount), stop: make(chan struct{})}}
+	for db := range store.shards {
+		store.shards[db] = make([]*shard, shardCount)
+		for i := range store.shards[db] {
+			s := newShard(len(store.all), db)
+			store.shards[db][i] = s
+			store.all = append(store.all, s)
+		}
 	}
 
-	store := &MemoryStore{data: data}
-	go store.cleanupExpiredKeys(time.Duration(cleanupIntervalSec) * time.Second)
+	go store.activeExpire(time.Duration(cleanupIntervalSec) * time.Second)
 	return store
 }
 
 func (m *MemoryStore) Set(db int, key, value string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock := m.lock(db, key)
+	defer unlock()
 
-	item := m.data[db][key]
-	item.Value = value
-	item.ExpireAt = 0
-	m.data[db][key] = item
+	m.put(db, key, Item{Value: value})
+}
+
+// put menyimpan item; key baru juga didaftarkan ke index SCAN, dan index
+// key volatile mengikuti ada tidaknya TTL.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) put(db int, key string, item Item) {
+	h := scanHash(key)
+	s := m.shards[db][shardIndex(h)]
+
+	old, ok := s.data[key]
+	if !ok {
+		s.index.insert(float64(h), key)
+	}
+	if item.meta == nil {
+		item.meta = old.meta
+		if item.meta == nil {
+			item.meta = newKeyMeta(time.Now().UnixMilli())
+		}
+	}
+
+	item.size = item.memUsage(key, defaultSamples)
+	s.used += item.size - old.size
+	m.used.Add(item.size - old.size)
+
+	if item.ExpireAt > 0 {
+		s.volatile.add(key)
+	} else {
+		s.volatile.remove(key)
+	}
+	s.data[key] = item
+}
+
+// remove menghapus key dari data dan index SCAN.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) remove(db int, key string) {
+	h := scanHash(key)
+	s := m.shards[db][shardIndex(h)]
+
+	old, ok := s.data[key]
+	if !ok {
+		return
+	}
+	s.used -= old.size
+	m.used.Add(-old.size)
+	delete(s.data, key)
+	s.index.delete(float64(h), key)
+	s.volatile.remove(key)
+}
+
+// lookup membaca item, menghapusnya bila sudah expired, dan mencatat
+// akses untuk LRU/LFU.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) lookup(db int, key string) (Item, bool) {
+	now := time.Now().UnixMilli()
+
+	item, ok := m.peek(db, key, now)
+	if ok {
+		item.meta.hit(now)
+	}
+	return item, ok
+}
+
+// peek seperti lookup tetapi tanpa mencatat akses (OBJECT, MEMORY USAGE).
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) peek(db int, key string, now int64) (Item, bool) {
+	item, ok := m.shard(db, key).data[key]
+	if !ok {
+		return Item{}, false
+	}
+
+	if m.isExpired(item, now) {
+		m.expire(db, key)
+		return Item{}, false
+	}
+	return item, true
+}
+
+func (it Item) expired(now int64) bool {
+	return it.ExpireAt > 0 && now > it.ExpireAt
+}
+
+// SetLoading mematikan expiry selama AOF dimuat, seperti Redis: key yang
+// TTL-nya sudah lewat tetap ada sampai loading selesai sehingga command
+// berikutnya di AOF melihat dataset yang sama seperti saat ditulis.
+func (m *MemoryStore) SetLoading(loading bool) {
+	m.loading.Store(loading)
+}
+
+// isExpired seperti Item.expired tetapi selalu false selama loading. Dipakai
+// di setiap jalur yang menghapus key expired.
+func (m *MemoryStore) isExpired(it Item, now int64) bool {
+	return !m.loading.Load() && it.expired(now)
+}
+
+// SetExpireHook memasang callback yang dipanggil setiap kali key expired
+// dihapus (lazy maupun oleh active expire). Callback berjalan di bawah lock
+// shard sehingga tidak boleh memanggil MemoryStore lagi.
+func (m *MemoryStore) SetExpireHook(fn func(db int, key string)) {
+	m.onExpire.Store(&fn)
+}
+
+// SetEvictHook seperti SetExpireHook untuk key yang dihapus eviction.
+func (m *MemoryStore) SetEvictHook(fn func(db int, key string)) {
+	m.onEvict.Store(&fn)
 }
 
-func (m *MemoryStore) Get(db int, key string) (string, bool) {
-	m.mu.RLock()
-	item, ok := m.data[db][key]
-	m.mu.RUnlock()
+// expire menghapus key yang TTL-nya lewat.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) expire(db int, key string) {
+	m.remove(db, key)
+	m.touch(db, key)
+	m.stats.expired.Add(1)
+
+	if fn := m.onExpire.Load(); fn != nil {
+		(*fn)(db, key)
+	}
+}
+
+// Get hanya memakai read lock; write lock diambil bila key ternyata
+// expired dan perlu dihapus.
+func (m *MemoryStore) Get(db int, key string) (string, bool, error) {
+	s := m.shard(db, key)
+
+	unlock := m.rlockShard(s)
+	item, ok := s.data[key]
+	unlock()
 
 	if !ok {
-		return "", false
+		return "", false, nil
+	}
+
+	now := time.Now().UnixMilli()
+	if m.isExpired(item, now) {
+		// lookup mengecek ulang, key bisa saja sudah ditulis ulang
+		unlock := m.lockShard(s)
+		m.lookup(db, key)
+		unlock()
+		return "", false, nil
 	}
+	item.meta.hit(now)
 
-	if item.ExpireAt > 0 && time.Now().Unix() > item.ExpireAt {
-		m.mu.Lock()
-		delete(m.data[db], key)
-		m.mu.Unlock()
-		return "", false
+	if item.Type != TypeString {
+		return "", false, ErrWrongType
 	}
+	return item.Value, true, nil
+}
 
-	return item.Value, true
+// Type mengembalikan tipe value milik key, atau false bila key tidak ada.
+func (m *MemoryStore) Type(db int, key string) (ValueType, bool) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok := m.lookup(db, key)
+	return item.Type, ok
 }
 
-func (m *MemoryStore) Del(db int, key string) int {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// TypeOf seperti Type tetapi hanya memakai read lock: akses tidak dicatat
+// untuk LRU/LFU dan key expired dianggap tidak ada tanpa dihapus. Dipakai
+// filter TYPE pada SCAN dan notifikasi keyspace, yang tidak boleh
+// mengubah dataset.
+func (m *MemoryStore) TypeOf(db int, key string) (ValueType, bool) {
+	s := m.shard(db, key)
+	unlock := m.rlockShard(s)
+	defer unlock()
+
+	item, ok := s.data[key]
+	if !ok || m.isExpired(item, time.Now().UnixMilli()) {
+		return 0, false
+	}
+	return item.Type, true
+}
+
+// Del menghapus keys dan mengembalikan jumlah yang benar-benar ada.
+func (m *MemoryStore) Del(db int, keys ...string) int {
+	unlock := m.lock(db, keys...)
+	defer unlock()
 
-	if _, ok := m.data[db][key]; ok {
-		delete(m.data[db], key)
-		return 1
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := m.lookup(db, key); ok {
+			m.remove(db, key)
+			deleted++
+		}
 	}
-	return 0
+	return deleted
 }
 
-func (m *MemoryStore) ExpireAt(db int, key string, timestamp int64) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// ExpireCond adalah opsi NX/XX/GT/LT pada keluarga EXPIRE.
+type ExpireCond int
+
+const (
+	ExpireAlways ExpireCond = iota
+	ExpireNX                // hanya bila key belum punya TTL
+	ExpireXX                // hanya bila key sudah punya TTL
+	ExpireGT                // hanya bila TTL baru lebih lama
+	ExpireLT                // hanya bila TTL baru lebih singkat
+)
+
+// ExpireAt memasang TTL absolut (unix milliseconds) bila cond terpenuhi.
+// Key tanpa TTL dianggap punya TTL tak hingga untuk GT/LT, seperti Redis.
+func (m *MemoryStore) ExpireAt(db int, key string, at int64, cond ExpireCond) bool {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return false
+	}
 
-	item, ok := m.data[db][key]
+	switch cond {
+	case ExpireNX:
+		ok = item.ExpireAt == 0
+	case ExpireXX:
+		ok = item.ExpireAt != 0
+	case ExpireGT:
+		ok = item.ExpireAt != 0 && at > item.ExpireAt
+	case ExpireLT:
+		ok = item.ExpireAt == 0 || at < item.ExpireAt
+	}
 	if !ok {
 		return false
 	}
 
-	item.ExpireAt = timestamp
-	m.data[db][key] = item
+	item.ExpireAt = at
+	m.put(db, key, item)
 	return true
 }
 
+// TTL mengembalikan sisa umur key dalam milidetik, -1 bila tanpa TTL dan
+// -2 bila key tidak ada.
 func (m *MemoryStore) TTL(db int, key string) int64 {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock := m.lock(db, key)
+	defer unlock()
 
-	item, ok := m.data[db][key]
+	item, ok := m.lookup(db, key)
 	if !ok {
 		return -2
 	}
@@ -92,99 +379,67 @@ func (m *MemoryStore) TTL(db int, key string) int64 {
 	if item.ExpireAt == 0 {
 		return -1
 	}
+	return max(item.ExpireAt-time.Now().UnixMilli(), 0)
+}
+
+// ExpireTime mengembalikan waktu expire absolut (unix milliseconds), -1
+// bila tanpa TTL dan -2 bila key tidak ada.
+func (m *MemoryStore) ExpireTime(db int, key string) int64 {
+	unlock := m.lock(db, key)
+	defer unlock()
 
-	now := time.Now().Unix()
-	if now >= item.ExpireAt {
-		delete(m.data[db], key)
+	item, ok := m.lookup(db, key)
+	switch {
+	case !ok:
 		return -2
+	case item.ExpireAt == 0:
+		return -1
 	}
-
-	return item.ExpireAt - now
+	return item.ExpireAt
 }
 
 func (m *MemoryStore) Persist(db int, key string) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock := m.lock(db, key)
+	defer unlock()
 
-	item, ok := m.data[db][key]
+	item, ok := m.lookup(db, key)
 	if !ok || item.ExpireAt == 0 {
 		return false
 	}
 
 	item.ExpireAt = 0
-	m.data[db][key] = item
+	m.put(db, key, item)
 	return true
 }
 
-func (m *MemoryStore) Snapshot() map[int]map[string]Item {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	snap := make(map[int]map[string]Item)
-	now := time.Now().Unix()
-
-	for db, kv := range m.data {
-		dbSnap := make(map[string]Item)
-		for k, v := range kv {
-			if v.ExpireAt > 0 && now > v.ExpireAt {
-				continue
-			}
-			dbSnap[k] = v
-		}
-		if len(dbSnap) > 0 {
-			snap[db] = dbSnap
-		}
-	}
-
-	return snap
-}
-
 func (m *MemoryStore) Size() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
 	total := 0
-	for _, kv := range m.data {
-		total += len(kv)
+	for _, s := range m.all {
+		unlock := m.rlockShard(s)
+		total += len(s.data)
+		unlock()
 	}
 	return total
 }
 
-func (m *MemoryStore) cleanupExpiredKeys(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		now := time.Now().Unix()
-
-		m.mu.Lock()
-		for db, kv := range m.data {
-			for k, v := range kv {
-				if v.ExpireAt > 0 && now > v.ExpireAt {
-					delete(m.data[db], k)
-				}
-			}
-		}
-		m.mu.Unlock()
-	}
-}
-
 func (m *MemoryStore) DBCount() int {
-	return len(m.data)
+	return len(m.shards)
 }
 
 func (m *MemoryStore) Keys(db int) []string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	unlock := m.rlockShards(m.shards[db])
+	defer unlock()
 
-	keys := make([]string, 0, len(m.data[db]))
-	now := time.Now().Unix()
+	var keys []string
+	now := time.Now().UnixMilli()
 
-	for k, v := range m.data[db] {
-		if v.ExpireAt > 0 && now > v.ExpireAt {
-			continue
+	for _, s := range m.shards[db] {
+		for k, v := range s.data {
+			if v.expired(now) {
+				continue
+			}
+			keys = append(keys, k)
 		}
-		keys = append(keys, k)
 	}
 	return keys
 }
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Benchmark ini menunjukkan skala throughput terhadap GOMAXPROCS, mis.:
+//
+//	go test ./internal/storage -run '^$' -bench . -cpu 1,2,4,8
+//
+// Dengan lock per shard, ns/op operasi paralel pada key berbeda seharusnya
+// turun seiring bertambahnya CPU.
+
+const benchKeys = 1 << 16
+
+func benchStore(b *testing.B) *MemoryStore {
+	b.Helper()
+
+	m := NewMemoryStore(16, 3600)
+	b.Cleanup(m.Close)
+	for i := range benchKeys {
+		m.Set(0, benchKey(i), "value")
+	}
+	return m
+}
+
+func benchKey(i int) string {
+	return "key:" + strconv.Itoa(i%benchKeys)
+}
+
+// parallel menjalankan fn dengan indeks yang berbeda per goroutine agar
+// tiap worker menyentuh key yang berbeda.
+func parallel(b *testing.B, fn func(i int)) {
+	var seed atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seed.Add(1)) * 7919
+		for pb.Next() {
+			fn(i)
+			i++
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		m.Get(0, benchKey(i))
+	})
+}
+
+func BenchmarkSet(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		m.Set(0, benchKey(i), "value")
+	})
+}
+
+func BenchmarkSetWithTTL(b *testing.B) {
+	m := benchStore(b)
+	at := time.Now().Add(time.Hour).UnixMilli()
+	parallel(b, func(i int) {
+		m.SetWithOptions(0, benchKey(i), "value", SetOptions{ExpireAt: at})
+	})
+}
+
+func BenchmarkIncr(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		m.IncrBy(0, "counter:"+strconv.Itoa(i%benchKeys), 1)
+	})
+}
+
+func BenchmarkHSet(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		m.HSet(0, "hash:"+strconv.Itoa(i%1024), []string{strconv.Itoa(i), "v"}, false)
+	})
+}
+
+func BenchmarkPushPop(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		key := "list:" + strconv.Itoa(i%1024)
+		m.Push(0, key, []string{"v"}, true, false)
+		m.Pop(0, key, false, 1)
+	})
+}
+
+// BenchmarkMixed meniru beban 80% baca / 20% tulis.
+func BenchmarkMixed(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		if i%5 == 0 {
+			m.Set(0, benchKey(i), "value")
+		} else {
+			m.Get(0, benchKey(i))
+		}
+	})
+}
+
+// BenchmarkMSet mengukur operasi multi-key yang mengunci beberapa shard
+// sekaligus.
+func BenchmarkMSet(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(i int) {
+		m.MSet(0, []string{
+			benchKey(i), "a",
+			benchKey(i + 1), "b",
+			benchKey(i + 2), "c",
+			benchKey(i + 3), "d",
+		}, false)
+	})
+}
+
+// BenchmarkHotKey adalah kasus terburuk: semua worker menulis key yang
+// sama sehingga tidak ada paralelisme yang bisa didapat.
+func BenchmarkHotKey(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(int) {
+		m.IncrBy(0, "hot", 1)
+	})
+}
+
+func BenchmarkScan(b *testing.B) {
+	m := benchStore(b)
+	parallel(b, func(int) {
+		m.Scan(0, 0, 100)
+	})
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// embstrLimit adalah batas panjang string ber-encoding "embstr" di Redis.
+const embstrLimit = 44
+
+// ObjectInfo adalah hasil OBJECT untuk satu key.
+type ObjectInfo struct {
+	Type     ValueType
+	Encoding string
+	Idle     int64 // milliseconds sejak akses terakhir
+	Freq     uint8 // counter LFU setelah decay
+}
+
+// Object membaca metadata key tanpa mencatatnya sebagai akses.
+func (m *MemoryStore) Object(db int, key string) (ObjectInfo, bool) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	now := time.Now().UnixMilli()
+	item, ok := m.peek(db, key, now)
+	if !ok {
+		return ObjectInfo{}, false
+	}
+
+	return ObjectInfo{
+		Type:     item.Type,
+		Encoding: item.encoding(),
+		Idle:     now - item.meta.access.Load(),
+		Freq:     item.meta.decayedFreq(now),
+	}, true
+}
+
+// MemoryUsage menghitung ulang perkiraan memori key dengan samples elemen
+// per koleksi (samples <= 0 = semua elemen).
+func (m *MemoryStore) MemoryUsage(db int, key string, samples int) (int64, bool) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok := m.peek(db, key, time.Now().UnixMilli())
+	if !ok {
+		return 0, false
+	}
+	return item.memUsage(key, samples), true
+}
+
+// encoding meniru nama encoding internal Redis agar tooling yang membaca
+// OBJECT ENCODING tetap berguna.
+func (it Item) encoding() string {
+	switch it.Type {
+	case TypeString:
+		if n, err := strconv.ParseInt(it.Value, 10, 64); err == nil && strconv.FormatInt(n, 10) == it.Value {
+			return "int"
+		}
+		if len(it.Value) <= embstrLimit {
+			return "embstr"
+		}
+		return "raw"
+	case TypeHash, TypeSet:
+		return "hashtable"
+	case TypeZSet:
+		return "skiplist"
+	case TypeList:
+		return "quicklist"
+	case TypeStream:
+		return "stream"
+	default:
+		return "unknown"
+	}
+}
+
+// DBMemory adalah ringkasan murah satu DB untuk MEMORY STATS.
+type DBMemory struct {
+	Keys    int
+	Expires int
+	Bytes   int64
+}
+
+// DBMemory menjumlahkan counter per shard tanpa menelusuri key.
+func (m *MemoryStore) DBMemory(db int) DBMemory {
+	var out DBMemory
+	for _, s := range m.shards[db] {
+		unlock := m.rlockShard(s)
+		out.Keys += len(s.data)
+		out.Expires += len(s.volatile.keys)
+		out.Bytes += s.used
+		unlock()
+	}
+	return out
+}
+
+// KeySize adalah satu key pada laporan key terbesar.
+type KeySize struct {
+	Key   string
+	Type  ValueType
+	Bytes int64
+}
+
+// TypeStats adalah jumlah key dan memori per tipe.
+type TypeStats struct {
+	Keys  int
+	Bytes int64
+}
+
+// DBKeyStats merangkum pemakaian memori satu DB.
+type DBKeyStats struct {
+	Keys    int
+	Expires int
+	Bytes   int64
+	Types   map[ValueType]TypeStats
+	Largest []KeySize // terurut turun, paling banyak topN
+}
+
+// KeyStats mengumpulkan distribusi tipe dan topN key terbesar di db.
+// Ukuran yang dipakai adalah perkiraan saat key terakhir ditulis sehingga
+// tidak perlu menelusuri isi koleksi. Shard dikunci bergantian agar
+// command lain tidak tertahan selama laporan dibuat.
+func (m *MemoryStore) KeyStats(db, topN int) DBKeyStats {
+	stats := DBKeyStats{Types: make(map[ValueType]TypeStats)}
+	now := time.Now().UnixMilli()
+
+	for _, s := range m.shards[db] {
+		unlock := m.rlockShard(s)
+		for key, item := range s.data {
+			if item.expired(now) {
+				continue
+			}
+
+			stats.Keys++
+			stats.Bytes += item.size
+			if item.ExpireAt > 0 {
+				stats.Expires++
+			}
+
+			t := stats.Types[item.Type]
+			t.Keys++
+			t.Bytes += item.size
+			stats.Types[item.Type] = t
+
+			stats.Largest = insertLargest(stats.Largest, KeySize{Key: key, Type: item.Type, Bytes: item.size}, topN)
+		}
+		unlock()
+	}
+	return stats
+}
+
+// insertLargest menyisipkan k ke top terurut turun dan menjaga panjangnya
+// paling banyak n.
+func insertLargest(top []KeySize, k KeySize, n int) []KeySize {
+	if n <= 0 {
+		return top
+	}
+	if len(top) == n && k.Bytes <= top[n-1].Bytes {
+		return top
+	}
+
+	i, _ := slices.BinarySearchFunc(top, k.Bytes, func(e KeySize, bytes int64) int {
+		switch {
+		case e.Bytes > bytes:
+			return -1
+		case e.Bytes < bytes:
+			return 1
+		}
+		return 0
+	})
+	top = slices.Insert(top, i, k)
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
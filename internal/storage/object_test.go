@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyStats(t *testing.T) {
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+
+	for i := 1; i <= 10; i++ {
+		m.Set(0, "s"+strconv.Itoa(i), strings.Repeat("x", i*100))
+	}
+	m.HSet(0, "h", []string{"f", strings.Repeat("x", 5000)}, false)
+	m.SAdd(0, "set", []string{"a", "b"})
+	m.Set(0, "gone", "v")
+	m.ExpireAt(0, "gone", 1, ExpireAlways)
+	m.Set(0, "ttl", "v")
+	m.ExpireAt(0, "ttl", time.Now().Add(time.Hour).UnixMilli(), ExpireAlways)
+
+	stats := m.KeyStats(0, 3)
+	if stats.Keys != 13 || stats.Expires != 1 {
+		t.Fatalf("Keys = %d, Expires = %d; want 13 and 1", stats.Keys, stats.Expires)
+	}
+	if got := stats.Types[TypeString].Keys; got != 11 {
+		t.Fatalf("string keys = %d, want 11", got)
+	}
+	if got := stats.Types[TypeSet].Keys; got != 1 {
+		t.Fatalf("set keys = %d, want 1", got)
+	}
+
+	var sum int64
+	for _, ts := range stats.Types {
+		sum += ts.Bytes
+	}
+	if sum != stats.Bytes {
+		t.Fatalf("type bytes sum to %d, total is %d", sum, stats.Bytes)
+	}
+
+	want := []string{"h", "s10", "s9"}
+	if len(stats.Largest) != len(want) {
+		t.Fatalf("Largest = %+v", stats.Largest)
+	}
+	for i, key := range want {
+		if stats.Largest[i].Key != key {
+			t.Fatalf("Largest = %+v, want keys %v", stats.Largest, want)
+		}
+	}
+
+	if got := m.KeyStats(0, 0).Largest; len(got) != 0 {
+		t.Fatalf("KeyStats top 0 returned %+v", got)
+	}
+}
+
+// TestAccessTracking: read mencatat akses, Object hanya mengintip.
+func TestAccessTracking(t *testing.T) {
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+
+	m.Set(0, "k", "v")
+	meta := m.shard(0, "k").data["k"].meta
+	meta.access.Store(time.Now().UnixMilli() - 10_000)
+
+	info, _ := m.Object(0, "k")
+	if info.Idle < 10_000 || info.Freq != lfuInitVal {
+		t.Fatalf("Object after write = %+v", info)
+	}
+	if info, _ := m.Object(0, "k"); info.Idle < 10_000 {
+		t.Fatalf("Object counted as an access: idle %d", info.Idle)
+	}
+
+	for i := 0; i < 1000; i++ {
+		m.Get(0, "k")
+	}
+	info, _ = m.Object(0, "k")
+	if info.Idle > 1000 || info.Freq <= lfuInitVal {
+		t.Fatalf("Object after reads = %+v", info)
+	}
+}
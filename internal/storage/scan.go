@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"math"
+	"time"
+)
+
+// scanHash menentukan urutan iterasi *SCAN. Urutan ini hanya bergantung
+// pada nama elemen, sehingga cursor tetap valid walau koleksi berubah:
+// elemen yang ada sepanjang iterasi pasti dikembalikan minimal sekali.
+func scanHash(s string) uint32 {
+	// FNV-1a 32-bit tanpa alokasi; dipanggil di setiap akses key
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// scanBatch mengembalikan elemen index dengan hash >= cursor, terurut
+// menurut hash, minimal count buah. Elemen dengan hash sama tidak dipisah
+// antar batch. Cursor berikutnya 0 bila iterasi selesai.
+func scanBatch(index *skiplist, cursor uint64, count int) (uint64, []string) {
+	if cursor > math.MaxUint32 {
+		return 0, nil
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	var out []string
+	last := -1.0
+
+	x := index.firstInScore(ScoreRange{Min: float64(cursor), Max: math.Inf(1)})
+	for ; x != nil; x = x.level[0].forward {
+		if len(out) >= count && x.score != last {
+			return uint64(x.score), out
+		}
+		last = x.score
+		out = append(out, x.member)
+	}
+	return 0, out
+}
+
+// Index SCAN koleksi (hash, set, zset) berisi member terurut menurut
+// scanHash, seperti index keyspace di shard. Index baru dibangun saat
+// koleksi pertama kali di-scan, lalu dirawat setiap write sehingga scan
+// berikutnya O(log n + count). Koleksi yang tidak pernah di-scan tidak
+// membayar memori index.
+
+func newScanIndex[V any](members map[string]V) *skiplist {
+	index := newSkiplist()
+	for mem := range members {
+		index.insert(float64(scanHash(mem)), mem)
+	}
+	return index
+}
+
+func indexAdd(index *skiplist, member string) {
+	if index != nil {
+		index.insert(float64(scanHash(member)), member)
+	}
+}
+
+func indexRemove(index *skiplist, member string) {
+	if index != nil {
+		index.delete(float64(scanHash(member)), member)
+	}
+}
+
+// scanIndex mengembalikan index SCAN item hash/set, membangunnya bila
+// belum ada.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) scanIndex(db int, key string, item Item) *skiplist {
+	if item.scan == nil {
+		if item.Type == TypeHash {
+			item.scan = newScanIndex(item.Hash)
+		} else {
+			item.scan = newScanIndex(item.Set)
+		}
+		m.shard(db, key).data[key] = item
+	}
+	return item.scan
+}
+
+// hashSet menulis field dan mengembalikan true bila field baru.
+func (it Item) hashSet(field, value string) bool {
+	_, exists := it.Hash[field]
+	if !exists {
+		indexAdd(it.scan, field)
+	}
+	it.Hash[field] = value
+	return !exists
+}
+
+func (it Item) hashDel(field string) bool {
+	if _, ok := it.Hash[field]; !ok {
+		return false
+	}
+	delete(it.Hash, field)
+	indexRemove(it.scan, field)
+	return true
+}
+
+func (it Item) setAdd(member string) bool {
+	if _, ok := it.Set[member]; ok {
+		return false
+	}
+	it.Set[member] = struct{}{}
+	indexAdd(it.scan, member)
+	return true
+}
+
+func (it Item) setRem(member string) bool {
+	if _, ok := it.Set[member]; !ok {
+		return false
+	}
+	delete(it.Set, member)
+	indexRemove(it.scan, member)
+	return true
+}
+
+// Scan mengembalikan satu batch key milik DB mulai dari cursor dengan
+// urutan dan jaminan yang sama seperti scanBatch, memakai index tiap shard
+// sehingga biayanya O(log n + count), bukan menyalin seluruh keyspace.
+// Shard dibaca satu per satu mulai dari shard yang memuat cursor.
+// Key expired dilewati; filter MATCH/TYPE diterapkan pemanggil.
+func (m *MemoryStore) Scan(db int, cursor uint64, count int) (uint64, []string) {
+	if cursor > math.MaxUint32 {
+		return 0, nil
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	now := time.Now().UnixMilli()
+	var out []string
+	n, last := 0, -1.0
+
+	for i := shardIndex(uint32(cursor)); i < shardCount; i++ {
+		s := m.shards[db][i]
+		unlock := m.rlockShard(s)
+
+		x := s.index.firstInScore(ScoreRange{Min: float64(cursor), Max: math.Inf(1)})
+		for ; x != nil; x = x.level[0].forward {
+			if n >= count && x.score != last {
+				unlock()
+				return uint64(x.score), out
+			}
+			n++
+			last = x.score
+
+			if s.data[x.member].expired(now) {
+				continue
+			}
+			out = append(out, x.member)
+		}
+
+		unlock()
+	}
+	return 0, out
+}
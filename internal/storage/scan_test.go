@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+)
+
+// collectionScanner membungkus HSCAN/SSCAN/ZSCAN untuk satu key agar
+// jaminan cursor bisa diuji dengan cara yang sama di ketiganya.
+type collectionScanner struct {
+	add    func(m *MemoryStore, members ...string)
+	remove func(m *MemoryStore, members ...string)
+	scan   func(m *MemoryStore, cursor uint64) (uint64, []string)
+}
+
+var collectionScanners = map[string]collectionScanner{
+	"hash": {
+		add: func(m *MemoryStore, members ...string) {
+			var pairs []string
+			for _, mem := range members {
+				pairs = append(pairs, mem, "v")
+			}
+			m.HSet(0, "c", pairs, false)
+		},
+		remove: func(m *MemoryStore, members ...string) { m.HDel(0, "c", members) },
+		scan: func(m *MemoryStore, cursor uint64) (uint64, []string) {
+			next, pairs, _ := m.HScan(0, "c", cursor, 10)
+			var fields []string
+			for i := 0; i < len(pairs); i += 2 {
+				fields = append(fields, pairs[i])
+			}
+			return next, fields
+		},
+	},
+	"set": {
+		add:    func(m *MemoryStore, members ...string) { m.SAdd(0, "c", members) },
+		remove: func(m *MemoryStore, members ...string) { m.SRem(0, "c", members) },
+		scan: func(m *MemoryStore, cursor uint64) (uint64, []string) {
+			next, members, _ := m.SScan(0, "c", cursor, 10)
+			return next, members
+		},
+	},
+	"zset": {
+		add: func(m *MemoryStore, members ...string) {
+			var zs []ZMember
+			for i, mem := range members {
+				zs = append(zs, ZMember{mem, float64(i)})
+			}
+			m.ZAdd(0, "c", ZAddOptions{}, zs)
+		},
+		remove: func(m *MemoryStore, members ...string) { m.ZRem(0, "c", members) },
+		scan: func(m *MemoryStore, cursor uint64) (uint64, []string) {
+			next, zs, _ := m.ZScan(0, "c", cursor, 10)
+			var members []string
+			for _, z := range zs {
+				members = append(members, z.Member)
+			}
+			return next, members
+		},
+	},
+}
+
+// TestCollectionScanCursor mengubah koleksi di antara setiap halaman scan:
+// member yang ada sepanjang iterasi harus muncul, member yang tidak pernah
+// ada tidak boleh muncul, dan iterasi tetap selesai.
+func TestCollectionScanCursor(t *testing.T) {
+	for name, sc := range collectionScanners {
+		t.Run(name, func(t *testing.T) {
+			m := NewMemoryStore(1, 3600)
+			defer m.Close()
+
+			const n = 1000
+			stable := map[string]bool{}
+			seen := map[string]bool{} // semua member yang pernah ada
+			for i := 0; i < n; i++ {
+				mem := "m" + strconv.Itoa(i)
+				sc.add(m, mem)
+				seen[mem] = true
+				if i%2 == 0 {
+					stable[mem] = true
+				}
+			}
+
+			returned := map[string]bool{}
+			cursor, pages := uint64(0), 0
+			for {
+				next, batch := sc.scan(m, cursor)
+				for _, mem := range batch {
+					if !seen[mem] {
+						t.Fatalf("scan returned %q which never existed", mem)
+					}
+					returned[mem] = true
+				}
+
+				// member ganjil dihapus dan member baru ditambah di antara halaman
+				for i := 0; i < 5; i++ {
+					added := "n" + strconv.Itoa(pages*5+i)
+					sc.add(m, added)
+					seen[added] = true
+					sc.remove(m, "m"+strconv.Itoa((pages*5+i)*2%n+1))
+				}
+
+				pages++
+				if next == 0 {
+					break
+				}
+				if pages > 10*n {
+					t.Fatal("scan did not terminate")
+				}
+				cursor = next
+			}
+
+			for mem := range stable {
+				if !returned[mem] {
+					t.Errorf("stable member %q was not returned", mem)
+				}
+			}
+		})
+	}
+}
+
+// TestCollectionScanIndex memastikan index yang dibangun scan pertama
+// mengikuti write berikutnya.
+func TestCollectionScanIndex(t *testing.T) {
+	for name, sc := range collectionScanners {
+		t.Run(name, func(t *testing.T) {
+			m := NewMemoryStore(1, 3600)
+			defer m.Close()
+
+			sc.add(m, "a", "b", "c")
+			sc.scan(m, 0)
+			sc.add(m, "d", "a")
+			sc.remove(m, "b", "x")
+
+			got := map[string]bool{}
+			for cursor := uint64(0); ; {
+				next, batch := sc.scan(m, cursor)
+				for _, mem := range batch {
+					if got[mem] {
+						t.Errorf("%q returned twice", mem)
+					}
+					got[mem] = true
+				}
+				if next == 0 {
+					break
+				}
+				cursor = next
+			}
+
+			want := map[string]bool{"a": true, "c": true, "d": true}
+			if len(got) != len(want) {
+				t.Fatalf("scan returned %v, want %v", got, want)
+			}
+			for mem := range want {
+				if !got[mem] {
+					t.Fatalf("scan returned %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestScanCursor adalah jaminan yang sama untuk SCAN keyspace: key yang
+// ada sepanjang iterasi pasti dikembalikan walau shard berubah di antara
+// halaman.
+func TestScanCursor(t *testing.T) {
+	m := NewMemoryStore(1, 3600)
+	defer m.Close()
+
+	const n = 2000
+	stable := map[string]bool{}
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		key := "k" + strconv.Itoa(i)
+		m.Set(0, key, "v")
+		seen[key] = true
+		if i%2 == 0 {
+			stable[key] = true
+		}
+	}
+
+	returned := map[string]bool{}
+	cursor, pages := uint64(0), 0
+	for {
+		next, keys := m.Scan(0, cursor, 10)
+		for _, key := range keys {
+			if !seen[key] {
+				t.Fatalf("scan returned %q which never existed", key)
+			}
+			returned[key] = true
+		}
+
+		for i := 0; i < 5; i++ {
+			added := "n" + strconv.Itoa(pages*5+i)
+			m.Set(0, added, "v")
+			seen[added] = true
+			m.Del(0, "k"+strconv.Itoa((pages*5+i)*2%n+1))
+		}
+
+		pages++
+		if next == 0 {
+			break
+		}
+		if pages > 10*n {
+			t.Fatal("scan did not terminate")
+		}
+		cursor = next
+	}
+
+	for key := range stable {
+		if !returned[key] {
+			t.Errorf("stable key %q was not returned", key)
+		}
+	}
+}
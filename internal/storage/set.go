@@ -0,0 +1,310 @@
+package storage
+
+import "math/rand"
+
+type SetOp int
+
+const (
+	SetInter SetOp = iota
+	SetUnion
+	SetDiff
+)
+
+// writableSet mengembalikan item set milik key, atau set kosong baru.
+// Pemanggil wajib memegang write lock.
+func (m *MemoryStore) writableSet(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{Type: TypeSet, Set: map[string]struct{}{}}, nil
+	}
+	if item.Type != TypeSet {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) lookupSet(db int, key string) (map[string]struct{}, error) {
+	item, err := m.setItem(db, key)
+	return item.Set, err
+}
+
+// setItem seperti lookupSet tetapi mengembalikan item-nya; Set nil bila
+// key tidak ada.
+func (m *MemoryStore) setItem(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{}, nil
+	}
+	if item.Type != TypeSet {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) SAdd(db int, key string, members []string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.writableSet(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, mem := range members {
+		if item.setAdd(mem) {
+			added++
+		}
+	}
+
+	m.put(db, key, item)
+	return added, nil
+}
+
+// SRem menghapus member; key ikut dihapus bila set menjadi kosong.
+func (m *MemoryStore) SRem(db int, key string, members []string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.setItem(db, key)
+	if err != nil || item.Set == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, mem := range members {
+		if item.setRem(mem) {
+			removed++
+		}
+	}
+
+	if len(item.Set) == 0 {
+		m.remove(db, key)
+	}
+	return removed, nil
+}
+
+func (m *MemoryStore) SMembers(db int, key string) ([]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupSet(db, key)
+	if err != nil {
+		return nil, err
+	}
+	return setMembers(s), nil
+}
+
+// SIsMember memeriksa beberapa member sekaligus (SISMEMBER/SMISMEMBER).
+func (m *MemoryStore) SIsMember(db int, key string, members []string) ([]bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupSet(db, key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]bool, len(members))
+	for i, mem := range members {
+		_, out[i] = s[mem]
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) SCard(db int, key string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupSet(db, key)
+	return len(s), err
+}
+
+// SPop menghapus dan mengembalikan sampai count member acak.
+func (m *MemoryStore) SPop(db int, key string, count int) ([]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.setItem(db, key)
+	if err != nil || item.Set == nil {
+		return nil, err
+	}
+
+	members := setMembers(item.Set)
+	rand.Shuffle(len(members), func(i, j int) {
+		members[i], members[j] = members[j], members[i]
+	})
+	if count < len(members) {
+		members = members[:count]
+	}
+
+	for _, mem := range members {
+		item.setRem(mem)
+	}
+	if len(item.Set) == 0 {
+		m.remove(db, key)
+	}
+	return members, nil
+}
+
+// SRandMember mengikuti Redis: count >= 0 menghasilkan member unik,
+// count < 0 boleh berulang sebanyak -count.
+func (m *MemoryStore) SRandMember(db int, key string, count int) ([]string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupSet(db, key)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	members := setMembers(s)
+
+	if count < 0 {
+		out := make([]string, -count)
+		for i := range out {
+			out[i] = members[rand.Intn(len(members))]
+		}
+		return out, nil
+	}
+
+	rand.Shuffle(len(members), func(i, j int) {
+		members[i], members[j] = members[j], members[i]
+	})
+	if count < len(members) {
+		members = members[:count]
+	}
+	return members, nil
+}
+
+// SMove memindahkan member dari src ke dst secara atomik.
+func (m *MemoryStore) SMove(db int, src, dst, member string) (bool, error) {
+	unlock := m.lock(db, src, dst)
+	defer unlock()
+
+	from, err := m.setItem(db, src)
+	if err != nil {
+		return false, err
+	}
+
+	target, err := m.writableSet(db, dst)
+	if err != nil {
+		return false, err
+	}
+
+	if !from.setRem(member) {
+		return false, nil
+	}
+	if len(from.Set) == 0 {
+		m.remove(db, src)
+	}
+
+	target.setAdd(member)
+	m.put(db, dst, target)
+	return true, nil
+}
+
+// SetAlgebra menghitung SINTER/SUNION/SDIFF dari keys. Key yang tidak ada
+// dianggap set kosong.
+func (m *MemoryStore) SetAlgebra(db int, op SetOp, keys []string) ([]string, error) {
+	unlock := m.lock(db, keys...)
+	defer unlock()
+
+	res, err := m.setAlgebra(db, op, keys)
+	if err != nil {
+		return nil, err
+	}
+	return setMembers(res), nil
+}
+
+// SetAlgebraStore menyimpan hasil operasi ke dst (menimpa tipe apa pun)
+// dan mengembalikan jumlah member hasil.
+func (m *MemoryStore) SetAlgebraStore(db int, op SetOp, dst string, keys []string) (int, error) {
+	unlock := m.lock(db, append([]string{dst}, keys...)...)
+	defer unlock()
+
+	res, err := m.setAlgebra(db, op, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(res) == 0 {
+		m.remove(db, dst)
+		return 0, nil
+	}
+
+	m.put(db, dst, Item{Type: TypeSet, Set: res})
+	return len(res), nil
+}
+
+func (m *MemoryStore) setAlgebra(db int, op SetOp, keys []string) (map[string]struct{}, error) {
+	sets := make([]map[string]struct{}, len(keys))
+	for i, k := range keys {
+		s, err := m.lookupSet(db, k)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = s
+	}
+
+	res := map[string]struct{}{}
+
+	switch op {
+	case SetUnion:
+		for _, s := range sets {
+			for mem := range s {
+				res[mem] = struct{}{}
+			}
+		}
+
+	case SetInter:
+		for mem := range sets[0] {
+			inAll := true
+			for _, s := range sets[1:] {
+				if _, ok := s[mem]; !ok {
+					inAll = false
+					break
+				}
+			}
+			if inAll {
+				res[mem] = struct{}{}
+			}
+		}
+
+	case SetDiff:
+		for mem := range sets[0] {
+			found := false
+			for _, s := range sets[1:] {
+				if _, ok := s[mem]; ok {
+					found = true
+					break
+				}
+			}
+			if !found {
+				res[mem] = struct{}{}
+			}
+		}
+	}
+	return res, nil
+}
+
+func (m *MemoryStore) SScan(db int, key string, cursor uint64, count int) (uint64, []string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, err := m.setItem(db, key)
+	if err != nil || item.Set == nil {
+		return 0, nil, err
+	}
+
+	next, batch := scanBatch(m.scanIndex(db, key, item), cursor, count)
+	return next, batch, nil
+}
+
+func setMembers(s map[string]struct{}) []string {
+	out := make([]string, 0, len(s))
+	for mem := range s {
+		out = append(out, mem)
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Keyspace tiap DB dipecah menjadi shardCount shard yang masing-masing punya
+// lock sendiri, sehingga command pada key berbeda bisa berjalan paralel.
+const (
+	shardBits  = 6
+	shardCount = 1 << shardBits
+)
+
+// shard memegang sebagian key satu DB. Key masuk shard berdasarkan bit
+// teratas scanHash, jadi tiap shard mencakup rentang cursor SCAN yang
+// bersambung dan urutan SCAN antar shard tetap terjaga.
+type shard struct {
+	mu      sync.RWMutex
+	unlock  func() // mu.Unlock yang sudah di-bind, agar lock() tanpa alokasi
+	runlock func() // mu.RUnlock yang sudah di-bind
+	id      int    // urutan global (db*shardCount + i) untuk mengunci banyak shard
+	db      int
+
+	data     map[string]Item
+	index    *skiplist              // key terurut berdasarkan scanHash, untuk SCAN
+	volatile *volatileSet           // key yang punya TTL, disampling oleh active expire
+	versions map[string]*keyVersion // key yang di-WATCH
+	used     int64                  // total memUsage key di shard ini
+
+	// snapshot copy-on-write, lihat snapshot.go
+	cow    bool
+	frozen map[string]Item
+}
+
+func newShard(id, db int) *shard {
+	s := &shard{id: id, db: db, versions: make(map[string]*keyVersion)}
+	s.unlock = s.mu.Unlock
+	s.runlock = s.mu.RUnlock
+	s.reset()
+	return s
+}
+
+// reset mengosongkan isi shard tanpa menyentuh versi WATCH.
+func (s *shard) reset() {
+	s.used = 0
+	s.data = make(map[string]Item)
+	s.index = newSkiplist()
+	s.volatile = newVolatileSet()
+}
+
+func shardIndex(h uint32) int {
+	return int(h >> (32 - shardBits))
+}
+
+// shard mengembalikan shard yang memegang key di db.
+func (m *MemoryStore) shard(db int, key string) *shard {
+	return m.shards[db][shardIndex(scanHash(key))]
+}
+
+// lock mengunci (write) semua shard yang memegang keys di db dan
+// mengembalikan fungsi untuk melepasnya.
+func (m *MemoryStore) lock(db int, keys ...string) func() {
+	if len(keys) == 1 {
+		return m.lockShard(m.shard(db, keys[0]))
+	}
+
+	shards := make([]*shard, len(keys))
+	for i, key := range keys {
+		shards[i] = m.shard(db, key)
+	}
+	return m.lockShards(shards)
+}
+
+func noop() {}
+
+// holds melaporkan apakah shard sudah dikunci oleh view ini.
+func (m *MemoryStore) holds(s *shard) bool {
+	if m.heldAll {
+		return true
+	}
+	for _, h := range m.held {
+		if h == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lockShard mengunci (write) satu shard kecuali sudah dipegang view.
+// Setiap write lock juga menjalankan copy-on-write snapshot.
+func (m *MemoryStore) lockShard(s *shard) func() {
+	unlock := noop
+	if !m.holds(s) {
+		s.mu.Lock()
+		unlock = s.unlock
+	}
+	s.copyOnWrite()
+	return unlock
+}
+
+// rlockShard seperti lockShard tetapi dengan read lock.
+func (m *MemoryStore) rlockShard(s *shard) func() {
+	if m.holds(s) {
+		return noop
+	}
+	s.mu.RLock()
+	return s.runlock
+}
+
+// lockShards mengunci shards berurutan menurut id, sehingga operasi
+// multi-key (termasuk lintas DB) tidak bisa saling deadlock. Shard yang
+// disebut lebih dari sekali atau sudah dipegang view hanya dikunci sekali.
+func (m *MemoryStore) lockShards(shards []*shard) func() {
+	shards = sortShards(shards)
+
+	locked := shards[:0]
+	for _, s := range shards {
+		if !m.holds(s) {
+			s.mu.Lock()
+			locked = append(locked, s)
+		}
+		s.copyOnWrite()
+	}
+
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			locked[i].mu.Unlock()
+		}
+	}
+}
+
+// rlockShards seperti lockShards tetapi dengan read lock. shards harus
+// sudah terurut menurut id (mis. m.shards[db] atau m.all).
+func (m *MemoryStore) rlockShards(shards []*shard) func() {
+	if m.heldAll {
+		return noop
+	}
+	for _, s := range shards {
+		if !m.holds(s) {
+			s.mu.RLock()
+		}
+	}
+	return func() {
+		for i := len(shards) - 1; i >= 0; i-- {
+			if !m.holds(shards[i]) {
+				shards[i].mu.RUnlock()
+			}
+		}
+	}
+}
+
+// sortShards mengurutkan shards menurut id dan membuang duplikat.
+func sortShards(shards []*shard) []*shard {
+	sort.Slice(shards, func(i, j int) bool { return shards[i].id < shards[j].id })
+	return slices.Compact(shards)
+}
+
+// LockKeys mengunci (write) shard milik keys di db dan mengembalikan view
+// store yang memegang lock itu sampai Unlock. Method view tidak mengunci
+// ulang shard tersebut, sehingga pemanggil bisa menjalankan operasi
+// beserta efek sampingnya (mis. append AOF) sebagai satu langkah atomic
+// per key tanpa lock global. View hanya boleh menulis key di shard yang
+// dipegangnya; key lain akan dikunci di luar urutan id.
+func (m *MemoryStore) LockKeys(db int, keys ...string) *MemoryStore {
+	shards := make([]*shard, len(keys))
+	for i, key := range keys {
+		shards[i] = m.shard(db, key)
+	}
+
+	v := &MemoryStore{core: m.core, held: sortShards(shards)}
+	v.Lock()
+	return v
+}
+
+// LockAll seperti LockKeys tetapi memegang seluruh shard semua DB, untuk
+// command tanpa key atau lintas DB dan untuk EXEC.
+func (m *MemoryStore) LockAll() *MemoryStore {
+	v := &MemoryStore{core: m.core, held: m.all, heldAll: true}
+	v.Lock()
+	return v
+}
+
+// Lock mengambil lagi lock milik view setelah Unlock, mis. saat command
+// blocking selesai menunggu. Tidak berpengaruh pada store biasa.
+func (m *MemoryStore) Lock() {
+	for _, s := range m.held {
+		s.mu.Lock()
+	}
+}
+
+// Unlock melepas lock milik view.
+func (m *MemoryStore) Unlock() {
+	for i := len(m.held) - 1; i >= 0; i-- {
+		m.held[i].mu.Unlock()
+	}
+}
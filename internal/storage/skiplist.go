@@ -0,0 +1,316 @@
+package storage
+
+import "math/rand"
+
+const (
+	skiplistMaxLevel = 32
+	skiplistP        = 0.25
+)
+
+// skiplist mengurutkan member berdasarkan (score, member). Setiap level
+// menyimpan span (jumlah node yang dilompati) sehingga rank bisa dihitung
+// dalam O(log n), sama seperti zskiplist di Redis.
+type skiplist struct {
+	header *skipNode
+	tail   *skipNode
+	length int
+	level  int
+}
+
+type skipNode struct {
+	member   string
+	score    float64
+	backward *skipNode
+	level    []skipLevel
+}
+
+type skipLevel struct {
+	forward *skipNode
+	span    int
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{
+		header: &skipNode{level: make([]skipLevel, skiplistMaxLevel)},
+		level:  1,
+	}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && rand.Float64() < skiplistP {
+		level++
+	}
+	return level
+}
+
+// less: apakah (score, member) berada sebelum node n.
+func nodeBefore(n *skipNode, score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+func (sl *skiplist) insert(score float64, member string) *skipNode {
+	var update [skiplistMaxLevel]*skipNode
+	var rank [skiplistMaxLevel]int
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && nodeBefore(x.level[i].forward, score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.header
+			update[i].level[i].span = sl.length
+		}
+		sl.level = level
+	}
+
+	x = &skipNode{member: member, score: score, level: make([]skipLevel, level)}
+	for i := 0; i < level; i++ {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = (rank[0] - rank[i]) + 1
+	}
+
+	// level di atas node baru: span bertambah satu
+	for i := level; i < sl.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != sl.header {
+		x.backward = update[0]
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		sl.tail = x
+	}
+
+	sl.length++
+	return x
+}
+
+func (sl *skiplist) deleteNode(x *skipNode, update []*skipNode) {
+	for i := 0; i < sl.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		sl.tail = x.backward
+	}
+
+	for sl.level > 1 && sl.header.level[sl.level-1].forward == nil {
+		sl.level--
+	}
+	sl.length--
+}
+
+func (sl *skiplist) delete(score float64, member string) bool {
+	update := make([]*skipNode, skiplistMaxLevel)
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && nodeBefore(x.level[i].forward, score, member) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.level[0].forward
+	if x != nil && x.score == score && x.member == member {
+		sl.deleteNode(x, update)
+		return true
+	}
+	return false
+}
+
+// rank mengembalikan rank 1-based dari (score, member), 0 bila tidak ada.
+func (sl *skiplist) rank(score float64, member string) int {
+	rank := 0
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil &&
+			(nodeBefore(x.level[i].forward, score, member) ||
+				(x.level[i].forward.score == score && x.level[i].forward.member == member)) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if x != sl.header && x.member == member {
+			return rank
+		}
+	}
+	return 0
+}
+
+// byRank mengembalikan node pada rank 1-based.
+func (sl *skiplist) byRank(rank int) *skipNode {
+	traversed := 0
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// ScoreRange adalah interval score; Min/MaxEx berarti batas eksklusif.
+type ScoreRange struct {
+	Min, Max     float64
+	MinEx, MaxEx bool
+}
+
+func (r ScoreRange) aboveMin(v float64) bool {
+	if r.MinEx {
+		return v > r.Min
+	}
+	return v >= r.Min
+}
+
+func (r ScoreRange) belowMax(v float64) bool {
+	if r.MaxEx {
+		return v < r.Max
+	}
+	return v <= r.Max
+}
+
+func (r ScoreRange) empty() bool {
+	return r.Min > r.Max || (r.Min == r.Max && (r.MinEx || r.MaxEx))
+}
+
+// LexRange adalah interval member untuk BYLEX. MinInf/MaxInf mewakili
+// "-" dan "+".
+type LexRange struct {
+	Min, Max       string
+	MinEx, MaxEx   bool
+	MinInf, MaxInf bool
+}
+
+func (r LexRange) aboveMin(v string) bool {
+	switch {
+	case r.MinInf:
+		return true
+	case r.MinEx:
+		return v > r.Min
+	default:
+		return v >= r.Min
+	}
+}
+
+func (r LexRange) belowMax(v string) bool {
+	switch {
+	case r.MaxInf:
+		return true
+	case r.MaxEx:
+		return v < r.Max
+	default:
+		return v <= r.Max
+	}
+}
+
+func (r LexRange) empty() bool {
+	if r.MinInf || r.MaxInf {
+		return false
+	}
+	return r.Min > r.Max || (r.Min == r.Max && (r.MinEx || r.MaxEx))
+}
+
+// firstInScore mengembalikan node pertama dengan score di dalam range.
+func (sl *skiplist) firstInScore(r ScoreRange) *skipNode {
+	if r.empty() {
+		return nil
+	}
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && !r.aboveMin(x.level[i].forward.score) {
+			x = x.level[i].forward
+		}
+	}
+
+	x = x.level[0].forward
+	if x == nil || !r.belowMax(x.score) {
+		return nil
+	}
+	return x
+}
+
+// lastInScore mengembalikan node terakhir dengan score di dalam range.
+func (sl *skiplist) lastInScore(r ScoreRange) *skipNode {
+	if r.empty() {
+		return nil
+	}
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && r.belowMax(x.level[i].forward.score) {
+			x = x.level[i].forward
+		}
+	}
+
+	if x == sl.header || !r.aboveMin(x.score) {
+		return nil
+	}
+	return x
+}
+
+func (sl *skiplist) firstInLex(r LexRange) *skipNode {
+	if r.empty() {
+		return nil
+	}
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && !r.aboveMin(x.level[i].forward.member) {
+			x = x.level[i].forward
+		}
+	}
+
+	x = x.level[0].forward
+	if x == nil || !r.belowMax(x.member) {
+		return nil
+	}
+	return x
+}
+
+func (sl *skiplist) lastInLex(r LexRange) *skipNode {
+	if r.empty() {
+		return nil
+	}
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && r.belowMax(x.level[i].forward.member) {
+			x = x.level[i].forward
+		}
+	}
+
+	if x == sl.header || !r.aboveMin(x.member) {
+		return nil
+	}
+	return x
+}
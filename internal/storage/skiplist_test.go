@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// skiplistMembers mengembalikan member sl dari depan ke belakang.
+func skiplistMembers(sl *skiplist) []string {
+	var out []string
+	for x := sl.header.level[0].forward; x != nil; x = x.level[0].forward {
+		out = append(out, x.member)
+	}
+	return out
+}
+
+func TestSkiplistOrder(t *testing.T) {
+	sl := newSkiplist()
+	sl.insert(2, "b")
+	sl.insert(1, "z")
+	sl.insert(2, "a")
+	sl.insert(-1, "c")
+
+	// score sama diurutkan menurut member
+	want := []string{"c", "z", "a", "b"}
+	got := skiplistMembers(sl)
+	if len(got) != len(want) {
+		t.Fatalf("members = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("members = %v, want %v", got, want)
+		}
+	}
+	if sl.tail.member != "b" || sl.tail.backward.member != "a" {
+		t.Fatalf("tail = %q, backward = %q", sl.tail.member, sl.tail.backward.member)
+	}
+
+	if sl.delete(2, "x") || sl.delete(3, "a") {
+		t.Fatal("delete of a missing (score, member) reported success")
+	}
+	if !sl.delete(2, "b") {
+		t.Fatal("delete(2, b) = false")
+	}
+	if sl.length != 3 || sl.tail.member != "a" {
+		t.Fatalf("after delete: length %d, tail %q", sl.length, sl.tail.member)
+	}
+}
+
+// TestSkiplistRank membandingkan rank dan byRank dengan slice terurut
+// setelah rangkaian insert dan delete acak.
+func TestSkiplistRank(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sl := newSkiplist()
+	scores := map[string]float64{}
+
+	for i := 0; i < 2000; i++ {
+		mem := "m" + strconv.Itoa(r.Intn(500))
+		if score, ok := scores[mem]; ok && r.Intn(2) == 0 {
+			if !sl.delete(score, mem) {
+				t.Fatalf("delete(%v, %q) = false", score, mem)
+			}
+			delete(scores, mem)
+			continue
+		}
+		if _, ok := scores[mem]; ok {
+			continue
+		}
+		score := float64(r.Intn(50))
+		sl.insert(score, mem)
+		scores[mem] = score
+	}
+
+	want := make([]string, 0, len(scores))
+	for mem := range scores {
+		want = append(want, mem)
+	}
+	sort.Slice(want, func(i, j int) bool {
+		si, sj := scores[want[i]], scores[want[j]]
+		if si != sj {
+			return si < sj
+		}
+		return want[i] < want[j]
+	})
+
+	if sl.length != len(want) {
+		t.Fatalf("length = %d, want %d", sl.length, len(want))
+	}
+	for i, mem := range want {
+		if got := sl.rank(scores[mem], mem); got != i+1 {
+			t.Fatalf("rank(%q) = %d, want %d", mem, got, i+1)
+		}
+		if x := sl.byRank(i + 1); x == nil || x.member != mem {
+			t.Fatalf("byRank(%d) = %v, want %q", i+1, x, mem)
+		}
+	}
+	if got := sl.rank(1000, "missing"); got != 0 {
+		t.Fatalf("rank of a missing member = %d, want 0", got)
+	}
+	if x := sl.byRank(len(want) + 1); x != nil {
+		t.Fatalf("byRank past the end = %q, want nil", x.member)
+	}
+}
+
+func TestSkiplistScoreRange(t *testing.T) {
+	sl := newSkiplist()
+	for i := 1; i <= 5; i++ {
+		sl.insert(float64(i), "m"+strconv.Itoa(i))
+	}
+
+	cases := []struct {
+		r           ScoreRange
+		first, last string
+	}{
+		{ScoreRange{Min: 2, Max: 4}, "m2", "m4"},
+		{ScoreRange{Min: 2, Max: 4, MinEx: true, MaxEx: true}, "m3", "m3"},
+		{ScoreRange{Min: 0, Max: 10}, "m1", "m5"},
+		{ScoreRange{Min: 3, Max: 3, MinEx: true}, "", ""},
+		{ScoreRange{Min: 6, Max: 10}, "", ""},
+	}
+	for _, tc := range cases {
+		var first, last string
+		if x := sl.firstInScore(tc.r); x != nil {
+			first = x.member
+		}
+		if x := sl.lastInScore(tc.r); x != nil {
+			last = x.member
+		}
+		if first != tc.first || last != tc.last {
+			t.Errorf("%+v: first %q last %q, want %q %q", tc.r, first, last, tc.first, tc.last)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"slices"
+	"time"
+)
+
+// Snapshot adalah isi dataset pada saat BeginSnapshot, dibaca per shard
+// lewat Next. Data tidak disalin di awal: shard yang masih ditunggu
+// snapshot disalin oleh write pertama yang menguncinya (copy-on-write),
+// atau oleh Next bila belum pernah ditulis. Tidak ada titik di mana
+// seluruh dataset disalin sambil menahan write.
+type Snapshot struct {
+	m    *MemoryStore
+	now  int64
+	next int // indeks m.all berikutnya untuk Next
+}
+
+// BeginSnapshot memulai snapshot. Semua shard dikunci hanya selama start
+// dipanggil dan shard ditandai, tanpa menyalin data. start dipakai untuk
+// memulai rewrite buffer AOF pada titik yang sama, sehingga setiap write
+// tercatat tepat sekali: di snapshot atau di rewrite buffer. Snapshot
+// wajib di-Close.
+func (m *MemoryStore) BeginSnapshot(start func() error) (*Snapshot, error) {
+	unlock := m.lockShards(slices.Clone(m.all))
+	defer unlock()
+
+	if err := start(); err != nil {
+		return nil, err
+	}
+	for _, s := range m.all {
+		s.cow = true
+	}
+	return &Snapshot{m: m, now: time.Now().UnixMilli()}, nil
+}
+
+// copyOnWrite menyalin isi shard untuk snapshot yang masih menunggu shard
+// ini, sebelum isinya diubah.
+// Pemanggil wajib memegang write lock shard.
+func (s *shard) copyOnWrite() {
+	if s.cow {
+		s.frozen = cloneItems(s.data)
+		s.cow = false
+	}
+}
+
+func cloneItems(data map[string]Item) map[string]Item {
+	out := make(map[string]Item, len(data))
+	for k, v := range data {
+		out[k] = v.clone()
+	}
+	return out
+}
+
+// Next mengembalikan isi shard berikutnya yang tidak kosong beserta DB-nya,
+// atau ok false bila semua shard sudah dibaca. Lock shard hanya dipegang
+// selama isinya diambil. Key yang sudah expired saat BeginSnapshot
+// dilewati.
+func (sn *Snapshot) Next() (db int, items map[string]Item, ok bool) {
+	for sn.next < len(sn.m.all) {
+		s := sn.m.all[sn.next]
+		sn.next++
+
+		s.mu.Lock()
+		if s.cow {
+			items = cloneItems(s.data)
+			s.cow = false
+		} else {
+			items = s.frozen
+		}
+		s.frozen = nil
+		s.mu.Unlock()
+
+		for k, it := range items {
+			if it.expired(sn.now) {
+				delete(items, k)
+			}
+		}
+		if len(items) > 0 {
+			return s.db, items, true
+		}
+	}
+	return 0, nil, false
+}
+
+// Close melepas shard yang belum dibaca Next sehingga write berikutnya
+// tidak lagi menyalinnya.
+func (sn *Snapshot) Close() {
+	for ; sn.next < len(sn.m.all); sn.next++ {
+		s := sn.m.all[sn.next]
+		s.mu.Lock()
+		s.cow = false
+		s.frozen = nil
+		s.mu.Unlock()
+	}
+}
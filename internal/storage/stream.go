@@ -0,0 +1,1009 @@
+package storage
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrStreamID       = errors.New("ERR Invalid stream ID specified as stream command argument")
+	ErrStreamIDZero   = errors.New("ERR The ID specified in XADD must be greater than 0-0")
+	ErrStreamIDSmall  = errors.New("ERR The ID specified in XADD is equal or smaller than the target stream top item")
+	ErrStreamExhaust  = errors.New("ERR The stream has exhausted the last possible ID, unable to add more items")
+	ErrSetIDSmall     = errors.New("ERR The ID specified in XSETID is smaller than the target stream top item")
+	ErrEntriesAdded   = errors.New("ERR The entries_added specified in XSETID is smaller than the target stream length")
+	ErrBusyGroup      = errors.New("BUSYGROUP Consumer Group name already exists")
+	ErrNoGroup        = errors.New("NOGROUP No such consumer group")
+	ErrStreamNotFound = errors.New("ERR no such key")
+)
+
+// StreamID adalah ID entry stream "<ms>-<seq>".
+type StreamID struct {
+	Ms, Seq uint64
+}
+
+var MaxStreamID = StreamID{math.MaxUint64, math.MaxUint64}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Less(o StreamID) bool {
+	return id.Ms < o.Ms || (id.Ms == o.Ms && id.Seq < o.Seq)
+}
+
+func (id StreamID) IsZero() bool {
+	return id.Ms == 0 && id.Seq == 0
+}
+
+// Next mengembalikan ID berikutnya; ok false bila id sudah maksimum.
+func (id StreamID) Next() (StreamID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{id.Ms, id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{id.Ms + 1, 0}, true
+	}
+	return id, false
+}
+
+// Prev mengembalikan ID sebelumnya; ok false bila id adalah 0-0.
+func (id StreamID) Prev() (StreamID, bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{id.Ms, id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{id.Ms - 1, math.MaxUint64}, true
+	}
+	return id, false
+}
+
+// ParseStreamID membaca "<ms>-<seq>" atau "<ms>"; pada bentuk kedua seq
+// diisi missingSeq (0 untuk batas awal, maksimum untuk batas akhir).
+func ParseStreamID(s string, missingSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrStreamID
+	}
+	if !hasSeq {
+		return StreamID{ms, missingSeq}, nil
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrStreamID
+	}
+	return StreamID{ms, seq}, nil
+}
+
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string // field, value, field, value, ...
+}
+
+// PendingEntry adalah satu entry di PEL (pending entries list): sudah
+// dikirim ke consumer tetapi belum di-XACK.
+type PendingEntry struct {
+	ID          StreamID
+	Consumer    string
+	DeliveredAt int64 // unix ms
+	Deliveries  int64
+}
+
+type Consumer struct {
+	Name    string
+	SeenAt  int64 // unix ms, interaksi terakhir
+	Pending map[StreamID]*PendingEntry
+}
+
+type ConsumerGroup struct {
+	Name      string
+	LastID    StreamID // ID terakhir yang dikirim ke group
+	Pending   map[StreamID]*PendingEntry
+	Consumers map[string]*Consumer
+}
+
+// PendingIDs mengembalikan ID di PEL group secara terurut.
+func (g *ConsumerGroup) PendingIDs() []StreamID {
+	return sortedPending(g.Pending)
+}
+
+// ConsumerNames mengembalikan nama consumer secara terurut.
+func (g *ConsumerGroup) ConsumerNames() []string {
+	names := make([]string, 0, len(g.Consumers))
+	for name := range g.Consumers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *ConsumerGroup) consumer(name string, now int64) (*Consumer, bool) {
+	if c, ok := g.Consumers[name]; ok {
+		c.SeenAt = now
+		return c, false
+	}
+	c := &Consumer{Name: name, SeenAt: now, Pending: map[StreamID]*PendingEntry{}}
+	g.Consumers[name] = c
+	return c, true
+}
+
+// assign memindahkan (atau membuat) entry PEL ke consumer c.
+func (g *ConsumerGroup) assign(id StreamID, c *Consumer) *PendingEntry {
+	pe, ok := g.Pending[id]
+	if ok {
+		if old, ok := g.Consumers[pe.Consumer]; ok {
+			delete(old.Pending, id)
+		}
+	} else {
+		pe = &PendingEntry{ID: id}
+		g.Pending[id] = pe
+	}
+	pe.Consumer = c.Name
+	c.Pending[id] = pe
+	return pe
+}
+
+func (g *ConsumerGroup) ack(id StreamID) bool {
+	pe, ok := g.Pending[id]
+	if !ok {
+		return false
+	}
+	delete(g.Pending, id)
+	if c, ok := g.Consumers[pe.Consumer]; ok {
+		delete(c.Pending, id)
+	}
+	return true
+}
+
+func (g *ConsumerGroup) clone() *ConsumerGroup {
+	c := &ConsumerGroup{
+		Name:      g.Name,
+		LastID:    g.LastID,
+		Pending:   make(map[StreamID]*PendingEntry, len(g.Pending)),
+		Consumers: make(map[string]*Consumer, len(g.Consumers)),
+	}
+	for name, cons := range g.Consumers {
+		c.Consumers[name] = &Consumer{Name: name, SeenAt: cons.SeenAt, Pending: map[StreamID]*PendingEntry{}}
+	}
+	for id, pe := range g.Pending {
+		cp := *pe
+		c.Pending[id] = &cp
+		if cons, ok := c.Consumers[pe.Consumer]; ok {
+			cons.Pending[id] = &cp
+		}
+	}
+	return c
+}
+
+func sortedPending(pel map[StreamID]*PendingEntry) []StreamID {
+	ids := make([]StreamID, 0, len(pel))
+	for id := range pel {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Less(ids[j]) })
+	return ids
+}
+
+// Stream adalah log append-only: entries terurut berdasarkan ID sehingga
+// range dan lookup memakai binary search.
+type Stream struct {
+	entries      []StreamEntry
+	lastID       StreamID
+	entriesAdded int64
+	groups       map[string]*ConsumerGroup
+}
+
+func NewStream() *Stream {
+	return &Stream{groups: map[string]*ConsumerGroup{}}
+}
+
+func (s *Stream) Len() int {
+	return len(s.entries)
+}
+
+func (s *Stream) LastID() StreamID {
+	return s.lastID
+}
+
+func (s *Stream) EntriesAdded() int64 {
+	return s.entriesAdded
+}
+
+// Entries mengembalikan seluruh entry (slice milik stream, jangan diubah).
+func (s *Stream) Entries() []StreamEntry {
+	return s.entries
+}
+
+// Groups mengembalikan consumer group terurut berdasarkan nama.
+func (s *Stream) Groups() []*ConsumerGroup {
+	out := make([]*ConsumerGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// search mengembalikan index entry pertama dengan ID >= id.
+func (s *Stream) search(id StreamID) int {
+	return sort.Search(len(s.entries), func(i int) bool {
+		return !s.entries[i].ID.Less(id)
+	})
+}
+
+func (s *Stream) get(id StreamID) (StreamEntry, bool) {
+	i := s.search(id)
+	if i < len(s.entries) && s.entries[i].ID == id {
+		return s.entries[i], true
+	}
+	return StreamEntry{}, false
+}
+
+// rangeEntries mengembalikan entry dengan start <= ID <= end, maksimal
+// count (count <= 0 = semua). rev true membalik urutan dari end.
+func (s *Stream) rangeEntries(start, end StreamID, count int, rev bool) []StreamEntry {
+	out := []StreamEntry{}
+	if end.Less(start) {
+		return out
+	}
+
+	lo := s.search(start)
+	hi := s.search(end)
+	if hi < len(s.entries) && s.entries[hi].ID == end {
+		hi++
+	}
+
+	if rev {
+		for i := hi - 1; i >= lo && (count <= 0 || len(out) < count); i-- {
+			out = append(out, s.entries[i])
+		}
+		return out
+	}
+	for i := lo; i < hi && (count <= 0 || len(out) < count); i++ {
+		out = append(out, s.entries[i])
+	}
+	return out
+}
+
+// removeFront membuang n entry tertua.
+func (s *Stream) removeFront(n int) {
+	for i := 0; i < n; i++ {
+		s.entries[i] = StreamEntry{}
+	}
+	s.entries = s.entries[n:]
+}
+
+// StreamTrim adalah strategi MAXLEN/MINID. Limit > 0 membatasi jumlah
+// entry yang dibuang dalam satu panggilan.
+type StreamTrim struct {
+	MinID     bool
+	MaxLen    int64
+	Threshold StreamID
+	Limit     int64
+}
+
+func (s *Stream) trim(t StreamTrim) int64 {
+	n := 0
+	if t.MinID {
+		n = s.search(t.Threshold)
+	} else if int64(len(s.entries)) > t.MaxLen {
+		n = len(s.entries) - int(t.MaxLen)
+	}
+
+	if t.Limit > 0 && int64(n) > t.Limit {
+		n = int(t.Limit)
+	}
+	s.removeFront(n)
+	return int64(n)
+}
+
+func (s *Stream) clone() *Stream {
+	c := &Stream{
+		entries:      append([]StreamEntry(nil), s.entries...),
+		lastID:       s.lastID,
+		entriesAdded: s.entriesAdded,
+		groups:       make(map[string]*ConsumerGroup, len(s.groups)),
+	}
+	for name, g := range s.groups {
+		c.groups[name] = g.clone()
+	}
+	return c
+}
+
+// XAddOptions: AutoID untuk "*", AutoSeq untuk "<ms>-*", selain itu ID
+// dipakai apa adanya.
+type XAddOptions struct {
+	ID         StreamID
+	AutoID     bool
+	AutoSeq    bool
+	NoMkStream bool
+	Trim       *StreamTrim
+}
+
+func (m *MemoryStore) writableStream(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{Type: TypeStream, Stream: NewStream()}, nil
+	}
+	if item.Type != TypeStream {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) lookupStream(db int, key string) (*Stream, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return nil, nil
+	}
+	if item.Type != TypeStream {
+		return nil, ErrWrongType
+	}
+	return item.Stream, nil
+}
+
+func (m *MemoryStore) lookupGroup(db int, key, group string) (*Stream, *ConsumerGroup, error) {
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s == nil {
+		return nil, nil, ErrNoGroup
+	}
+	g, ok := s.groups[group]
+	if !ok {
+		return nil, nil, ErrNoGroup
+	}
+	return s, g, nil
+}
+
+// XAdd menambah entry dan mengembalikan ID-nya. ok false bila NOMKSTREAM
+// dan key belum ada.
+func (m *MemoryStore) XAdd(db int, key string, opts XAddOptions, fields []string, now int64) (StreamID, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	if opts.NoMkStream {
+		if s, err := m.lookupStream(db, key); err != nil || s == nil {
+			return StreamID{}, false, err
+		}
+	}
+
+	item, err := m.writableStream(db, key)
+	if err != nil {
+		return StreamID{}, false, err
+	}
+	s := item.Stream
+	last := s.lastID
+
+	id := opts.ID
+	switch {
+	case opts.AutoID:
+		if ms := uint64(now); ms > last.Ms {
+			id = StreamID{ms, 0}
+		} else {
+			next, ok := last.Next()
+			if !ok {
+				return StreamID{}, false, ErrStreamExhaust
+			}
+			id = next
+		}
+
+	case opts.AutoSeq:
+		switch {
+		case id.Ms > last.Ms:
+			id.Seq = 0
+		case id.Ms == last.Ms && last.Seq < math.MaxUint64:
+			id.Seq = last.Seq + 1
+		default:
+			return StreamID{}, false, ErrStreamIDSmall
+		}
+
+	default:
+		if id.IsZero() {
+			return StreamID{}, false, ErrStreamIDZero
+		}
+		if !last.Less(id) {
+			return StreamID{}, false, ErrStreamIDSmall
+		}
+	}
+
+	s.entries = append(s.entries, StreamEntry{ID: id, Fields: fields})
+	s.lastID = id
+	s.entriesAdded++
+
+	if opts.Trim != nil {
+		s.trim(*opts.Trim)
+	}
+
+	m.put(db, key, item)
+	return id, true, nil
+}
+
+func (m *MemoryStore) XTrim(db int, key string, t StreamTrim) (int64, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	return s.trim(t), nil
+}
+
+// XDel menghapus entry berdasarkan ID. Stream tetap ada walau kosong.
+func (m *MemoryStore) XDel(db int, key string, ids []StreamID) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil || s == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range ids {
+		i := s.search(id)
+		if i < len(s.entries) && s.entries[i].ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (m *MemoryStore) XLen(db int, key string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	return s.Len(), nil
+}
+
+func (m *MemoryStore) XRange(db int, key string, start, end StreamID, count int, rev bool) ([]StreamEntry, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil || s == nil {
+		return []StreamEntry{}, err
+	}
+	return s.rangeEntries(start, end, count, rev), nil
+}
+
+// XLastID mengembalikan ID terakhir stream (0-0 bila key tidak ada),
+// dipakai untuk "$" di XREAD.
+func (m *MemoryStore) XLastID(db int, key string) (StreamID, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil || s == nil {
+		return StreamID{}, err
+	}
+	return s.lastID, nil
+}
+
+// XSetID mengubah ID terakhir stream; entriesAdded < 0 berarti tidak diubah.
+func (m *MemoryStore) XSetID(db int, key string, id StreamID, entriesAdded int64) error {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return ErrStreamNotFound
+	}
+
+	if n := len(s.entries); n > 0 && id.Less(s.entries[n-1].ID) {
+		return ErrSetIDSmall
+	}
+	if entriesAdded >= 0 {
+		if entriesAdded < int64(len(s.entries)) {
+			return ErrEntriesAdded
+		}
+		s.entriesAdded = entriesAdded
+	}
+	s.lastID = id
+	return nil
+}
+
+// XGroupCreate membuat consumer group. useLast berarti "$". Mengembalikan
+// ID awal group yang sebenarnya.
+func (m *MemoryStore) XGroupCreate(db int, key, group string, id StreamID, useLast, mkStream bool) (StreamID, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return StreamID{}, err
+	}
+	if s == nil {
+		if !mkStream {
+			return StreamID{}, ErrStreamNotFound
+		}
+		s = NewStream()
+		m.put(db, key, Item{Type: TypeStream, Stream: s})
+	}
+
+	if _, ok := s.groups[group]; ok {
+		return StreamID{}, ErrBusyGroup
+	}
+	if useLast {
+		id = s.lastID
+	}
+
+	s.groups[group] = &ConsumerGroup{
+		Name:      group,
+		LastID:    id,
+		Pending:   map[StreamID]*PendingEntry{},
+		Consumers: map[string]*Consumer{},
+	}
+	return id, nil
+}
+
+func (m *MemoryStore) XGroupSetID(db int, key, group string, id StreamID, useLast bool) (StreamID, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return StreamID{}, err
+	}
+	if useLast {
+		id = s.lastID
+	}
+	g.LastID = id
+	return id, nil
+}
+
+func (m *MemoryStore) XGroupDestroy(db int, key, group string) (bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return false, err
+	}
+	if s == nil {
+		return false, ErrStreamNotFound
+	}
+	if _, ok := s.groups[group]; !ok {
+		return false, nil
+	}
+	delete(s.groups, group)
+	return true, nil
+}
+
+func (m *MemoryStore) XGroupCreateConsumer(db int, key, group, consumer string, now int64) (bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := g.Consumers[consumer]; ok {
+		return false, nil
+	}
+	g.consumer(consumer, now)
+	return true, nil
+}
+
+// XGroupDelConsumer menghapus consumer beserta entry pending miliknya dan
+// mengembalikan jumlah entry pending yang ikut dihapus.
+func (m *MemoryStore) XGroupDelConsumer(db int, key, group, consumer string) (int, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return 0, false, err
+	}
+
+	c, ok := g.Consumers[consumer]
+	if !ok {
+		return 0, false, nil
+	}
+
+	n := len(c.Pending)
+	for id := range c.Pending {
+		delete(g.Pending, id)
+	}
+	delete(g.Consumers, consumer)
+	return n, true, nil
+}
+
+// XReadGroupResult adalah hasil XREADGROUP untuk satu stream. Entry yang
+// sudah dihapus dari stream (hanya pada pembacaan history) memiliki
+// Fields nil.
+type XReadGroupResult struct {
+	Entries         []StreamEntry
+	Delivered       []*PendingEntry // entry baru yang masuk PEL
+	LastID          StreamID        // LastID group baru, 0-0 bila tidak berubah
+	ConsumerCreated bool
+}
+
+// XReadGroup membaca entry untuk consumer. newOnly true berarti ">":
+// entry setelah LastID group dikirim dan dicatat di PEL (kecuali noAck).
+// Selain itu, history PEL consumer setelah start dikembalikan.
+func (m *MemoryStore) XReadGroup(db int, key, group, consumer string, start StreamID, newOnly bool, count int, noAck bool, now int64) (XReadGroupResult, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var res XReadGroupResult
+
+	s, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return res, err
+	}
+
+	c, created := g.consumer(consumer, now)
+	res.ConsumerCreated = created
+
+	if !newOnly {
+		res.Entries = []StreamEntry{}
+		for _, id := range sortedPending(c.Pending) {
+			if !start.Less(id) {
+				continue
+			}
+			if count > 0 && len(res.Entries) >= count {
+				break
+			}
+			e, ok := s.get(id)
+			if !ok {
+				e = StreamEntry{ID: id}
+			}
+			res.Entries = append(res.Entries, e)
+		}
+		return res, nil
+	}
+
+	from, ok := g.LastID.Next()
+	if !ok {
+		return res, nil
+	}
+
+	res.Entries = s.rangeEntries(from, MaxStreamID, count, false)
+	for _, e := range res.Entries {
+		g.LastID = e.ID
+		if noAck {
+			continue
+		}
+
+		pe := g.assign(e.ID, c)
+		pe.DeliveredAt = now
+		pe.Deliveries = 1
+		res.Delivered = append(res.Delivered, pe)
+	}
+	if len(res.Entries) > 0 {
+		res.LastID = g.LastID
+	}
+	return res, nil
+}
+
+func (m *MemoryStore) XAck(db int, key, group string, ids []StreamID) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if errors.Is(err, ErrNoGroup) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range ids {
+		if g.ack(id) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// PendingSummary adalah bentuk ringkas XPENDING.
+type PendingSummary struct {
+	Count     int
+	Min, Max  StreamID
+	Consumers []ConsumerPending
+}
+
+type ConsumerPending struct {
+	Name  string
+	Count int
+}
+
+func (m *MemoryStore) XPendingSummary(db int, key, group string) (PendingSummary, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var sum PendingSummary
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return sum, err
+	}
+
+	ids := sortedPending(g.Pending)
+	sum.Count = len(ids)
+	if len(ids) == 0 {
+		return sum, nil
+	}
+	sum.Min, sum.Max = ids[0], ids[len(ids)-1]
+
+	for _, name := range g.ConsumerNames() {
+		if n := len(g.Consumers[name].Pending); n > 0 {
+			sum.Consumers = append(sum.Consumers, ConsumerPending{name, n})
+		}
+	}
+	return sum, nil
+}
+
+// XPendingRange adalah bentuk lengkap XPENDING. consumer kosong berarti
+// semua consumer; minIdle dalam ms.
+func (m *MemoryStore) XPendingRange(db int, key, group string, start, end StreamID, count int, consumer string, minIdle, now int64) ([]PendingEntry, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	pel := g.Pending
+	if consumer != "" {
+		c, ok := g.Consumers[consumer]
+		if !ok {
+			return []PendingEntry{}, nil
+		}
+		pel = c.Pending
+	}
+
+	out := []PendingEntry{}
+	for _, id := range sortedPending(pel) {
+		if len(out) >= count {
+			break
+		}
+		if id.Less(start) || end.Less(id) {
+			continue
+		}
+		pe := pel[id]
+		if now-pe.DeliveredAt < minIdle {
+			continue
+		}
+		out = append(out, *pe)
+	}
+	return out, nil
+}
+
+// XClaimOptions memetakan opsi XCLAIM. DeliveredAt/RetryCount < 0 berarti
+// tidak di-set.
+type XClaimOptions struct {
+	DeliveredAt int64
+	RetryCount  int64
+	Force       bool
+	JustID      bool
+	LastID      *StreamID
+}
+
+// XClaimResult: Claimed berisi entry yang berpindah ke consumer (Fields
+// nil bila JustID), Deleted berisi ID yang sudah tidak ada di stream dan
+// dibuang dari PEL.
+type XClaimResult struct {
+	Claimed []StreamEntry
+	Pending []PendingEntry // state PEL akhir untuk setiap entry di Claimed
+	Deleted []StreamID
+	Next    StreamID // cursor XAUTOCLAIM, 0-0 bila selesai
+	LastID  StreamID // LastID group setelah XCLAIM (untuk opsi LASTID)
+}
+
+func (m *MemoryStore) XClaim(db int, key, group, consumer string, minIdle int64, ids []StreamID, opts XClaimOptions, now int64) (XClaimResult, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var res XClaimResult
+
+	s, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return res, err
+	}
+
+	if opts.LastID != nil && g.LastID.Less(*opts.LastID) {
+		g.LastID = *opts.LastID
+	}
+
+	// consumer baru dibuat hanya bila ada entry yang diklaim
+	var c *Consumer
+	for _, id := range ids {
+		pe, pending := g.Pending[id]
+		e, exists := s.get(id)
+
+		if !pending {
+			if !opts.Force || !exists {
+				continue
+			}
+		} else {
+			if !exists {
+				g.ack(id)
+				res.Deleted = append(res.Deleted, id)
+				continue
+			}
+			if minIdle > 0 && now-pe.DeliveredAt < minIdle {
+				continue
+			}
+		}
+
+		if c == nil {
+			c, _ = g.consumer(consumer, now)
+		}
+		claimEntry(g, c, e, opts, now, &res)
+	}
+
+	res.LastID = g.LastID
+	return res, nil
+}
+
+// XAutoClaim memindai PEL mulai dari start dan mengklaim sampai count
+// entry yang idle >= minIdle.
+func (m *MemoryStore) XAutoClaim(db int, key, group, consumer string, minIdle int64, start StreamID, count int, justID bool, now int64) (XClaimResult, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var res XClaimResult
+
+	s, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return res, err
+	}
+
+	var c *Consumer
+	opts := XClaimOptions{DeliveredAt: -1, RetryCount: -1, JustID: justID}
+
+	// seperti Redis, jumlah entry yang diperiksa dibatasi count*10
+	attempts := count * 10
+	ids := sortedPending(g.Pending)
+	i := sort.Search(len(ids), func(i int) bool { return !ids[i].Less(start) })
+
+	for ; i < len(ids) && attempts > 0 && len(res.Claimed) < count; i++ {
+		attempts--
+		id := ids[i]
+
+		e, exists := s.get(id)
+		if !exists {
+			g.ack(id)
+			res.Deleted = append(res.Deleted, id)
+			continue
+		}
+		if now-g.Pending[id].DeliveredAt < minIdle {
+			continue
+		}
+
+		if c == nil {
+			c, _ = g.consumer(consumer, now)
+		}
+		claimEntry(g, c, e, opts, now, &res)
+	}
+
+	if i < len(ids) {
+		res.Next = ids[i]
+	}
+	return res, nil
+}
+
+func claimEntry(g *ConsumerGroup, c *Consumer, e StreamEntry, opts XClaimOptions, now int64, res *XClaimResult) {
+	pe := g.assign(e.ID, c)
+
+	pe.DeliveredAt = now
+	if opts.DeliveredAt >= 0 {
+		pe.DeliveredAt = opts.DeliveredAt
+	}
+	if opts.RetryCount >= 0 {
+		pe.Deliveries = opts.RetryCount
+	} else if !opts.JustID {
+		pe.Deliveries++
+	}
+
+	if opts.JustID {
+		e.Fields = nil
+	}
+	res.Claimed = append(res.Claimed, e)
+	res.Pending = append(res.Pending, *pe)
+}
+
+// StreamInfo adalah ringkasan XINFO STREAM.
+type StreamInfo struct {
+	Length       int
+	LastID       StreamID
+	EntriesAdded int64
+	Groups       int
+	First, Last  *StreamEntry
+}
+
+func (m *MemoryStore) XInfoStream(db int, key string) (StreamInfo, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var info StreamInfo
+
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return info, err
+	}
+	if s == nil {
+		return info, ErrStreamNotFound
+	}
+
+	info.Length = s.Len()
+	info.LastID = s.lastID
+	info.EntriesAdded = s.entriesAdded
+	info.Groups = len(s.groups)
+	if n := s.Len(); n > 0 {
+		first, last := s.entries[0], s.entries[n-1]
+		info.First, info.Last = &first, &last
+	}
+	return info, nil
+}
+
+// GroupInfo adalah satu baris XINFO GROUPS.
+type GroupInfo struct {
+	Name      string
+	Consumers int
+	Pending   int
+	LastID    StreamID
+}
+
+func (m *MemoryStore) XInfoGroups(db int, key string) ([]GroupInfo, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	s, err := m.lookupStream(db, key)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, ErrStreamNotFound
+	}
+
+	out := []GroupInfo{}
+	for _, g := range s.Groups() {
+		out = append(out, GroupInfo{g.Name, len(g.Consumers), len(g.Pending), g.LastID})
+	}
+	return out, nil
+}
+
+// ConsumerInfo adalah satu baris XINFO CONSUMERS.
+type ConsumerInfo struct {
+	Name    string
+	Pending int
+	Idle    int64 // ms
+}
+
+func (m *MemoryStore) XInfoConsumers(db int, key, group string, now int64) ([]ConsumerInfo, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	_, g, err := m.lookupGroup(db, key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []ConsumerInfo{}
+	for _, name := range g.ConsumerNames() {
+		c := g.Consumers[name]
+		out = append(out, ConsumerInfo{name, len(c.Pending), now - c.SeenAt})
+	}
+	return out, nil
+}
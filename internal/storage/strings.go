@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"math"
+	"strconv"
+)
+
+// MaxStringSize sama dengan proto-max-bulk-len default Redis (512MB).
+const MaxStringSize = 512 * 1024 * 1024
+
+type SetMode int
+
+const (
+	SetAlways SetMode = iota
+	SetIfNotExists
+	SetIfExists
+)
+
+type SetOptions struct {
+	Mode     SetMode
+	ExpireAt int64 // unix milliseconds, > 0 = pasang TTL baru
+	KeepTTL  bool
+	Get      bool // value lama dibutuhkan, wajib bertipe string
+}
+
+// SetWithOptions menjalankan SET lengkap secara atomik. Mengembalikan value
+// lama (bila ada) dan apakah value baru benar-benar ditulis.
+func (m *MemoryStore) SetWithOptions(
+	db int,
+	key, value string,
+	opts SetOptions,
+) (old string, existed bool, written bool, err error) {
+
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, existed := m.lookup(db, key)
+	if existed && opts.Get && item.Type != TypeString {
+		return "", false, false, ErrWrongType
+	}
+	old = item.Value
+
+	switch {
+	case opts.Mode == SetIfNotExists && existed:
+		return old, existed, false, nil
+	case opts.Mode == SetIfExists && !existed:
+		return old, existed, false, nil
+	}
+
+	// SET selalu menimpa key dengan tipe apa pun
+	next := Item{Value: value}
+	switch {
+	case opts.ExpireAt > 0:
+		next.ExpireAt = opts.ExpireAt
+	case opts.KeepTTL:
+		next.ExpireAt = item.ExpireAt
+	}
+
+	m.put(db, key, next)
+	return old, existed, true, nil
+}
+
+// MSet menulis semua pasangan key/value sekaligus. Bila nx true, tidak ada
+// yang ditulis jika salah satu key sudah ada.
+func (m *MemoryStore) MSet(db int, pairs []string, nx bool) bool {
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		keys = append(keys, pairs[i])
+	}
+
+	unlock := m.lock(db, keys...)
+	defer unlock()
+
+	if nx {
+		for i := 0; i < len(pairs); i += 2 {
+			if _, ok := m.lookup(db, pairs[i]); ok {
+				return false
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m.put(db, pairs[i], Item{Value: pairs[i+1]})
+	}
+	return true
+}
+
+func (m *MemoryStore) MGet(db int, keys []string) ([]string, []bool) {
+	unlock := m.lock(db, keys...)
+	defer unlock()
+
+	values := make([]string, len(keys))
+	found := make([]bool, len(keys))
+
+	for i, key := range keys {
+		item, ok := m.lookup(db, key)
+		values[i], found[i] = item.Value, ok && item.Type == TypeString
+	}
+	return values, found
+}
+
+func (m *MemoryStore) IncrBy(db int, key string, delta int64) (int64, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok, err := m.lookupString(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	var cur int64
+	if ok {
+		n, err := strconv.ParseInt(item.Value, 10, 64)
+		if err != nil {
+			return 0, ErrNotInteger
+		}
+		cur = n
+	}
+
+	if (delta > 0 && cur > math.MaxInt64-delta) ||
+		(delta < 0 && cur < math.MinInt64-delta) {
+		return 0, ErrOverflow
+	}
+
+	cur += delta
+	item.Value = strconv.FormatInt(cur, 10)
+	m.put(db, key, item)
+	return cur, nil
+}
+
+// IncrByFloat mengembalikan value baru dalam bentuk string yang disimpan.
+func (m *MemoryStore) IncrByFloat(db int, key string, delta float64) (string, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok, err := m.lookupString(db, key)
+	if err != nil {
+		return "", err
+	}
+
+	var cur float64
+	if ok {
+		f, err := strconv.ParseFloat(item.Value, 64)
+		if err != nil || math.IsNaN(f) {
+			return "", ErrNotFloat
+		}
+		cur = f
+	}
+
+	cur += delta
+	if math.IsNaN(cur) || math.IsInf(cur, 0) {
+		return "", ErrNaN
+	}
+
+	item.Value = FormatFloat(cur)
+	m.put(db, key, item)
+	return item.Value, nil
+}
+
+func (m *MemoryStore) Append(db int, key, value string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, _, err := m.lookupString(db, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(item.Value)+len(value) > MaxStringSize {
+		return 0, ErrTooLarge
+	}
+
+	item.Value += value
+	m.put(db, key, item)
+	return len(item.Value), nil
+}
+
+// SetRange menimpa value mulai offset, mengisi celah dengan byte nol.
+func (m *MemoryStore) SetRange(db int, key string, offset int, value string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok, err := m.lookupString(db, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if value == "" {
+		// tidak membuat key baru
+		return len(item.Value), nil
+	}
+	if offset+len(value) > MaxStringSize {
+		return 0, ErrTooLarge
+	}
+
+	buf := []byte(item.Value)
+	if need := offset + len(value); need > len(buf) {
+		buf = append(buf, make([]byte, need-len(buf))...)
+	}
+	copy(buf[offset:], value)
+
+	item.Value = string(buf)
+	if !ok {
+		item.ExpireAt = 0
+	}
+	m.put(db, key, item)
+	return len(buf), nil
+}
+
+func (m *MemoryStore) GetDel(db int, key string) (string, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok, err := m.lookupString(db, key)
+	if !ok || err != nil {
+		return "", false, err
+	}
+
+	m.remove(db, key)
+	return item.Value, true, nil
+}
+
+// GetEx membaca value sekaligus mengubah TTL: expireAt > 0 memasang TTL
+// baru, persist menghapus TTL, selain itu TTL tidak disentuh.
+func (m *MemoryStore) GetEx(db int, key string, expireAt int64, persist bool) (string, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	item, ok, err := m.lookupString(db, key)
+	if !ok || err != nil {
+		return "", false, err
+	}
+
+	switch {
+	case expireAt > 0:
+		item.ExpireAt = expireAt
+	case persist:
+		item.ExpireAt = 0
+	}
+
+	m.put(db, key, item)
+	return item.Value, true, nil
+}
+
+// lookupString seperti lookup, tetapi menolak key bertipe selain string.
+func (m *MemoryStore) lookupString(db int, key string) (Item, bool, error) {
+	item, ok := m.lookup(db, key)
+	if ok && item.Type != TypeString {
+		return Item{}, false, ErrWrongType
+	}
+	return item, ok, nil
+}
+
+// FormatFloat memformat float seperti Redis (tanpa exponent dan trailing zero).
+func FormatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
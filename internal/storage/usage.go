@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Perkiraan overhead memori (byte) struktur Go per key/elemen. Angkanya
+// kasar tetapi cukup untuk maxmemory dan MEMORY USAGE.
+const (
+	keyOverhead     = 160 // entry map, Item, node index SCAN, keyMeta
+	stringOverhead  = 16  // header string
+	mapOverhead     = 48  // entry map field/member
+	listOverhead    = 16  // slot ring buffer
+	zsetOverhead    = 96  // entry dict + node skiplist
+	streamOverhead  = 40  // StreamEntry + header slice
+	defaultSamples  = 5   // elemen yang disampling per koleksi
+	lfuInitVal      = 5   // counter LFU key baru, seperti Redis
+	lfuLogFactor    = 10
+	lfuDecayMinutes = 1
+)
+
+// keyMeta menyimpan statistik akses key untuk eviction LRU/LFU. Item
+// menyimpannya sebagai pointer sehingga bisa diperbarui lewat atomic
+// walau hanya memegang read lock.
+type keyMeta struct {
+	access atomic.Int64  // unix milliseconds akses terakhir
+	freq   atomic.Uint32 // counter LFU logaritmik (0..255)
+}
+
+func newKeyMeta(now int64) *keyMeta {
+	meta := &keyMeta{}
+	meta.access.Store(now)
+	meta.freq.Store(lfuInitVal)
+	return meta
+}
+
+// hit mencatat satu akses. Counter LFU diturunkan dulu sesuai lama key
+// menganggur lalu dinaikkan secara logaritmik (LFULogIncr di Redis).
+func (k *keyMeta) hit(now int64) {
+	k.freq.Store(uint32(lfuIncr(k.decayedFreq(now))))
+	k.access.Store(now)
+}
+
+// decayedFreq mengembalikan counter LFU setelah decay per menit idle.
+func (k *keyMeta) decayedFreq(now int64) uint8 {
+	freq := int64(k.freq.Load())
+	periods := (now - k.access.Load()) / (lfuDecayMinutes * 60_000)
+	if periods >= freq {
+		return 0
+	}
+	return uint8(freq - periods)
+}
+
+func lfuIncr(counter uint8) uint8 {
+	if counter == 255 {
+		return 255
+	}
+
+	base := float64(counter) - lfuInitVal
+	if base < 0 {
+		base = 0
+	}
+	if rand.Float64() < 1/(base*lfuLogFactor+1) {
+		counter++
+	}
+	return counter
+}
+
+// memUsage memperkirakan memori yang dipakai key beserta value-nya.
+// Koleksi besar tidak dihitung penuh: samples elemen diambil dan rata-
+// ratanya dikalikan jumlah elemen (samples <= 0 = hitung semuanya).
+func (it Item) memUsage(key string, samples int) int64 {
+	size := int64(keyOverhead + len(key))
+
+	switch it.Type {
+	case TypeString:
+		size += int64(len(it.Value))
+	case TypeHash:
+		size += sampleMap(it.Hash, samples, func(f, v string) int {
+			return 2*stringOverhead + mapOverhead + len(f) + len(v)
+		})
+	case TypeSet:
+		size += sampleMap(it.Set, samples, func(mem string, _ struct{}) int {
+			return stringOverhead + mapOverhead + len(mem)
+		})
+	case TypeZSet:
+		size += sampleMap(it.ZSet.dict, samples, func(mem string, _ float64) int {
+			return stringOverhead + zsetOverhead + len(mem)
+		})
+	case TypeList:
+		size += sampleSlice(it.List.Len(), samples, func(i int) int {
+			return listOverhead + len(it.List.At(i))
+		})
+	case TypeStream:
+		entries := it.Stream.entries
+		size += sampleSlice(len(entries), samples, func(i int) int {
+			n := streamOverhead
+			for _, f := range entries[i].Fields {
+				n += stringOverhead + len(f)
+			}
+			return n
+		})
+	}
+	return size
+}
+
+// sampleMap menjumlahkan size dari sebagian entry map lalu
+// mengekstrapolasikannya ke seluruh map. Urutan range map di Go acak
+// sehingga sampel tidak selalu sama.
+func sampleMap[V any](m map[string]V, samples int, size func(string, V) int) int64 {
+	if len(m) == 0 {
+		return 0
+	}
+
+	total, n := 0, 0
+	for k, v := range m {
+		if samples > 0 && n >= samples {
+			break
+		}
+		total += size(k, v)
+		n++
+	}
+	return int64(total) * int64(len(m)) / int64(n)
+}
+
+// sampleSlice seperti sampleMap untuk koleksi berindeks; sampel diambil
+// merata di sepanjang koleksi.
+func sampleSlice(length, samples int, size func(i int) int) int64 {
+	if length == 0 {
+		return 0
+	}
+
+	n := length
+	if samples > 0 && samples < length {
+		n = samples
+	}
+
+	total := 0
+	for i := range n {
+		total += size(i * length / n)
+	}
+	return int64(total) * int64(length) / int64(n)
+}
@@ -0,0 +1,89 @@
+package storage
+
+import "sync/atomic"
+
+// keyVersion adalah versi modifikasi sebuah key untuk WATCH. Versi hanya
+// disimpan selama key masih di-WATCH oleh minimal satu client.
+type keyVersion struct {
+	version  uint64
+	watchers int
+}
+
+// touch menaikkan versi key yang sedang di-WATCH.
+// Pemanggil wajib memegang write lock shard milik key.
+func (m *MemoryStore) touch(db int, key string) {
+	if v, ok := m.shard(db, key).versions[key]; ok {
+		v.version++
+	}
+}
+
+// touchAll menaikkan versi semua key yang di-WATCH di db (FLUSHDB,
+// SWAPDB). Pemanggil wajib memegang write lock semua shard db.
+func (m *MemoryStore) touchAll(db int) {
+	for _, s := range m.shards[db] {
+		for _, v := range s.versions {
+			v.version++
+		}
+	}
+}
+
+// Touch menandai key sebagai berubah. Dipanggil engine setelah command
+// write berhasil; tanpa WATCH aktif tidak ada lock yang diambil.
+func (m *MemoryStore) Touch(db int, keys ...string) {
+	if atomic.LoadInt64(&m.watching) == 0 {
+		return
+	}
+
+	unlock := m.lock(db, keys...)
+	defer unlock()
+
+	for _, key := range keys {
+		m.touch(db, key)
+	}
+}
+
+// Watch mendaftarkan satu watcher untuk key dan mengembalikan versinya.
+func (m *MemoryStore) Watch(db int, key string) uint64 {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	m.lookup(db, key) // key yang sudah expired dihapus lebih dulu
+
+	versions := m.shard(db, key).versions
+	v, ok := versions[key]
+	if !ok {
+		v = &keyVersion{}
+		versions[key] = v
+	}
+	v.watchers++
+	atomic.AddInt64(&m.watching, 1)
+	return v.version
+}
+
+func (m *MemoryStore) Unwatch(db int, key string) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	versions := m.shard(db, key).versions
+	v, ok := versions[key]
+	if !ok {
+		return
+	}
+	if v.watchers--; v.watchers == 0 {
+		delete(versions, key)
+	}
+	atomic.AddInt64(&m.watching, -1)
+}
+
+// KeyVersion mengembalikan versi key saat ini. Key yang expired sejak
+// WATCH ikut dihitung sebagai perubahan.
+func (m *MemoryStore) KeyVersion(db int, key string) uint64 {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	m.lookup(db, key)
+	if v, ok := m.shard(db, key).versions[key]; ok {
+		return v.version
+	}
+	return 0
+}
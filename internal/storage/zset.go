@@ -0,0 +1,555 @@
+package storage
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+var ErrScoreNaN = errors.New("ERR resulting score is not a number (NaN)")
+
+// ZMember adalah pasangan member/score hasil query sorted set.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ZSet adalah sorted set: map member->score untuk lookup O(1) dan skiplist
+// untuk urutan, rank dan range.
+type ZSet struct {
+	dict map[string]float64
+	zsl  *skiplist
+	scan *skiplist // index ZSCAN, nil sampai di-scan; lihat scan.go
+}
+
+func NewZSet() *ZSet {
+	return &ZSet{dict: map[string]float64{}, zsl: newSkiplist()}
+}
+
+func (z *ZSet) Len() int {
+	return len(z.dict)
+}
+
+func (z *ZSet) Score(member string) (float64, bool) {
+	s, ok := z.dict[member]
+	return s, ok
+}
+
+// Add menambah member atau memperbarui score-nya.
+func (z *ZSet) Add(member string, score float64) {
+	if cur, ok := z.dict[member]; ok {
+		if cur == score {
+			return
+		}
+		z.zsl.delete(cur, member)
+	} else {
+		indexAdd(z.scan, member)
+	}
+	z.dict[member] = score
+	z.zsl.insert(score, member)
+}
+
+func (z *ZSet) Remove(member string) bool {
+	score, ok := z.dict[member]
+	if !ok {
+		return false
+	}
+	delete(z.dict, member)
+	z.zsl.delete(score, member)
+	indexRemove(z.scan, member)
+	return true
+}
+
+// Rank mengembalikan rank 0-based member (urutan naik).
+func (z *ZSet) Rank(member string) (int, bool) {
+	score, ok := z.dict[member]
+	if !ok {
+		return 0, false
+	}
+	return z.zsl.rank(score, member) - 1, true
+}
+
+// Range mengembalikan member pada rank [start, stop] (sudah dinormalisasi).
+// Bila rev true, rank dihitung dari score tertinggi.
+func (z *ZSet) Range(start, stop int, rev bool) []ZMember {
+	out := make([]ZMember, 0, stop-start+1)
+
+	var x *skipNode
+	if rev {
+		x = z.zsl.byRank(z.zsl.length - start)
+	} else {
+		x = z.zsl.byRank(start + 1)
+	}
+
+	for i := start; i <= stop && x != nil; i++ {
+		out = append(out, ZMember{x.member, x.score})
+		if rev {
+			x = x.backward
+		} else {
+			x = x.level[0].forward
+		}
+	}
+	return out
+}
+
+// Members mengembalikan seluruh isi terurut naik.
+func (z *ZSet) Members() []ZMember {
+	if z.Len() == 0 {
+		return []ZMember{}
+	}
+	return z.Range(0, z.Len()-1, false)
+}
+
+func (z *ZSet) clone() *ZSet {
+	c := NewZSet()
+	for x := z.zsl.header.level[0].forward; x != nil; x = x.level[0].forward {
+		c.Add(x.member, x.score)
+	}
+	return c
+}
+
+// ParseScore membaca score; "inf", "+inf" dan "-inf" diterima, NaN tidak.
+func ParseScore(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, ErrNotFloat
+	}
+	return f, nil
+}
+
+// FormatScore memformat score seperti Redis: representasi terpendek yang
+// bisa dibaca ulang persis, "inf"/"-inf" untuk tak hingga.
+func FormatScore(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ZAddOptions memetakan flag ZADD NX/XX/GT/LT/INCR.
+type ZAddOptions struct {
+	NX, XX bool
+	GT, LT bool
+	Incr   bool
+}
+
+// ZAddResult: Added member baru, Updated member yang score-nya berubah.
+// Untuk INCR, Score berisi score akhir dan Applied false bila dibatalkan
+// oleh NX/XX/GT/LT.
+type ZAddResult struct {
+	Added   int
+	Updated int
+	Score   float64
+	Applied bool
+}
+
+// writableZSet mengembalikan item sorted set milik key, atau zset kosong
+// baru. Pemanggil wajib memegang write lock.
+func (m *MemoryStore) writableZSet(db int, key string) (Item, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return Item{Type: TypeZSet, ZSet: NewZSet()}, nil
+	}
+	if item.Type != TypeZSet {
+		return Item{}, ErrWrongType
+	}
+	return item, nil
+}
+
+func (m *MemoryStore) lookupZSet(db int, key string) (*ZSet, error) {
+	item, ok := m.lookup(db, key)
+	if !ok {
+		return nil, nil
+	}
+	if item.Type != TypeZSet {
+		return nil, ErrWrongType
+	}
+	return item.ZSet, nil
+}
+
+func (m *MemoryStore) ZAdd(db int, key string, opts ZAddOptions, members []ZMember) (ZAddResult, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	var res ZAddResult
+
+	item, err := m.writableZSet(db, key)
+	if err != nil {
+		return res, err
+	}
+	z := item.ZSet
+
+	for _, mem := range members {
+		cur, exists := z.Score(mem.Member)
+
+		if !exists {
+			if opts.XX {
+				continue
+			}
+			z.Add(mem.Member, mem.Score)
+			res.Added++
+			res.Score, res.Applied = mem.Score, true
+			continue
+		}
+
+		if opts.NX {
+			continue
+		}
+
+		score := mem.Score
+		if opts.Incr {
+			score += cur
+			if math.IsNaN(score) {
+				return res, ErrScoreNaN
+			}
+		}
+
+		if (opts.GT && score <= cur) || (opts.LT && score >= cur) {
+			continue
+		}
+
+		if score != cur {
+			z.Add(mem.Member, score)
+			res.Updated++
+		}
+		res.Score, res.Applied = score, true
+	}
+
+	if z.Len() > 0 {
+		m.put(db, key, item)
+	}
+	return res, nil
+}
+
+// ZRem menghapus member; key ikut dihapus bila zset menjadi kosong.
+func (m *MemoryStore) ZRem(db int, key string, members []string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, mem := range members {
+		if z.Remove(mem) {
+			removed++
+		}
+	}
+
+	if z.Len() == 0 {
+		m.remove(db, key)
+	}
+	return removed, nil
+}
+
+func (m *MemoryStore) ZScore(db int, key, member string) (float64, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, false, err
+	}
+
+	score, ok := z.Score(member)
+	return score, ok, nil
+}
+
+func (m *MemoryStore) ZCard(db int, key string) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+	return z.Len(), nil
+}
+
+// ZRank mengembalikan rank 0-based; rev true untuk ZREVRANK.
+func (m *MemoryStore) ZRank(db int, key, member string, rev bool) (int, bool, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, false, err
+	}
+
+	rank, ok := z.Rank(member)
+	if !ok {
+		return 0, false, nil
+	}
+	if rev {
+		rank = z.Len() - 1 - rank
+	}
+	return rank, true, nil
+}
+
+func (m *MemoryStore) ZRangeByRank(db int, key string, start, stop int, rev bool) ([]ZMember, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return []ZMember{}, err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, z.Len())
+	if !ok {
+		return []ZMember{}, nil
+	}
+	return z.Range(start, stop, rev), nil
+}
+
+// ZRangeByScore mengembalikan member dalam range score. offset/count
+// mengikuti LIMIT; count < 0 berarti tanpa batas.
+func (m *MemoryStore) ZRangeByScore(db int, key string, r ScoreRange, rev bool, offset, count int) ([]ZMember, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return []ZMember{}, err
+	}
+
+	var x *skipNode
+	if rev {
+		x = z.zsl.lastInScore(r)
+	} else {
+		x = z.zsl.firstInScore(r)
+	}
+
+	inRange := func(n *skipNode) bool {
+		if rev {
+			return r.aboveMin(n.score)
+		}
+		return r.belowMax(n.score)
+	}
+	return collectRange(x, rev, offset, count, inRange), nil
+}
+
+func (m *MemoryStore) ZRangeByLex(db int, key string, r LexRange, rev bool, offset, count int) ([]ZMember, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return []ZMember{}, err
+	}
+
+	var x *skipNode
+	if rev {
+		x = z.zsl.lastInLex(r)
+	} else {
+		x = z.zsl.firstInLex(r)
+	}
+
+	inRange := func(n *skipNode) bool {
+		if rev {
+			return r.aboveMin(n.member)
+		}
+		return r.belowMax(n.member)
+	}
+	return collectRange(x, rev, offset, count, inRange), nil
+}
+
+// collectRange berjalan dari x ke arah rev selama inRange, melewati offset
+// node pertama dan berhenti setelah count node.
+func collectRange(x *skipNode, rev bool, offset, count int, inRange func(*skipNode) bool) []ZMember {
+	out := []ZMember{}
+	if offset < 0 {
+		return out
+	}
+
+	next := func(n *skipNode) *skipNode {
+		if rev {
+			return n.backward
+		}
+		return n.level[0].forward
+	}
+
+	for ; x != nil && offset > 0; offset-- {
+		x = next(x)
+	}
+
+	for ; x != nil && count != 0 && inRange(x); x = next(x) {
+		out = append(out, ZMember{x.member, x.score})
+		count--
+	}
+	return out
+}
+
+// ZCount menghitung member dalam range score tanpa iterasi, memakai rank.
+func (m *MemoryStore) ZCount(db int, key string, r ScoreRange) (int, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+
+	first := z.zsl.firstInScore(r)
+	if first == nil {
+		return 0, nil
+	}
+	last := z.zsl.lastInScore(r)
+
+	return z.zsl.rank(last.score, last.member) - z.zsl.rank(first.score, first.member) + 1, nil
+}
+
+// ZPop menghapus sampai count member dengan score terendah (atau
+// tertinggi bila max true).
+func (m *MemoryStore) ZPop(db int, key string, max bool, count int) ([]ZMember, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return nil, err
+	}
+
+	if count > z.Len() {
+		count = z.Len()
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	out := z.Range(0, count-1, max)
+	for _, mem := range out {
+		z.Remove(mem.Member)
+	}
+
+	if z.Len() == 0 {
+		m.remove(db, key)
+	}
+	return out, nil
+}
+
+// ZAggregate menentukan cara menggabungkan score di ZUNIONSTORE/ZINTERSTORE.
+type ZAggregate int
+
+const (
+	ZAggSum ZAggregate = iota
+	ZAggMin
+	ZAggMax
+)
+
+func (a ZAggregate) apply(x, y float64) float64 {
+	switch a {
+	case ZAggMin:
+		return math.Min(x, y)
+	case ZAggMax:
+		return math.Max(x, y)
+	}
+	// inf + -inf dianggap 0 seperti Redis
+	if s := x + y; !math.IsNaN(s) {
+		return s
+	}
+	return 0
+}
+
+// ZSetStore menghitung union/intersection dari keys (zset atau set biasa
+// dengan score 1) lalu menyimpannya ke dst. Mengembalikan jumlah member.
+func (m *MemoryStore) ZSetStore(db int, dst string, keys []string, weights []float64, agg ZAggregate, inter bool) (int, error) {
+	unlock := m.lock(db, append([]string{dst}, keys...)...)
+	defer unlock()
+
+	inputs := make([]map[string]float64, len(keys))
+	for i, k := range keys {
+		item, ok := m.lookup(db, k)
+		if !ok {
+			continue
+		}
+
+		switch item.Type {
+		case TypeZSet:
+			inputs[i] = item.ZSet.dict
+		case TypeSet:
+			s := make(map[string]float64, len(item.Set))
+			for mem := range item.Set {
+				s[mem] = 1
+			}
+			inputs[i] = s
+		default:
+			return 0, ErrWrongType
+		}
+	}
+
+	weighted := func(i int, score float64) float64 {
+		if s := score * weights[i]; !math.IsNaN(s) {
+			return s
+		}
+		return 0
+	}
+
+	res := map[string]float64{}
+	if inter {
+		for mem, score := range inputs[0] {
+			acc := weighted(0, score)
+			inAll := true
+			for i := 1; i < len(inputs); i++ {
+				s, ok := inputs[i][mem]
+				if !ok {
+					inAll = false
+					break
+				}
+				acc = agg.apply(acc, weighted(i, s))
+			}
+			if inAll {
+				res[mem] = acc
+			}
+		}
+	} else {
+		for i, in := range inputs {
+			for mem, score := range in {
+				if acc, ok := res[mem]; ok {
+					res[mem] = agg.apply(acc, weighted(i, score))
+				} else {
+					res[mem] = weighted(i, score)
+				}
+			}
+		}
+	}
+
+	if len(res) == 0 {
+		m.remove(db, dst)
+		return 0, nil
+	}
+
+	z := NewZSet()
+	for mem, score := range res {
+		z.Add(mem, score)
+	}
+	m.put(db, dst, Item{Type: TypeZSet, ZSet: z})
+	return z.Len(), nil
+}
+
+// ZScan mengembalikan satu batch member beserta score mulai dari cursor.
+func (m *MemoryStore) ZScan(db int, key string, cursor uint64, count int) (uint64, []ZMember, error) {
+	unlock := m.lock(db, key)
+	defer unlock()
+
+	z, err := m.lookupZSet(db, key)
+	if err != nil || z == nil {
+		return 0, nil, err
+	}
+
+	if z.scan == nil {
+		z.scan = newScanIndex(z.dict)
+	}
+	next, batch := scanBatch(z.scan, cursor, count)
+
+	out := make([]ZMember, len(batch))
+	for i, mem := range batch {
+		out[i] = ZMember{mem, z.dict[mem]}
+	}
+	return next, out, nil
+}
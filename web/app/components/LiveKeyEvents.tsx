@@ -0,0 +1,35 @@
+"use client";
+
+import { useEffect } from "react";
+import { useRouter } from "next/navigation";
+
+type Props = {
+  dbId: number;
+};
+
+// Refresh halaman setiap ada keyspace event dari server (SSE).
+// Butuh notify_keyspace_events di config server, mis. "EA".
+export default function LiveKeyEvents({ dbId }: Props) {
+  const router = useRouter();
+
+  useEffect(() => {
+    const source = new EventSource(`/api/db/${dbId}/events`);
+    let timer: ReturnType<typeof setTimeout> | null = null;
+
+    source.onmessage = () => {
+      // gabungkan burst event menjadi satu refresh
+      if (timer) return;
+      timer = setTimeout(() => {
+        timer = null;
+        router.refresh();
+      }, 300);
+    };
+
+    return () => {
+      if (timer) clearTimeout(timer);
+      source.close();
+    };
+  }, [dbId, router]);
+
+  return null;
+}
@@ -64,9 +64,15 @@ export default async function KeyPage({ params }: Props) {
       {/* Value Card */}
       <div className="border border-zinc-800 rounded-lg overflow-hidden">
         <div className="px-4 py-2 text-sm text-zinc-400 border-b border-zinc-800">
-          Value
+          Value <span className="text-zinc-500">({data.type})</span>
         </div>
-        <EditKeyValue dbId={dbId} keyName={data.key} value={data.value} />
+        {data.type === "string" ? (
+          <EditKeyValue dbId={dbId} keyName={data.key} value={data.value} />
+        ) : (
+          <pre className="bg-zinc-950 p-4 text-sm overflow-auto">
+            {JSON.stringify(data.value, null, 2)}
+          </pre>
+        )}
       </div>
 
       {/* Meta */}
@@ -0,0 +1,138 @@
+import Breadcrumb from "@/app/components/Breadcrumb";
+
+type Props = {
+  params: Promise<{ id: string }>;
+  searchParams: Promise<{ top?: string }>;
+};
+
+type TypeStats = { keys: number; bytes: number };
+type KeySize = { key: string; type: string; bytes: number };
+
+function formatBytes(n: number) {
+  if (n < 1024) return `${n} B`;
+  if (n < 1024 * 1024) return `${(n / 1024).toFixed(1)} KB`;
+  if (n < 1024 * 1024 * 1024) return `${(n / 1024 / 1024).toFixed(1)} MB`;
+  return `${(n / 1024 / 1024 / 1024).toFixed(2)} GB`;
+}
+
+export default async function MemoryPage({ params, searchParams }: Props) {
+  const { id } = await params;
+  const { top = "20" } = await searchParams;
+  const dbId = Number(id);
+
+  if (Number.isNaN(dbId)) {
+    return (
+      <div className="h-full flex items-center justify-center">
+        <div className="text-center">
+          <h1 className="text-red-500 font-bold mb-2">Invalid Database ID</h1>
+          <p className="text-zinc-400 text-sm">Database ID must be a number</p>
+        </div>
+      </div>
+    );
+  }
+
+  const res = await fetch(
+    `http://localhost:8080/api/db/${dbId}/memory?top=${encodeURIComponent(top)}`,
+    { cache: "no-store" }
+  );
+
+  if (!res.ok) {
+    const text = await res.text();
+    return <pre className="text-red-400 bg-zinc-900 p-4 rounded">{text}</pre>;
+  }
+
+  const data = await res.json();
+  const types = Object.entries(data.types as Record<string, TypeStats>).sort(
+    (a, b) => b[1].bytes - a[1].bytes
+  );
+
+  return (
+    <section className="space-y-6">
+      <Breadcrumb
+        items={[
+          { label: "Home", href: "/" },
+          { label: `DB ${dbId}`, href: `/db/${dbId}` },
+          { label: "Memory" },
+        ]}
+      />
+
+      {/* Header */}
+      <div>
+        <h1 className="text-xl font-semibold">
+          Memory <span className="text-blue-500">DB {dbId}</span>
+        </h1>
+        <p className="text-sm text-zinc-400">
+          {data.keys} keys ({data.expires} with TTL), about{" "}
+          {formatBytes(data.bytes)} estimated
+        </p>
+      </div>
+
+      {/* Type distribution */}
+      <div className="border border-zinc-800 rounded-lg overflow-hidden">
+        <table className="w-full text-sm">
+          <thead className="bg-zinc-900 text-zinc-400">
+            <tr>
+              <th className="px-4 py-2 text-left font-normal">Type</th>
+              <th className="px-4 py-2 text-right font-normal">Keys</th>
+              <th className="px-4 py-2 text-right font-normal">Memory</th>
+              <th className="px-4 py-2 text-right font-normal">Share</th>
+            </tr>
+          </thead>
+          <tbody className="divide-y divide-zinc-800">
+            {types.length === 0 ? (
+              <tr>
+                <td colSpan={4} className="p-6 text-center text-zinc-500">
+                  No keys found in this database
+                </td>
+              </tr>
+            ) : (
+              types.map(([type, s]) => (
+                <tr key={type}>
+                  <td className="px-4 py-2 text-zinc-200">{type}</td>
+                  <td className="px-4 py-2 text-right">{s.keys}</td>
+                  <td className="px-4 py-2 text-right">{formatBytes(s.bytes)}</td>
+                  <td className="px-4 py-2 text-right text-zinc-400">
+                    {data.bytes > 0 ? ((s.bytes * 100) / data.bytes).toFixed(1) : "0.0"}%
+                  </td>
+                </tr>
+              ))
+            )}
+          </tbody>
+        </table>
+      </div>
+
+      {/* Largest keys */}
+      <div className="space-y-2">
+        <h2 className="text-sm text-zinc-400">Top {top} largest keys</h2>
+        <div className="border border-zinc-800 rounded-lg overflow-hidden">
+          {data.largest.length === 0 ? (
+            <div className="p-6 text-center text-zinc-500 text-sm">
+              No keys found in this database
+            </div>
+          ) : (
+            <ul className="divide-y divide-zinc-800">
+              {data.largest.map((k: KeySize) => (
+                <li key={k.key}>
+                  <a
+                    href={`/db/${dbId}/key/${encodeURIComponent(k.key)}`}
+                    className="
+                      flex justify-between px-4 py-3 text-sm
+                      text-zinc-200
+                      hover:bg-zinc-800
+                      transition
+                    "
+                  >
+                    <span>
+                      {k.key} <span className="text-zinc-500">({k.type})</span>
+                    </span>
+                    <span className="text-zinc-400">{formatBytes(k.bytes)}</span>
+                  </a>
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
+      </div>
+    </section>
+  );
+}
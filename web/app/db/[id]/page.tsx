@@ -1,12 +1,16 @@
 import Breadcrumb from "@/app/components/Breadcrumb";
 import CreateKeyModal from "@/app/components/CreateKeyModal";
+import LiveKeyEvents from "@/app/components/LiveKeyEvents";
 
 type Props = {
   params: Promise<{ id: string }>;
+  searchParams: Promise<{ cursor?: string; match?: string }>;
 };
 
-export default async function DBPage({ params }: Props) {
+export default async function DBPage({ params, searchParams }: Props) {
   const { id } = await params;
+  const { cursor = "0", match = "" } = await searchParams;
+  const matchQuery = match ? `&match=${encodeURIComponent(match)}` : "";
   const dbId = Number(id);
 
   if (Number.isNaN(dbId)) {
@@ -20,9 +24,10 @@ export default async function DBPage({ params }: Props) {
     );
   }
 
-  const res = await fetch(`http://localhost:8080/api/db/${dbId}/keys`, {
-    cache: "no-store",
-  });
+  const res = await fetch(
+    `http://localhost:8080/api/db/${dbId}/keys?cursor=${encodeURIComponent(cursor)}${matchQuery}`,
+    { cache: "no-store" }
+  );
 
   if (!res.ok) {
     const text = await res.text();
@@ -33,6 +38,7 @@ export default async function DBPage({ params }: Props) {
 
   return (
     <section className="space-y-6">
+      <LiveKeyEvents dbId={dbId} />
       <Breadcrumb
         items={[{ label: "Home", href: "/" }, { label: `DB ${dbId}` }]}
       />
@@ -45,9 +51,37 @@ export default async function DBPage({ params }: Props) {
           <p className="text-sm text-zinc-400">Keys stored in this database</p>
         </div>
 
-        <CreateKeyModal dbId={dbId} />
+        <div className="flex items-center gap-2">
+          <a
+            href={`/db/${dbId}/memory`}
+            className="px-4 py-2 rounded border border-zinc-800 hover:bg-zinc-800 text-sm"
+          >
+            Memory
+          </a>
+          <CreateKeyModal dbId={dbId} />
+        </div>
       </div>
 
+      {/* Search (glob: *, ?, [abc], [^a], [a-z]) */}
+      <form method="get" className="flex gap-2">
+        <input
+          name="match"
+          defaultValue={match}
+          placeholder="Search keys, e.g. user:*"
+          className="
+            flex-1 px-3 py-2 rounded
+            bg-zinc-900 border border-zinc-800
+            text-sm text-zinc-200
+          "
+        />
+        <button
+          type="submit"
+          className="px-4 py-2 rounded bg-blue-600 hover:bg-blue-500 text-sm"
+        >
+          Search
+        </button>
+      </form>
+
       {/* Keys */}
       <div className="border border-zinc-800 rounded-lg overflow-hidden">
         {data.keys.length === 0 ? (
@@ -74,6 +108,27 @@ export default async function DBPage({ params }: Props) {
           </ul>
         )}
       </div>
+
+      {/* Pagination (SCAN cursor) */}
+      {(cursor !== "0" || data.cursor !== "0") && (
+        <div className="flex justify-between text-sm">
+          {cursor !== "0" ? (
+            <a href={`/db/${dbId}?cursor=0${matchQuery}`} className="text-blue-500 hover:underline">
+              ← First page
+            </a>
+          ) : (
+            <span />
+          )}
+          {data.cursor !== "0" && (
+            <a
+              href={`/db/${dbId}?cursor=${data.cursor}${matchQuery}`}
+              className="text-blue-500 hover:underline"
+            >
+              Next page →
+            </a>
+          )}
+        </div>
+      )}
     </section>
   );
 }